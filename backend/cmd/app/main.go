@@ -1,13 +1,26 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 
 	"github.com/cativovo/bookstore/internal/book"
+	"github.com/cativovo/bookstore/internal/cart"
+	"github.com/cativovo/bookstore/internal/catalog"
+	"github.com/cativovo/bookstore/internal/change"
+	"github.com/cativovo/bookstore/internal/cover"
+	"github.com/cativovo/bookstore/internal/exchange"
+	"github.com/cativovo/bookstore/internal/inventory"
+	"github.com/cativovo/bookstore/internal/order"
+	"github.com/cativovo/bookstore/internal/payment"
+	"github.com/cativovo/bookstore/internal/review"
 	"github.com/cativovo/bookstore/internal/server"
 	"github.com/cativovo/bookstore/internal/storage/postgres"
+	"github.com/cativovo/bookstore/internal/user"
+	"github.com/cativovo/bookstore/internal/webhook"
 )
 
 func main() {
@@ -24,7 +37,77 @@ func main() {
 	}
 
 	bookService := book.NewBookService(repository)
+	inventoryService := inventory.NewInventoryService(repository)
+	cartService := cart.NewCartService(repository)
+	orderService := order.NewOrderService(repository)
 
-	s := server.NewServer(bookService)
-	log.Fatal(s.ListenAndServe("127.0.0.1:5000"))
+	const addr = "127.0.0.1:5000"
+
+	paymentWebhookSecret := os.Getenv("PAYMENT_WEBHOOK_SECRET")
+	if paymentWebhookSecret == "" {
+		log.Fatal("PAYMENT_WEBHOOK_SECRET is required to verify the payment webhooks")
+	}
+	// the fake provider calls back into this server
+	paymentProvider := payment.NewFakeProvider(paymentWebhookSecret, "http://"+addr+"/payments/webhook")
+	paymentService := payment.NewPaymentService(repository, paymentProvider, orderService)
+
+	exchangeService := exchange.NewExchangeService(repository)
+	changeService := change.NewChangeService(repository)
+
+	// load the exchange rates from a file when the admin endpoint isn't used to upload them
+	if path := os.Getenv("EXCHANGE_RATES_FILE"); path != "" {
+		rates, err := exchange.ReadRatesFile(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if err := exchangeService.UpdateRates(context.Background(), rates); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	maxPageSize := server.DefaultMaxPageSize
+	if v := os.Getenv("MAX_PAGE_SIZE"); v != "" {
+		maxPageSize, err = strconv.Atoi(v)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	webhookService := webhook.NewWebhookService(repository)
+
+	jwtSecret := os.Getenv("JWT_SECRET")
+	if jwtSecret == "" {
+		log.Fatal("JWT_SECRET is required to sign the access tokens")
+	}
+	userService := user.NewUserService(repository, jwtSecret)
+	reviewService := review.NewReviewService(repository)
+
+	coversDir := os.Getenv("COVERS_DIR")
+	if coversDir == "" {
+		coversDir = "covers"
+	}
+	coverStore, err := cover.NewLocalBlobStore(coversDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	coverService := cover.NewCoverService(coverStore, bookService)
+
+	// the rows of an import are validated like the payloads of the api
+	catalogService := catalog.NewCatalogService(repository, server.NewValidator())
+
+	maxAttempts := webhook.DefaultMaxAttempts
+	if v := os.Getenv("WEBHOOK_MAX_ATTEMPTS"); v != "" {
+		maxAttempts, err = strconv.Atoi(v)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// the dispatcher delivers the events of the outbox in the background for as long as the server runs
+	dispatcher := webhook.NewDispatcher(repository, maxAttempts, webhook.DefaultBackoff)
+	go dispatcher.Run(context.Background())
+
+	s := server.NewServer(bookService, inventoryService, cartService, orderService, paymentService, exchangeService, changeService, webhookService, userService, reviewService, coverService, catalogService, maxPageSize)
+	log.Fatal(s.ListenAndServe(addr))
 }
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cativovo/bookstore/internal/catalog"
+	"github.com/cativovo/bookstore/internal/server"
+	"github.com/cativovo/bookstore/internal/storage/postgres"
+)
+
+// imports the books of a csv or json file like POST /books/import and prints the report as json, e.g.
+// go run ./cmd/import -create-genres -chunk-size 100 testdata/books.json
+func main() {
+	format := flag.String("format", "", "csv or json, the extension of the file by default")
+	dryRun := flag.Bool("dry-run", false, "validate the rows without writing")
+	createGenres := flag.Bool("create-genres", false, "create the genres the catalog doesn't have instead of failing their rows")
+	chunkSize := flag.Int("chunk-size", 0, "the number of books created per transaction, 0 creates every book in a single transaction")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] file\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 || *chunkSize < 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	path := flag.Arg(0)
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	var rows []catalog.Row
+	switch *format {
+	case "csv":
+		rows, err = catalog.ReadCSV(f)
+	case "json":
+		rows, err = catalog.ReadJSON(f)
+	default:
+		log.Fatalf("unknown format '%s', it should be csv or json", *format)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	connStr := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s",
+		os.Getenv("DB_HOST"),
+		os.Getenv("DB_USER"),
+		os.Getenv("DB_PASSWORD"),
+		os.Getenv("DB_NAME"),
+		os.Getenv("DB_PORT"),
+	)
+	repository, err := postgres.NewPostgresRepository(connStr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	catalogService := catalog.NewCatalogService(repository, server.NewValidator())
+
+	report, err := catalogService.Import(context.Background(), rows, catalog.Options{
+		CreateGenres: *createGenres,
+		ChunkSize:    *chunkSize,
+		DryRun:       *dryRun,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("created %d, skipped %d, failed %d", report.Created, report.Skipped, report.Failed)
+}
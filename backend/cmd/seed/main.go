@@ -2,8 +2,10 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"math/rand/v2"
 	"os"
 	"slices"
@@ -12,6 +14,7 @@ import (
 	"github.com/brianvoe/gofakeit/v7"
 	"github.com/cativovo/bookstore/internal/book"
 	"github.com/cativovo/bookstore/internal/storage/postgres"
+	"github.com/cativovo/bookstore/internal/user"
 )
 
 const (
@@ -32,11 +35,22 @@ func main() {
 		log.Fatal(err)
 	}
 
+	ctx := context.Background()
+
+	// the first admin can't be created with the api, it gives the roles to the other users
+	if email := os.Getenv("ADMIN_EMAIL"); email != "" {
+		log.Println("seeding admin...")
+		userService := user.NewUserService(repository, "")
+		_, err := userService.CreateUser(ctx, email, os.Getenv("ADMIN_PASSWORD"), "Admin", user.RoleAdmin)
+		if err != nil && !errors.Is(err, user.ErrAlreadyExists) {
+			log.Fatal(err)
+		}
+	}
+
 	var wg sync.WaitGroup
 
 	log.Println("seeding genres...")
 	genres := getGenres()
-	ctx := context.Background()
 	for _, genre := range genres {
 		wg.Add(1)
 		go func() {
@@ -71,7 +85,7 @@ func main() {
 				Author:      gofakeit.BookAuthor(),
 				Description: gofakeit.Product().Description,
 				CoverImage:  "https://placehold.co/600x400",
-				Price:       gofakeit.Price(0.99, 69.99),
+				Price:       book.NewMoney(int64(math.Round(gofakeit.Price(0.99, 69.99)*100)), book.DefaultCurrency),
 				Genres:      bookGenres,
 			}
 			repository.CreateBook(ctx, b)
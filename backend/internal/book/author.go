@@ -0,0 +1,79 @@
+package book
+
+import (
+	"strings"
+	"time"
+)
+
+type AuthorRole string
+
+const (
+	AuthorRoleAuthor      AuthorRole = "author"
+	AuthorRoleTranslator  AuthorRole = "translator"
+	AuthorRoleIllustrator AuthorRole = "illustrator"
+	AuthorRoleEditor      AuthorRole = "editor"
+)
+
+// Author is a person credited on books, the same person is credited with the same author on every book.
+type Author struct {
+	CreatedAt time.Time `json:"created_at"`
+	Id        string    `json:"id"`
+	Name      string    `json:"name"`
+	Bio       string    `json:"bio"`
+	PhotoUrl  string    `json:"photo_url"`
+	BookCount int       `json:"book_count"`
+}
+
+// BookAuthor is the credit of an author on a book.
+// A new credit is matched with an existing author by name, case insensitive, the author is created if there is none.
+type BookAuthor struct {
+	Id   string     `json:"id"`
+	Name string     `json:"name"`
+	Role AuthorRole `json:"role"`
+}
+
+// Byline returns the names of the authors credited with AuthorRoleAuthor in order, e.g. "Terry Pratchett, Neil Gaiman".
+// It is what Book.Author holds.
+func Byline(authors []BookAuthor) string {
+	var names []string
+
+	for _, v := range authors {
+		if v.Role == AuthorRoleAuthor {
+			names = append(names, v.Name)
+		}
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// normalizeAuthors trims the names, credits the authors without a role as AuthorRoleAuthor and removes the duplicated credits.
+// It returns ErrInvalidAuthor if a name is empty or no one is credited with AuthorRoleAuthor.
+func normalizeAuthors(authors []BookAuthor) ([]BookAuthor, error) {
+	normalized := make([]BookAuthor, 0, len(authors))
+	seen := make(map[BookAuthor]bool)
+
+	for _, v := range authors {
+		v.Name = strings.TrimSpace(v.Name)
+		if v.Name == "" {
+			return nil, ErrInvalidAuthor
+		}
+
+		if v.Role == "" {
+			v.Role = AuthorRoleAuthor
+		}
+
+		key := BookAuthor{Name: strings.ToLower(v.Name), Role: v.Role}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		normalized = append(normalized, v)
+	}
+
+	if Byline(normalized) == "" {
+		return nil, ErrInvalidAuthor
+	}
+
+	return normalized, nil
+}
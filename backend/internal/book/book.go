@@ -1,11 +1,52 @@
 package book
 
+import "time"
+
 type Book struct {
-	Id          string   `json:"id"`
-	Title       string   `json:"title"`
-	Author      string   `json:"author"`
-	Description string   `json:"description"`
-	CoverImage  string   `json:"cover_image"`
-	Genres      []string `json:"genres"`
-	Price       float64  `json:"price"`
+	Id    string `json:"id"`
+	Title string `json:"title"`
+	// Author is the byline of the authors of the book, see Byline
+	Author         string       `json:"author"`
+	Authors        []BookAuthor `json:"authors"`
+	Description    string       `json:"description"`
+	CoverImage     CoverImage   `json:"cover_image"`
+	Genres         []string     `json:"genres"`
+	Price          Money        `json:"price"`
+	QuantityOnHand int          `json:"quantity_on_hand"`
+	Reserved       int          `json:"reserved"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+	// AverageRating is the average of the ratings of the reviews, it is null if the book has no review
+	AverageRating *float64 `json:"average_rating"`
+	ReviewCount   int      `json:"review_count"`
+	// Isbn13 is empty if the book has no ISBN, Isbn10 is also empty if the ISBN-13 has the 979 prefix
+	Isbn13          string `json:"isbn13"`
+	Isbn10          string `json:"isbn10"`
+	Publisher       string `json:"publisher"`
+	PublicationDate *Date  `json:"publication_date"`
+	Edition         string `json:"edition"`
+	PageCount       *int   `json:"page_count"`
+	// Language is a BCP 47 language tag, e.g. en or pt-BR
+	Language string `json:"language"`
+	Format   Format `json:"format"`
+	// Series is null if the book is not part of a series, SeriesPosition is then also null.
+	// The position is the reading order, with 2 decimals so a novella between the second and the third book can be 2.5.
+	Series         *BookSeries `json:"series"`
+	SeriesPosition *float64    `json:"series_position"`
+}
+
+// Format is the physical or digital form of a book, it is empty if it is unknown.
+type Format string
+
+const (
+	FormatHardcover Format = "hardcover"
+	FormatPaperback Format = "paperback"
+	FormatEbook     Format = "ebook"
+	FormatAudiobook Format = "audiobook"
+)
+
+type Genre struct {
+	Id        string `json:"id"`
+	Name      string `json:"name"`
+	BookCount int    `json:"book_count"`
 }
@@ -0,0 +1,127 @@
+package book
+
+import (
+	"bytes"
+	"encoding/json"
+	"path"
+	"strings"
+)
+
+// the sizes of a cover, the original is the image as uploaded and the others are thumbnails resized to a width
+const (
+	CoverSizeOriginal = "original"
+	CoverSizeLarge    = "large"
+	CoverSizeMedium   = "medium"
+	CoverSizeSmall    = "small"
+)
+
+var CoverSizes = []string{CoverSizeOriginal, CoverSizeLarge, CoverSizeMedium, CoverSizeSmall}
+
+// CoverUrlPrefix is the path the uploaded covers are served from
+const CoverUrlPrefix = "/covers/"
+
+// the thumbnails are encoded as jpeg whatever the format of the original
+const thumbnailExt = ".jpg"
+
+// CoverImage is the url of the cover of a book, either the url of an image on another site or the url of the original of an uploaded cover.
+// It is encoded in json as the url of each size, every size of an image on another site is the same url.
+type CoverImage string
+
+// UploadedCoverImage returns the cover image of an upload, the id identifies the upload and ext is the extension of the original, e.g. ".png".
+func UploadedCoverImage(id string, ext string) CoverImage {
+	return CoverImage(CoverUrlPrefix + CoverKey(id, CoverSizeOriginal, ext))
+}
+
+// CoverKey returns the name of the file of a size of an uploaded cover, e.g. "3f2a_small.jpg".
+func CoverKey(id string, size string, ext string) string {
+	if size != CoverSizeOriginal {
+		ext = thumbnailExt
+	}
+
+	return id + "_" + size + ext
+}
+
+// uploadId returns the id and the extension of the original of an uploaded cover, false if the cover is on another site
+func (c CoverImage) uploadId() (string, string, bool) {
+	if !strings.HasPrefix(string(c), CoverUrlPrefix) {
+		return "", "", false
+	}
+
+	key := strings.TrimPrefix(string(c), CoverUrlPrefix)
+	ext := path.Ext(key)
+
+	id, found := strings.CutSuffix(strings.TrimSuffix(key, ext), "_"+CoverSizeOriginal)
+	if !found || id == "" {
+		return "", "", false
+	}
+
+	return id, ext, true
+}
+
+// Url returns the url of the size of the cover.
+func (c CoverImage) Url(size string) string {
+	id, ext, ok := c.uploadId()
+	if !ok {
+		return string(c)
+	}
+
+	return CoverUrlPrefix + CoverKey(id, size, ext)
+}
+
+// Keys returns the names of the files of every size of an uploaded cover, nil if the cover is on another site.
+func (c CoverImage) Keys() []string {
+	id, ext, ok := c.uploadId()
+	if !ok {
+		return nil
+	}
+
+	keys := make([]string, len(CoverSizes))
+	for i, size := range CoverSizes {
+		keys[i] = CoverKey(id, size, ext)
+	}
+
+	return keys
+}
+
+// MarshalJSON encodes the cover as {"original":"...","large":"...","medium":"...","small":"..."}, a book without a cover is null.
+func (c CoverImage) MarshalJSON() ([]byte, error) {
+	if c == "" {
+		return []byte("null"), nil
+	}
+
+	urls := make(map[string]string, len(CoverSizes))
+	for _, size := range CoverSizes {
+		urls[size] = c.Url(size)
+	}
+
+	return json.Marshal(urls)
+}
+
+// UnmarshalJSON accepts the url of the cover or the urls of its sizes, the original is kept.
+func (c *CoverImage) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+
+	if bytes.Equal(data, []byte("null")) {
+		*c = ""
+		return nil
+	}
+
+	if len(data) > 0 && data[0] == '{' {
+		var urls map[string]string
+		if err := json.Unmarshal(data, &urls); err != nil {
+			return err
+		}
+
+		*c = CoverImage(urls[CoverSizeOriginal])
+		return nil
+	}
+
+	var url string
+	if err := json.Unmarshal(data, &url); err != nil {
+		return err
+	}
+
+	*c = CoverImage(url)
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package book
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoverImageUrl(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          CoverImage
+		size           string
+		expectedOutput string
+	}{
+		{
+			name:           "Original of an upload",
+			input:          UploadedCoverImage("3f2a", ".png"),
+			size:           CoverSizeOriginal,
+			expectedOutput: "/covers/3f2a_original.png",
+		},
+		{
+			name:           "Thumbnail of an upload",
+			input:          UploadedCoverImage("3f2a", ".png"),
+			size:           CoverSizeSmall,
+			expectedOutput: "/covers/3f2a_small.jpg",
+		},
+		{
+			name:           "Image on another site",
+			input:          "https://placehold.co/600x400.png",
+			size:           CoverSizeSmall,
+			expectedOutput: "https://placehold.co/600x400.png",
+		},
+		{
+			name:           "Thumbnail instead of the original",
+			input:          "/covers/3f2a_small.jpg",
+			size:           CoverSizeLarge,
+			expectedOutput: "/covers/3f2a_small.jpg",
+		},
+		{
+			name:           "No id",
+			input:          "/covers/_original.png",
+			size:           CoverSizeLarge,
+			expectedOutput: "/covers/_original.png",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expectedOutput, test.input.Url(test.size))
+		})
+	}
+}
+
+func TestCoverImageKeys(t *testing.T) {
+	assert.Equal(t,
+		[]string{"3f2a_original.gif", "3f2a_large.jpg", "3f2a_medium.jpg", "3f2a_small.jpg"},
+		UploadedCoverImage("3f2a", ".gif").Keys(),
+	)
+	assert.Nil(t, CoverImage("https://placehold.co/600x400.png").Keys())
+}
+
+func TestCoverImageJSON(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		expectedOutput CoverImage
+		expectedJson   string
+	}{
+		{
+			name:           "Url of an upload",
+			input:          `"/covers/3f2a_original.png"`,
+			expectedOutput: "/covers/3f2a_original.png",
+			expectedJson:   `{"large":"/covers/3f2a_large.jpg","medium":"/covers/3f2a_medium.jpg","original":"/covers/3f2a_original.png","small":"/covers/3f2a_small.jpg"}`,
+		},
+		{
+			name:           "Url on another site",
+			input:          `"https://placehold.co/600x400"`,
+			expectedOutput: "https://placehold.co/600x400",
+			expectedJson:   `{"large":"https://placehold.co/600x400","medium":"https://placehold.co/600x400","original":"https://placehold.co/600x400","small":"https://placehold.co/600x400"}`,
+		},
+		{
+			name:           "Object",
+			input:          `{"original":"/covers/3f2a_original.png","small":"/covers/3f2a_small.jpg"}`,
+			expectedOutput: "/covers/3f2a_original.png",
+			expectedJson:   `{"large":"/covers/3f2a_large.jpg","medium":"/covers/3f2a_medium.jpg","original":"/covers/3f2a_original.png","small":"/covers/3f2a_small.jpg"}`,
+		},
+		{
+			name:         "Object without the original",
+			input:        `{"small":"/covers/3f2a_small.jpg"}`,
+			expectedJson: `null`,
+		},
+		{
+			name:         "Null",
+			input:        `null`,
+			expectedJson: `null`,
+		},
+		{
+			name:         "Empty string",
+			input:        `""`,
+			expectedJson: `null`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := CoverImage("https://placehold.co/previous")
+			err := json.Unmarshal([]byte(test.input), &c)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedOutput, c)
+
+			b, err := json.Marshal(c)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedJson, string(b))
+
+			// the json of the cover is decoded back to the same cover
+			var roundTrip CoverImage
+			err = json.Unmarshal(b, &roundTrip)
+			assert.NoError(t, err)
+			assert.Equal(t, c, roundTrip)
+		})
+	}
+}
+
+func TestCoverImageInvalidJSON(t *testing.T) {
+	var c CoverImage
+
+	assert.Error(t, json.Unmarshal([]byte(`42`), &c))
+	assert.Error(t, json.Unmarshal([]byte(`{"original":42}`), &c))
+}
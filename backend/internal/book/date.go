@@ -0,0 +1,56 @@
+package book
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var ErrInvalidDate = errors.New("invalid date: should be YYYY-MM-DD")
+
+const dateLayout = "2006-01-02"
+
+// Date is a calendar date without a time of day, e.g. the publication date of a book.
+// It is encoded in json as "2006-01-02".
+type Date struct {
+	time.Time
+}
+
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{Time: time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+func (d Date) String() string {
+	return d.Format(dateLayout)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return ErrInvalidDate
+	}
+
+	// an empty string is the zero Date, it clears the date of a book
+	if s == "" {
+		*d = Date{}
+		return nil
+	}
+
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return ErrInvalidDate
+	}
+
+	d.Time = t
+
+	return nil
+}
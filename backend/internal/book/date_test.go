@@ -0,0 +1,56 @@
+package book
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDateUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		expectedOutput Date
+		expectedErr    error
+	}{
+		{
+			name:           "Date",
+			input:          `"1965-08-01"`,
+			expectedOutput: NewDate(1965, time.August, 1),
+		},
+		{
+			name:  "Empty string",
+			input: `""`,
+		},
+		{
+			name:  "Null",
+			input: `null`,
+		},
+		{
+			name:        "Time of day",
+			input:       `"1965-08-01T10:00:00Z"`,
+			expectedErr: ErrInvalidDate,
+		},
+		{
+			name:        "Number",
+			input:       `1965`,
+			expectedErr: ErrInvalidDate,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var d Date
+			err := json.Unmarshal([]byte(test.input), &d)
+			if test.expectedErr != nil {
+				assert.ErrorIs(t, err, test.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedOutput, d)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package book
+
+const (
+	// GenreMatchAny matches the books that have any of the genres of the filter, it is the default
+	GenreMatchAny = "any"
+	// GenreMatchAll matches the books that have every genre of the filter
+	GenreMatchAll = "all"
+)
+
+// PriceFacetBoundaries are the boundaries of the price buckets of the facets in DefaultCurrency,
+// they are converted like the prices of the books for the other currencies.
+var PriceFacetBoundaries = []Money{
+	NewMoney(1000, DefaultCurrency),
+	NewMoney(2500, DefaultCurrency),
+	NewMoney(5000, DefaultCurrency),
+	NewMoney(10000, DefaultCurrency),
+}
+
+// FacetCount is the number of books that have the value, e.g. Thriller (42).
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// PriceBucket is the number of books with a price from Min up to but not including Max, nil is unbounded.
+type PriceBucket struct {
+	Min   *Money `json:"min"`
+	Max   *Money `json:"max"`
+	Count int    `json:"count"`
+}
+
+// Facets are the counts of the books matching the filter by genre, author and price.
+// Every facet ignores its own filter so the other values can still be picked,
+// e.g. the genres are counted as if Genres wasn't set but with the price range.
+type Facets struct {
+	Genres  []FacetCount  `json:"genres"`
+	Authors []FacetCount  `json:"authors"`
+	Prices  []PriceBucket `json:"prices"`
+}
@@ -0,0 +1,89 @@
+package book
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrInvalidISBN = errors.New("invalid isbn")
+
+// ParseISBN returns the ISBN-13 of an ISBN-10 or an ISBN-13, the hyphens and the spaces are ignored.
+// It returns ErrInvalidISBN if the check digit doesn't match.
+func ParseISBN(s string) (string, error) {
+	s = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(s))
+
+	switch len(s) {
+	case 10:
+		if !isDigits(s[:9]) || !(isDigits(s[9:]) || s[9] == 'X') || isbn10CheckDigit(s[:9]) != s[9] {
+			return "", ErrInvalidISBN
+		}
+
+		// an ISBN-10 is an ISBN-13 with the 978 prefix, the check digit is computed again
+		isbn := "978" + s[:9]
+		return isbn + string(isbn13CheckDigit(isbn)), nil
+	case 13:
+		if !isDigits(s) || isbn13CheckDigit(s[:12]) != s[12] {
+			return "", ErrInvalidISBN
+		}
+
+		return s, nil
+	default:
+		return "", ErrInvalidISBN
+	}
+}
+
+// ValidISBN reports whether s is an ISBN-10 or an ISBN-13 with a valid check digit.
+func ValidISBN(s string) bool {
+	_, err := ParseISBN(s)
+	return err == nil
+}
+
+// ISBN10 returns the ISBN-10 of an ISBN-13, it returns false for the ISBN-13s with the 979 prefix, they have no ISBN-10.
+func ISBN10(isbn13 string) (string, bool) {
+	if len(isbn13) != 13 || !strings.HasPrefix(isbn13, "978") {
+		return "", false
+	}
+
+	isbn := isbn13[3:12]
+	return isbn + string(isbn10CheckDigit(isbn)), true
+}
+
+// isbn10CheckDigit returns the check digit of the first 9 digits of an ISBN-10, 10 is written X
+func isbn10CheckDigit(digits string) byte {
+	sum := 0
+	for i, d := range digits {
+		sum += (10 - i) * int(d-'0')
+	}
+
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return 'X'
+	}
+
+	return byte('0' + check)
+}
+
+// isbn13CheckDigit returns the check digit of the first 12 digits of an ISBN-13
+func isbn13CheckDigit(digits string) byte {
+	sum := 0
+	for i, d := range digits {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+
+		sum += weight * int(d-'0')
+	}
+
+	return byte('0' + (10-sum%10)%10)
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,153 @@
+package book
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseISBN(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		expectedOutput string
+		expectedErr    error
+	}{
+		{
+			name:           "ISBN-13",
+			input:          "9780306406157",
+			expectedOutput: "9780306406157",
+		},
+		{
+			name:           "ISBN-13 with hyphens",
+			input:          "978-0-306-40615-7",
+			expectedOutput: "9780306406157",
+		},
+		{
+			name:           "ISBN-13 with the 979 prefix",
+			input:          "979-10-90636-07-1",
+			expectedOutput: "9791090636071",
+		},
+		{
+			name:           "ISBN-10",
+			input:          "0306406152",
+			expectedOutput: "9780306406157",
+		},
+		{
+			name:           "ISBN-10 with spaces",
+			input:          "0 306 40615 2",
+			expectedOutput: "9780306406157",
+		},
+		{
+			name:           "ISBN-10 with the X check digit",
+			input:          "0-8044-2957-X",
+			expectedOutput: "9780804429573",
+		},
+		{
+			name:           "ISBN-10 with a lowercase x",
+			input:          "080442957x",
+			expectedOutput: "9780804429573",
+		},
+		{
+			name:        "ISBN-13 with a wrong check digit",
+			input:       "9780306406158",
+			expectedErr: ErrInvalidISBN,
+		},
+		{
+			name:        "ISBN-10 with a wrong check digit",
+			input:       "0306406153",
+			expectedErr: ErrInvalidISBN,
+		},
+		{
+			name:        "ISBN-10 with X instead of a digit",
+			input:       "030640615X",
+			expectedErr: ErrInvalidISBN,
+		},
+		{
+			name:        "X outside of the check digit",
+			input:       "08044295X7",
+			expectedErr: ErrInvalidISBN,
+		},
+		{
+			name:        "ISBN-13 with X",
+			input:       "978080442957X",
+			expectedErr: ErrInvalidISBN,
+		},
+		{
+			name:        "Letters",
+			input:       "97803064O6157",
+			expectedErr: ErrInvalidISBN,
+		},
+		{
+			name:        "Wrong length",
+			input:       "978030640615",
+			expectedErr: ErrInvalidISBN,
+		},
+		{
+			name:        "Empty",
+			input:       "",
+			expectedErr: ErrInvalidISBN,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			isbn, err := ParseISBN(test.input)
+			if test.expectedErr != nil {
+				assert.ErrorIs(t, err, test.expectedErr)
+				assert.False(t, ValidISBN(test.input))
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedOutput, isbn)
+			assert.True(t, ValidISBN(test.input))
+		})
+	}
+}
+
+func TestISBN10(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		expectedOutput string
+		expectedOk     bool
+	}{
+		{
+			name:           "978 prefix",
+			input:          "9780306406157",
+			expectedOutput: "0306406152",
+			expectedOk:     true,
+		},
+		{
+			name:           "X check digit",
+			input:          "9780804429573",
+			expectedOutput: "080442957X",
+			expectedOk:     true,
+		},
+		{
+			name:  "979 prefix",
+			input: "9791090636071",
+		},
+		{
+			name:  "Not an ISBN-13",
+			input: "0306406152",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			isbn, ok := ISBN10(test.input)
+
+			assert.Equal(t, test.expectedOk, ok)
+			assert.Equal(t, test.expectedOutput, isbn)
+
+			// the ISBN-10 is parsed back to the same ISBN-13
+			if ok {
+				isbn13, err := ParseISBN(isbn)
+				assert.NoError(t, err)
+				assert.Equal(t, test.input, isbn13)
+			}
+		})
+	}
+}
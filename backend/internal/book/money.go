@@ -0,0 +1,219 @@
+package book
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is the currency the prices of the books are stored in.
+const DefaultCurrency = "USD"
+
+var (
+	ErrInvalidMoney      = errors.New("invalid money")
+	ErrCurrencyMismatch  = errors.New("currency mismatch")
+	ErrInvalidCurrency   = errors.New("invalid currency")
+	errMoneyOutOfRange   = fmt.Errorf("%w: amount out of range", ErrInvalidMoney)
+	errMoneyInvalidValue = fmt.Errorf("%w: amount should be a decimal number", ErrInvalidMoney)
+)
+
+// number of digits after the decimal point of the currencies that don't use 2
+var minorUnits = map[string]int{
+	"BHD": 3,
+	"CLP": 0,
+	"IDR": 0,
+	"ISK": 0,
+	"JOD": 3,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"OMR": 3,
+	"TND": 3,
+	"VND": 0,
+}
+
+// MinorUnits returns the number of digits after the decimal point of the currency.
+func MinorUnits(currency string) int {
+	if n, ok := minorUnits[currency]; ok {
+		return n
+	}
+
+	return 2
+}
+
+// Money is an exact amount in the minor unit of an ISO 4217 currency, 10.10 USD is Money{Amount: 1010, Currency: "USD"}.
+// It is encoded in json as {"amount":"10.10","currency":"USD"}.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// ParseMoney parses a decimal string such as "10.10" into the minor unit of the currency.
+// It returns ErrInvalidMoney if the string has more digits after the decimal point than the currency has.
+func ParseMoney(s string, currency string) (Money, error) {
+	if !isCurrencyCode(currency) {
+		return Money{}, fmt.Errorf("%w: '%s'", ErrInvalidCurrency, currency)
+	}
+
+	units := MinorUnits(currency)
+
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	whole, fraction, _ := strings.Cut(s, ".")
+	if whole == "" || strings.ContainsAny(whole+fraction, "+-.") {
+		return Money{}, errMoneyInvalidValue
+	}
+
+	// trailing zeros don't change the amount, "10.100" is the same as "10.10"
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > units {
+		return Money{}, fmt.Errorf("%w: %s has %d digits after the decimal point", ErrInvalidMoney, currency, units)
+	}
+	fraction += strings.Repeat("0", units-len(fraction))
+
+	amount, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		var numErr *strconv.NumError
+		if errors.As(err, &numErr) && numErr.Err == strconv.ErrRange {
+			return Money{}, errMoneyOutOfRange
+		}
+		return Money{}, errMoneyInvalidValue
+	}
+
+	if negative {
+		amount = -amount
+	}
+
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// Add returns the sum of both amounts, it returns ErrCurrencyMismatch if the currencies are not the same.
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+
+	return Money{Amount: m.Amount + o.Amount, Currency: m.Currency}, nil
+}
+
+// Mul returns the amount multiplied by n, e.g. the price of a book times the quantity.
+func (m Money) Mul(n int) Money {
+	return Money{Amount: m.Amount * int64(n), Currency: m.Currency}
+}
+
+// String returns the amount as a decimal string without the currency, e.g. "10.10".
+func (m Money) String() string {
+	units := MinorUnits(m.Currency)
+
+	var sign string
+	abs := uint64(m.Amount)
+	if m.Amount < 0 {
+		sign = "-"
+		// also right for math.MinInt64 since its negation wraps to itself
+		abs = uint64(-m.Amount)
+	}
+
+	digits := strconv.FormatUint(abs, 10)
+
+	if units == 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= units {
+		digits = strings.Repeat("0", units-len(digits)+1) + digits
+	}
+
+	return sign + digits[:len(digits)-units] + "." + digits[len(digits)-units:]
+}
+
+type moneyJson struct {
+	Amount   json.RawMessage `json:"amount"`
+	Currency string          `json:"currency"`
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{
+		Amount:   m.String(),
+		Currency: m.Currency,
+	})
+}
+
+// UnmarshalJSON accepts an object with the amount and the currency, or only the amount as a string or a number.
+// The currency is DefaultCurrency if it is not set.
+// Numbers are parsed from their decimal text so 10.1 is exactly 1010 cents.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	currency := DefaultCurrency
+	amount := json.RawMessage(data)
+
+	if bytes.HasPrefix(data, []byte("{")) {
+		var v moneyJson
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+
+		if v.Currency != "" {
+			currency = v.Currency
+		}
+		amount = bytes.TrimSpace(v.Amount)
+	}
+
+	var s string
+	switch {
+	case bytes.HasPrefix(amount, []byte(`"`)):
+		if err := json.Unmarshal(amount, &s); err != nil {
+			return err
+		}
+	case len(amount) > 0 && (amount[0] == '-' || (amount[0] >= '0' && amount[0] <= '9')):
+		var n json.Number
+		if err := json.Unmarshal(amount, &n); err != nil {
+			return err
+		}
+		s = n.String()
+		// exponents are not worth supporting for prices
+		if strings.ContainsAny(s, "eE") {
+			return errMoneyInvalidValue
+		}
+	default:
+		return errMoneyInvalidValue
+	}
+
+	parsed, err := ParseMoney(s, currency)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+
+	return nil
+}
+
+func isCurrencyCode(currency string) bool {
+	if len(currency) != 3 {
+		return false
+	}
+
+	for _, c := range currency {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,335 @@
+package book
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		currency       string
+		expectedOutput Money
+		expectedErr    error
+	}{
+		{
+			name:           "Two digits",
+			input:          "10.10",
+			currency:       "USD",
+			expectedOutput: NewMoney(1010, "USD"),
+		},
+		{
+			name:           "One digit",
+			input:          "10.1",
+			currency:       "USD",
+			expectedOutput: NewMoney(1010, "USD"),
+		},
+		{
+			name:           "No digit",
+			input:          "10",
+			currency:       "USD",
+			expectedOutput: NewMoney(1000, "USD"),
+		},
+		{
+			name:           "Trailing point",
+			input:          "10.",
+			currency:       "USD",
+			expectedOutput: NewMoney(1000, "USD"),
+		},
+		{
+			name:           "Trailing zeros",
+			input:          "10.1000",
+			currency:       "USD",
+			expectedOutput: NewMoney(1010, "USD"),
+		},
+		{
+			name:        "More digits than the currency",
+			input:       "10.005",
+			currency:    "USD",
+			expectedErr: ErrInvalidMoney,
+		},
+		{
+			name:           "Three digit currency",
+			input:          "10.005",
+			currency:       "BHD",
+			expectedOutput: NewMoney(10005, "BHD"),
+		},
+		{
+			name:           "Zero digit currency",
+			input:          "1500.0",
+			currency:       "JPY",
+			expectedOutput: NewMoney(1500, "JPY"),
+		},
+		{
+			name:        "Digits in a zero digit currency",
+			input:       "1500.5",
+			currency:    "JPY",
+			expectedErr: ErrInvalidMoney,
+		},
+		{
+			name:           "Negative",
+			input:          "-5.25",
+			currency:       "USD",
+			expectedOutput: NewMoney(-525, "USD"),
+		},
+		{
+			name:           "Negative below one",
+			input:          "-0.5",
+			currency:       "USD",
+			expectedOutput: NewMoney(-50, "USD"),
+		},
+		{
+			name:        "Double sign",
+			input:       "--5",
+			currency:    "USD",
+			expectedErr: ErrInvalidMoney,
+		},
+		{
+			name:        "Plus sign",
+			input:       "+5",
+			currency:    "USD",
+			expectedErr: ErrInvalidMoney,
+		},
+		{
+			name:        "Exponent",
+			input:       "1e3",
+			currency:    "USD",
+			expectedErr: ErrInvalidMoney,
+		},
+		{
+			name:        "Exponent in the fraction",
+			input:       "1.5e2",
+			currency:    "USD",
+			expectedErr: ErrInvalidMoney,
+		},
+		{
+			name:        "No whole part",
+			input:       ".5",
+			currency:    "USD",
+			expectedErr: ErrInvalidMoney,
+		},
+		{
+			name:        "Thousands separator",
+			input:       "1,000.00",
+			currency:    "USD",
+			expectedErr: ErrInvalidMoney,
+		},
+		{
+			name:        "Empty",
+			input:       "",
+			currency:    "USD",
+			expectedErr: ErrInvalidMoney,
+		},
+		{
+			name:           "Largest amount",
+			input:          "92233720368547758.07",
+			currency:       "USD",
+			expectedOutput: NewMoney(math.MaxInt64, "USD"),
+		},
+		{
+			name:        "Out of range",
+			input:       "92233720368547758.08",
+			currency:    "USD",
+			expectedErr: ErrInvalidMoney,
+		},
+		{
+			name:        "Lowercase currency",
+			input:       "10.10",
+			currency:    "usd",
+			expectedErr: ErrInvalidCurrency,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m, err := ParseMoney(test.input, test.currency)
+			if test.expectedErr != nil {
+				assert.ErrorIs(t, err, test.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedOutput, m)
+		})
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          Money
+		expectedOutput string
+	}{
+		{
+			name:           "Two digits",
+			input:          NewMoney(1010, "USD"),
+			expectedOutput: "10.10",
+		},
+		{
+			name:           "Below one",
+			input:          NewMoney(5, "USD"),
+			expectedOutput: "0.05",
+		},
+		{
+			name:           "Zero",
+			input:          NewMoney(0, "USD"),
+			expectedOutput: "0.00",
+		},
+		{
+			name:           "Negative below one",
+			input:          NewMoney(-5, "USD"),
+			expectedOutput: "-0.05",
+		},
+		{
+			name:           "Three digit currency",
+			input:          NewMoney(10005, "BHD"),
+			expectedOutput: "10.005",
+		},
+		{
+			name:           "Zero digit currency",
+			input:          NewMoney(1500, "JPY"),
+			expectedOutput: "1500",
+		},
+		{
+			name:           "Smallest amount",
+			input:          NewMoney(math.MinInt64, "USD"),
+			expectedOutput: "-92233720368547758.08",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expectedOutput, test.input.String())
+
+			// the string is parsed back to the same amount
+			if test.input.Amount != math.MinInt64 {
+				m, err := ParseMoney(test.expectedOutput, test.input.Currency)
+				assert.NoError(t, err)
+				assert.Equal(t, test.input, m)
+			}
+		})
+	}
+}
+
+func TestMoneyAdd(t *testing.T) {
+	sum, err := NewMoney(1010, "USD").Add(NewMoney(95, "USD"))
+	assert.NoError(t, err)
+	assert.Equal(t, NewMoney(1105, "USD"), sum)
+
+	_, err = NewMoney(1010, "USD").Add(NewMoney(95, "EUR"))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+}
+
+func TestMoneyMarshalJSON(t *testing.T) {
+	b, err := json.Marshal(NewMoney(1010, "EUR"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, `{"amount":"10.10","currency":"EUR"}`, string(b))
+}
+
+func TestMoneyUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		expectedOutput Money
+		expectedErr    error
+	}{
+		{
+			name:           "Object",
+			input:          `{"amount":"10.10","currency":"EUR"}`,
+			expectedOutput: NewMoney(1010, "EUR"),
+		},
+		{
+			name:           "Object with a number",
+			input:          `{"amount": 10.1, "currency": "EUR"}`,
+			expectedOutput: NewMoney(1010, "EUR"),
+		},
+		{
+			name:           "Object without the currency",
+			input:          `{"amount":"10.10"}`,
+			expectedOutput: NewMoney(1010, DefaultCurrency),
+		},
+		{
+			name:           "Object with three digits",
+			input:          `{"amount":"1.005","currency":"JOD"}`,
+			expectedOutput: NewMoney(1005, "JOD"),
+		},
+		{
+			name:        "Object with more digits than the currency",
+			input:       `{"amount":"1.005","currency":"USD"}`,
+			expectedErr: ErrInvalidMoney,
+		},
+		{
+			name:        "Object with an invalid currency",
+			input:       `{"amount":"1.00","currency":"usd"}`,
+			expectedErr: ErrInvalidCurrency,
+		},
+		{
+			name:        "Object without the amount",
+			input:       `{"currency":"USD"}`,
+			expectedErr: ErrInvalidMoney,
+		},
+		{
+			name:           "String",
+			input:          `"10.10"`,
+			expectedOutput: NewMoney(1010, DefaultCurrency),
+		},
+		{
+			name:        "Empty string",
+			input:       `""`,
+			expectedErr: ErrInvalidMoney,
+		},
+		{
+			name:           "Number",
+			input:          `10.1`,
+			expectedOutput: NewMoney(1010, DefaultCurrency),
+		},
+		{
+			name:           "Number that isn't exact as a float",
+			input:          `0.29`,
+			expectedOutput: NewMoney(29, DefaultCurrency),
+		},
+		{
+			name:           "Negative number",
+			input:          `-3`,
+			expectedOutput: NewMoney(-300, DefaultCurrency),
+		},
+		{
+			name:        "Number with more digits than the currency",
+			input:       `10.005`,
+			expectedErr: ErrInvalidMoney,
+		},
+		{
+			name:        "Number with an exponent",
+			input:       `1e3`,
+			expectedErr: ErrInvalidMoney,
+		},
+		{
+			name:        "Boolean",
+			input:       `true`,
+			expectedErr: ErrInvalidMoney,
+		},
+		{
+			name:  "Null",
+			input: `null`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var m Money
+			err := json.Unmarshal([]byte(test.input), &m)
+			if test.expectedErr != nil {
+				assert.ErrorIs(t, err, test.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedOutput, m)
+		})
+	}
+}
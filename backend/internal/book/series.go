@@ -0,0 +1,32 @@
+package book
+
+import (
+	"math"
+	"time"
+)
+
+// Series is an ordered set of books, e.g. the novels of a saga, GetBooks sorted by SortBySeriesPosition lists them in reading order.
+type Series struct {
+	CreatedAt   time.Time `json:"created_at"`
+	Id          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	BookCount   int       `json:"book_count"`
+}
+
+// BookSeries is the series a book is part of.
+type BookSeries struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// ValidSeriesPosition reports whether the position is stored as is, the position has at most 2 decimals and is between 0.01 and 9999.99.
+func ValidSeriesPosition(position float64) bool {
+	if position < 0.01 || position > 9999.99 {
+		return false
+	}
+
+	// the float of a decimal like 1.23 is not exact, it is only close to a number of hundredths
+	hundredths := position * 100
+	return math.Abs(hundredths-math.Round(hundredths)) < 1e-6
+}
@@ -0,0 +1,67 @@
+package book
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidSeriesPosition(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          float64
+		expectedOutput bool
+	}{
+		{
+			name:           "Whole",
+			input:          3,
+			expectedOutput: true,
+		},
+		{
+			name:           "Fractional",
+			input:          2.5,
+			expectedOutput: true,
+		},
+		{
+			name:           "Two decimals that aren't exact as a float",
+			input:          1.23,
+			expectedOutput: true,
+		},
+		{
+			name:           "Smallest",
+			input:          0.01,
+			expectedOutput: true,
+		},
+		{
+			name:           "Largest",
+			input:          9999.99,
+			expectedOutput: true,
+		},
+		{
+			name:  "Zero",
+			input: 0,
+		},
+		{
+			name:  "Negative",
+			input: -1,
+		},
+		{
+			name:  "Rounded to zero",
+			input: 0.004,
+		},
+		{
+			name:  "Three decimals",
+			input: 1.234,
+		},
+		{
+			name:  "Too large",
+			input: 10000,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expectedOutput, ValidSeriesPosition(test.input))
+		})
+	}
+}
@@ -3,34 +3,140 @@ package book
 import (
 	"context"
 	"errors"
+	"strings"
+	"time"
 )
 
 var (
 	ErrNotFound      = errors.New("not found")
 	ErrAlreadyExists = errors.New("already exists")
+	ErrInvalidGenre  = errors.New("invalid genre")
+	// ErrMergeIntoItself is returned when the source and the target of a merge are the same genre
+	ErrMergeIntoItself = errors.New("cannot merge a genre into itself")
+	ErrPriceNotFound   = errors.New("price not found")
+	ErrInvalidCursor   = errors.New("invalid cursor")
+	ErrInvalidSort     = errors.New("invalid sort")
+	// ErrInvalidAuthor is returned when the credits of a book have no author
+	ErrInvalidAuthor  = errors.New("invalid author")
+	ErrAuthorNotFound = errors.New("author not found")
+	ErrSeriesNotFound = errors.New("series not found")
+	// ErrInvalidSeriesPosition is returned when a book is put in a series without a position or with one ValidSeriesPosition rejects
+	ErrInvalidSeriesPosition = errors.New("invalid series position")
 )
 
+const OrderByRelevance = "relevance"
+
 type GetBooksFilter struct {
-	Author string
-	Title  string
-	Genres []string
+	// Query is a full-text search over the title, author and description, e.g. "camus stranger"
+	Query string
+	// nil matches every book, true matches books with available stock, false matches books without
+	InStock *bool
+	// Author matches a part of the name of an author credited with AuthorRoleAuthor,
+	// or the whole name if ExactAuthor is set, case insensitive
+	Author      string
+	ExactAuthor bool
+	// AuthorId matches the books crediting the author in any role
+	AuthorId string
+	Title    string
+	Genres   []string
+	// GenreMatch is GenreMatchAny or GenreMatchAll, empty is GenreMatchAny
+	GenreMatch string
+	// ExcludeGenres leaves out the books that have any of the genres
+	ExcludeGenres []string
+	// MinPrice and MaxPrice are inclusive and in the currency of the options, nil is unbounded
+	MinPrice *Money
+	MaxPrice *Money
+	// CreatedSince and UpdatedSince match the books created or updated at or after the time, nil matches every book
+	CreatedSince *time.Time
+	UpdatedSince *time.Time
+	// MinRating matches the books with an average rating at or above it, the books without a review don't match
+	MinRating *float64
+	// SeriesId matches the books of the series
+	SeriesId string
 }
 
 type GetBooksOptions struct {
+	// the prices are converted to Currency and sorted by the converted price, empty is DefaultCurrency
+	Currency string
+	// OrderBy is title, author, price, rating or relevance, relevance needs Filter.Query and always puts the best match first.
+	// It is ignored if Sort is set.
 	OrderBy string
-	Filter  GetBooksFilter
-	Limit   int
-	Offset  int
-	Desc    bool
+	// Sort orders the books by each key in turn, e.g. price then title.
+	Sort   []SortKey
+	Filter GetBooksFilter
+	// Cursor is the NextCursor of a previous page, the books after the last book of that page are returned and Offset is ignored.
+	// The cursor only works with the same OrderBy, Sort, Desc, Currency and Filter it was made with.
+	Cursor string
+	Limit  int
+	Offset int
+	Desc   bool
+	// SkipCount doesn't count the books matching the filter, counting is the slowest part of a page on a large catalog
+	SkipCount bool
+	// Facets counts the books matching the filter by genre, author and price
+	Facets bool
+}
+
+// BooksPage is a page of the books returned by GetBooks.
+type BooksPage struct {
+	Books []Book
+	// Count is the number of books matching the filter, it is -1 if the books were not counted
+	Count int
+	// NextCursor is the cursor of the page after this one, it is empty on the last page
+	NextCursor string
+	// Facets is nil if the options didn't ask for them
+	Facets *Facets
+}
+
+// UpdateBookOptions holds the fields to change on a book, nil fields are left untouched.
+// A non-nil Genres replaces every genre of the book and a non-nil Authors replaces every credit of the book.
+type UpdateBookOptions struct {
+	Title *string
+	// Author replaces the authors credited with AuthorRoleAuthor by one author, the other credits are kept
+	Author      *string
+	Authors     *[]BookAuthor
+	Description *string
+	CoverImage  *string
+	Price       *Money
+	Genres      *[]string
+	// an empty Isbn, Publisher, Edition, Language or Format, a zero PublicationDate or a zero PageCount clears it
+	Isbn            *string
+	Publisher       *string
+	PublicationDate *Date
+	Edition         *string
+	PageCount       *int
+	Language        *string
+	Format          *Format
+	// SeriesId puts the book in the series at SeriesPosition, an empty SeriesId removes the book from its series
+	SeriesId       *string
+	SeriesPosition *float64
 }
 
 type BookRepository interface {
-	GetBooks(ctx context.Context, options GetBooksOptions) (books []Book, count int, err error)
-	GetBookById(ctx context.Context, id string) (Book, error)
-	GetGenres(ctx context.Context) ([]string, error)
+	// GetBooks returns ErrInvalidCursor if the cursor of the options is malformed or was made with other options.
+	GetBooks(ctx context.Context, options GetBooksOptions) (BooksPage, error)
+	// GetBookById returns the book with the price in the currency, it returns ErrInvalidCurrency if the currency has no exchange rate.
+	GetBookById(ctx context.Context, id string, currency string) (Book, error)
+	// GetBookByIsbn is GetBookById for the book with the ISBN-13.
+	GetBookByIsbn(ctx context.Context, isbn13 string, currency string) (Book, error)
+	GetGenres(ctx context.Context) ([]Genre, error)
+	// GetAuthors returns the authors with a name matching a part of name, by name.
+	GetAuthors(ctx context.Context, name string, limit int, offset int) (authors []Author, count int, err error)
+	GetAuthorById(ctx context.Context, id string) (Author, error)
+	// CreateSeries returns ErrAlreadyExists if a series has the name, case insensitive.
+	CreateSeries(ctx context.Context, s Series) (Series, error)
+	GetSeriesById(ctx context.Context, id string) (Series, error)
 	CreateGenre(ctx context.Context, name string) error
-	DeleteGenre(ctx context.Context, name string) error
+	UpdateGenre(ctx context.Context, id string, name string) (Genre, error)
+	MergeGenres(ctx context.Context, sourceId string, targetId string) (Genre, error)
+	DeleteGenre(ctx context.Context, id string) error
+	// CreateBook credits Book.Author as the author of the book if Book.Authors is empty.
+	// CreateBook and UpdateBook return ErrAlreadyExists if another book has the ISBN and ErrSeriesNotFound if the series doesn't exist.
 	CreateBook(ctx context.Context, b Book) (Book, error)
+	UpdateBook(ctx context.Context, id string, options UpdateBookOptions) (Book, error)
+	DeleteBook(ctx context.Context, id string) error
+	// SetBookPrice sets the price of the book in the currency of the price, it is used instead of the converted price.
+	SetBookPrice(ctx context.Context, id string, price Money) error
+	DeleteBookPrice(ctx context.Context, id string, currency string) error
 }
 
 type BookService struct {
@@ -47,22 +153,152 @@ func (bs *BookService) CreateGenre(ctx context.Context, name string) error {
 	return bs.repository.CreateGenre(ctx, name)
 }
 
-func (bs *BookService) DeleteGenre(ctx context.Context, name string) error {
-	return bs.repository.DeleteGenre(ctx, name)
+func (bs *BookService) UpdateGenre(ctx context.Context, id string, name string) (Genre, error) {
+	return bs.repository.UpdateGenre(ctx, id, name)
+}
+
+// MergeGenres moves every book of the source genre into the target genre then deletes the source genre.
+func (bs *BookService) MergeGenres(ctx context.Context, sourceId string, targetId string) (Genre, error) {
+	return bs.repository.MergeGenres(ctx, sourceId, targetId)
+}
+
+func (bs *BookService) DeleteGenre(ctx context.Context, id string) error {
+	return bs.repository.DeleteGenre(ctx, id)
 }
 
 func (bs *BookService) CreateBook(ctx context.Context, b Book) (Book, error) {
+	if b.Price.Currency != DefaultCurrency {
+		return Book{}, ErrInvalidCurrency
+	}
+
+	if len(b.Authors) > 0 {
+		authors, err := normalizeAuthors(b.Authors)
+		if err != nil {
+			return Book{}, err
+		}
+
+		b.Authors = authors
+	}
+
+	if b.Series != nil && !validSeriesPosition(b.SeriesPosition) {
+		return Book{}, ErrInvalidSeriesPosition
+	}
+
+	// the ISBN is stored as an ISBN-13 whichever form it is given in
+	if b.Isbn13 != "" {
+		isbn, err := ParseISBN(b.Isbn13)
+		if err != nil {
+			return Book{}, err
+		}
+
+		b.Isbn13 = isbn
+	}
+
 	return bs.repository.CreateBook(ctx, b)
 }
 
-func (bs *BookService) GetBooks(ctx context.Context, options GetBooksOptions) (books []Book, count int, err error) {
+func (bs *BookService) UpdateBook(ctx context.Context, id string, options UpdateBookOptions) (Book, error) {
+	if options.Price != nil && options.Price.Currency != DefaultCurrency {
+		return Book{}, ErrInvalidCurrency
+	}
+
+	if options.Authors != nil {
+		authors, err := normalizeAuthors(*options.Authors)
+		if err != nil {
+			return Book{}, err
+		}
+
+		options.Authors = &authors
+	}
+
+	if options.SeriesId != nil && *options.SeriesId != "" && !validSeriesPosition(options.SeriesPosition) {
+		return Book{}, ErrInvalidSeriesPosition
+	}
+
+	if options.Isbn != nil && *options.Isbn != "" {
+		isbn, err := ParseISBN(*options.Isbn)
+		if err != nil {
+			return Book{}, err
+		}
+
+		options.Isbn = &isbn
+	}
+
+	return bs.repository.UpdateBook(ctx, id, options)
+}
+
+func (bs *BookService) DeleteBook(ctx context.Context, id string) error {
+	return bs.repository.DeleteBook(ctx, id)
+}
+
+// SetBookPrice overrides the converted price of the book, the price can't be in DefaultCurrency.
+func (bs *BookService) SetBookPrice(ctx context.Context, id string, price Money) error {
+	if price.Currency == DefaultCurrency {
+		return ErrInvalidCurrency
+	}
+
+	return bs.repository.SetBookPrice(ctx, id, price)
+}
+
+func (bs *BookService) DeleteBookPrice(ctx context.Context, id string, currency string) error {
+	return bs.repository.DeleteBookPrice(ctx, id, currency)
+}
+
+func (bs *BookService) GetBooks(ctx context.Context, options GetBooksOptions) (BooksPage, error) {
+	if options.Currency == "" {
+		options.Currency = DefaultCurrency
+	}
+
+	if options.Filter.Query != "" && options.OrderBy == "" && len(options.Sort) == 0 {
+		options.OrderBy = OrderByRelevance
+	}
+
 	return bs.repository.GetBooks(ctx, options)
 }
 
-func (bs *BookService) GetBookById(ctx context.Context, id string) (Book, error) {
-	return bs.repository.GetBookById(ctx, id)
+func (bs *BookService) GetBookById(ctx context.Context, id string, currency string) (Book, error) {
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+
+	return bs.repository.GetBookById(ctx, id, currency)
+}
+
+// GetBookByIsbn returns the book with the ISBN-10 or the ISBN-13, it returns ErrInvalidISBN if the ISBN is malformed.
+func (bs *BookService) GetBookByIsbn(ctx context.Context, isbn string, currency string) (Book, error) {
+	isbn13, err := ParseISBN(isbn)
+	if err != nil {
+		return Book{}, err
+	}
+
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+
+	return bs.repository.GetBookByIsbn(ctx, isbn13, currency)
 }
 
-func (bs *BookService) GetGenres(ctx context.Context) ([]string, error) {
+func (bs *BookService) GetGenres(ctx context.Context) ([]Genre, error) {
 	return bs.repository.GetGenres(ctx)
 }
+
+func (bs *BookService) GetAuthors(ctx context.Context, name string, limit int, offset int) ([]Author, int, error) {
+	return bs.repository.GetAuthors(ctx, name, limit, offset)
+}
+
+func (bs *BookService) GetAuthorById(ctx context.Context, id string) (Author, error) {
+	return bs.repository.GetAuthorById(ctx, id)
+}
+
+func (bs *BookService) CreateSeries(ctx context.Context, s Series) (Series, error) {
+	s.Name = strings.TrimSpace(s.Name)
+	return bs.repository.CreateSeries(ctx, s)
+}
+
+func (bs *BookService) GetSeriesById(ctx context.Context, id string) (Series, error) {
+	return bs.repository.GetSeriesById(ctx, id)
+}
+
+func validSeriesPosition(position *float64) bool {
+	return position != nil && ValidSeriesPosition(*position)
+}
@@ -0,0 +1,53 @@
+package book
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// the fields the books can be sorted by, OrderByRelevance can also be used when there is a search query
+const (
+	SortByTitle     = "title"
+	SortByAuthor    = "author"
+	SortByPrice     = "price"
+	SortByCreatedAt = "created_at"
+	SortByUpdatedAt = "updated_at"
+	// SortByRating sorts by the average rating, the books without a review are sorted as if they had a rating of 0
+	SortByRating = "rating"
+	// SortBySeriesPosition sorts by the position in the series, the books outside a series are sorted as if they had a position of 0
+	SortBySeriesPosition = "series_position"
+)
+
+var sortFields = []string{SortByTitle, SortByAuthor, SortByPrice, SortByCreatedAt, SortByUpdatedAt, SortByRating, SortBySeriesPosition, OrderByRelevance}
+
+// SortKey is a field the books are sorted by.
+// Relevance is sorted from the best match when Desc is false since that is the only useful order of it.
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+// ParseSort parses comma separated fields, a field starting with '-' is sorted in descending order, e.g. "price,-title".
+// It returns ErrInvalidSort if a field can't be sorted by or is used twice.
+func ParseSort(s string) ([]SortKey, error) {
+	var keys []SortKey
+
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		desc := strings.HasPrefix(field, "-")
+		field = strings.TrimPrefix(field, "-")
+
+		if !slices.Contains(sortFields, field) {
+			return nil, fmt.Errorf("%w: unknown field '%s'", ErrInvalidSort, field)
+		}
+
+		if slices.ContainsFunc(keys, func(k SortKey) bool { return k.Field == field }) {
+			return nil, fmt.Errorf("%w: '%s' is used more than once", ErrInvalidSort, field)
+		}
+
+		keys = append(keys, SortKey{Field: field, Desc: desc})
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,36 @@
+package cart
+
+import "github.com/cativovo/bookstore/internal/book"
+
+type Item struct {
+	BookId   string     `json:"book_id"`
+	Title    string     `json:"title"`
+	Author   string     `json:"author"`
+	Price    book.Money `json:"price"`
+	Quantity int        `json:"quantity"`
+	Subtotal book.Money `json:"subtotal"`
+}
+
+type Cart struct {
+	Id    string     `json:"id"`
+	Items []Item     `json:"items"`
+	Total book.Money `json:"total"`
+}
+
+// calculateTotal sets the subtotal of every item and the total of the cart from the current price of the books.
+func (c *Cart) calculateTotal() error {
+	c.Total = book.NewMoney(0, book.DefaultCurrency)
+
+	for i := range c.Items {
+		c.Items[i].Subtotal = c.Items[i].Price.Mul(c.Items[i].Quantity)
+
+		total, err := c.Total.Add(c.Items[i].Subtotal)
+		if err != nil {
+			return err
+		}
+
+		c.Total = total
+	}
+
+	return nil
+}
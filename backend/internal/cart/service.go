@@ -0,0 +1,72 @@
+package cart
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrNotFound     = errors.New("cart not found")
+	ErrItemNotFound = errors.New("item not found")
+)
+
+// CartRepository returns book.ErrNotFound when a book id does not match any book.
+type CartRepository interface {
+	CreateCart(ctx context.Context) (Cart, error)
+	GetCartById(ctx context.Context, id string) (Cart, error)
+	AddCartItem(ctx context.Context, cartId string, bookId string, quantity int) error
+	UpdateCartItem(ctx context.Context, cartId string, bookId string, quantity int) error
+	RemoveCartItem(ctx context.Context, cartId string, bookId string) error
+}
+
+type CartService struct {
+	repository CartRepository
+}
+
+func NewCartService(r CartRepository) *CartService {
+	return &CartService{
+		repository: r,
+	}
+}
+
+func (cs *CartService) CreateCart(ctx context.Context) (Cart, error) {
+	return cs.repository.CreateCart(ctx)
+}
+
+func (cs *CartService) GetCartById(ctx context.Context, id string) (Cart, error) {
+	c, err := cs.repository.GetCartById(ctx, id)
+	if err != nil {
+		return Cart{}, err
+	}
+
+	if err := c.calculateTotal(); err != nil {
+		return Cart{}, err
+	}
+
+	return c, nil
+}
+
+// AddCartItem adds quantity to the item of the book, the item is created if the book is not in the cart yet.
+func (cs *CartService) AddCartItem(ctx context.Context, cartId string, bookId string, quantity int) (Cart, error) {
+	if err := cs.repository.AddCartItem(ctx, cartId, bookId, quantity); err != nil {
+		return Cart{}, err
+	}
+
+	return cs.GetCartById(ctx, cartId)
+}
+
+func (cs *CartService) UpdateCartItem(ctx context.Context, cartId string, bookId string, quantity int) (Cart, error) {
+	if err := cs.repository.UpdateCartItem(ctx, cartId, bookId, quantity); err != nil {
+		return Cart{}, err
+	}
+
+	return cs.GetCartById(ctx, cartId)
+}
+
+func (cs *CartService) RemoveCartItem(ctx context.Context, cartId string, bookId string) (Cart, error) {
+	if err := cs.repository.RemoveCartItem(ctx, cartId, bookId); err != nil {
+		return Cart{}, err
+	}
+
+	return cs.GetCartById(ctx, cartId)
+}
@@ -0,0 +1,74 @@
+package catalog
+
+import "github.com/cativovo/bookstore/internal/book"
+
+// Row is a book of an import, it is validated like the payload of POST /book.
+// The json is the shape of testdata/books.json, the id of a book is ignored.
+type Row struct {
+	Price           *book.Money `json:"price" validate:"required,gt=0"`
+	Title           string      `json:"title" validate:"required"`
+	Author          string      `json:"author" validate:"required"`
+	Description     string      `json:"description"`
+	CoverImage      string      `json:"cover_image"`
+	Genres          []string    `json:"genres" validate:"required,unique"`
+	Isbn            string      `json:"isbn" validate:"omitempty,isbn"`
+	Publisher       string      `json:"publisher" validate:"max=255"`
+	PublicationDate *book.Date  `json:"publication_date"`
+	Edition         string      `json:"edition" validate:"max=64"`
+	PageCount       *int        `json:"page_count" validate:"omitempty,gt=0"`
+	Language        string      `json:"language" validate:"omitempty,bcp47_language_tag"`
+	Format          book.Format `json:"format" validate:"omitempty,oneof=hardcover paperback ebook audiobook"`
+
+	// err is why the row couldn't be read from the file, the row fails without being validated
+	err error
+}
+
+type Status string
+
+const (
+	StatusCreated Status = "created"
+	// StatusSkipped is a row of a book the catalog or an earlier row already has
+	StatusSkipped Status = "skipped"
+	StatusFailed  Status = "failed"
+)
+
+type RowResult struct {
+	// Row is the position of the book in the file, the first book is row 1
+	Row    int    `json:"row"`
+	Status Status `json:"status"`
+	// Id is the id of the created book, or of the book a skipped row is a duplicate of
+	Id     string `json:"id,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type Report struct {
+	// DryRun is true when nothing was written, the created rows are the rows that would be created
+	DryRun  bool        `json:"dry_run"`
+	Created int         `json:"created"`
+	Skipped int         `json:"skipped"`
+	Failed  int         `json:"failed"`
+	Rows    []RowResult `json:"rows"`
+}
+
+type Options struct {
+	// CreateGenres creates the genres the catalog doesn't have, otherwise the rows with such a genre fail
+	CreateGenres bool
+	// ChunkSize is the number of books created per transaction, 0 creates every book in a single transaction
+	ChunkSize int
+	DryRun    bool
+}
+
+// BookError is the error of a book of CreateBooks, the transaction is rolled back so none of the books is created
+type BookError struct {
+	// Index is the index of the book in the books given to CreateBooks
+	Index int
+	Err   error
+}
+
+func (e *BookError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *BookError) Unwrap() error {
+	return e.Err
+}
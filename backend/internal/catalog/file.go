@@ -0,0 +1,189 @@
+package catalog
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cativovo/bookstore/internal/book"
+)
+
+// the genres of a book are one column of a csv file separated by this
+const genreSeparator = ";"
+
+// ReadJSON reads the rows from a json array shaped like testdata/books.json.
+// A book that isn't a valid object is a failed row, the other rows are still read.
+func ReadJSON(r io.Reader) ([]Row, error) {
+	var books []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&books); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, fmt.Errorf("%w: should be an array of books", ErrInvalidFile)
+		}
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+
+	rows := make([]Row, len(books))
+	for i, v := range books {
+		if err := json.Unmarshal(v, &rows[i]); err != nil {
+			rows[i] = Row{err: jsonErr(err)}
+		}
+	}
+
+	return rows, nil
+}
+
+func jsonErr(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		if typeErr.Field == "" {
+			return errors.New("should be an object")
+		}
+		return fmt.Errorf("invalid value for '%s'", typeErr.Field)
+	}
+
+	return err
+}
+
+// ReadCSV reads the rows from a csv file with a header of the columns, the columns are named like the fields of the json,
+// e.g. title,author,genres,price. The genres are separated by ';' and the price is a decimal string such as "10.10".
+// A record with a value that can't be parsed is a failed row, the other rows are still read.
+func ReadCSV(r io.Reader) ([]Row, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: the header is missing", ErrInvalidFile)
+		}
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if _, ok := csvColumns[column]; !ok {
+			return nil, fmt.Errorf("%w: unknown column '%s'", ErrInvalidFile, column)
+		}
+		header[i] = column
+	}
+
+	var rows []Row
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			// a record with a wrong number of fields is returned with the error and the reader can go on
+			if errors.Is(err, csv.ErrFieldCount) {
+				rows = append(rows, Row{err: fmt.Errorf("should have %d fields", len(header))})
+				continue
+			}
+			return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+		}
+
+		var row Row
+		for i, v := range record {
+			if err := csvColumns[header[i]](&row, strings.TrimSpace(v)); err != nil {
+				row = Row{err: fmt.Errorf("invalid value for '%s'", header[i])}
+				break
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// csvColumns sets the field of a column from its value, an empty value leaves the field unset
+var csvColumns = map[string]func(row *Row, v string) error{
+	// the id of the books of testdata/books.json, it is ignored like in the json
+	"id": func(row *Row, v string) error {
+		return nil
+	},
+	"title": func(row *Row, v string) error {
+		row.Title = v
+		return nil
+	},
+	"author": func(row *Row, v string) error {
+		row.Author = v
+		return nil
+	},
+	"description": func(row *Row, v string) error {
+		row.Description = v
+		return nil
+	},
+	"cover_image": func(row *Row, v string) error {
+		row.CoverImage = v
+		return nil
+	},
+	"genres": func(row *Row, v string) error {
+		row.Genres = []string{}
+		for _, genre := range strings.Split(v, genreSeparator) {
+			if genre = strings.TrimSpace(genre); genre != "" {
+				row.Genres = append(row.Genres, genre)
+			}
+		}
+		return nil
+	},
+	"price": func(row *Row, v string) error {
+		if v == "" {
+			return nil
+		}
+		price, err := book.ParseMoney(v, book.DefaultCurrency)
+		if err != nil {
+			return err
+		}
+		row.Price = &price
+		return nil
+	},
+	"isbn": func(row *Row, v string) error {
+		row.Isbn = v
+		return nil
+	},
+	"publisher": func(row *Row, v string) error {
+		row.Publisher = v
+		return nil
+	},
+	"publication_date": func(row *Row, v string) error {
+		if v == "" {
+			return nil
+		}
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			return err
+		}
+		row.PublicationDate = &book.Date{Time: t}
+		return nil
+	},
+	"edition": func(row *Row, v string) error {
+		row.Edition = v
+		return nil
+	},
+	"page_count": func(row *Row, v string) error {
+		if v == "" {
+			return nil
+		}
+		pageCount, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		row.PageCount = &pageCount
+		return nil
+	},
+	"language": func(row *Row, v string) error {
+		row.Language = v
+		return nil
+	},
+	"format": func(row *Row, v string) error {
+		row.Format = book.Format(v)
+		return nil
+	},
+}
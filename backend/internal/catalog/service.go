@@ -0,0 +1,226 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cativovo/bookstore/internal/book"
+)
+
+var ErrInvalidFile = errors.New("invalid file")
+
+const msgIsbnAlreadyExists = "a book with the isbn already exists"
+
+// Validator validates a row with the validator of the payloads of the api, the error is the message of the invalid fields
+type Validator interface {
+	ValidateStruct(s any) error
+}
+
+type CatalogRepository interface {
+	GetGenres(ctx context.Context) ([]book.Genre, error)
+	GetBookByIsbn(ctx context.Context, isbn13 string, currency string) (book.Book, error)
+	// CreateBooks creates the books in a single transaction, the genres the catalog doesn't have are created if createGenres is true.
+	// It returns a *BookError for the book the transaction failed at.
+	CreateBooks(ctx context.Context, books []book.Book, createGenres bool) ([]book.Book, error)
+}
+
+type CatalogService struct {
+	repository CatalogRepository
+	validator  Validator
+}
+
+func NewCatalogService(r CatalogRepository, v Validator) *CatalogService {
+	return &CatalogService{
+		repository: r,
+		validator:  v,
+	}
+}
+
+// Import creates the books of the rows and reports what was done with each row.
+// The rows that fail the validation, have a genre the catalog doesn't have without Options.CreateGenres or fail to be created are failed,
+// the rows with the ISBN of a book of the catalog or of an earlier row are skipped.
+// The books are created in chunks of Options.ChunkSize, a failed book rolls back the books of its chunk.
+func (cs *CatalogService) Import(ctx context.Context, rows []Row, opts Options) (Report, error) {
+	genres, err := cs.repository.GetGenres(ctx)
+	if err != nil {
+		return Report{}, err
+	}
+
+	knownGenres := make(map[string]bool, len(genres))
+	for _, g := range genres {
+		knownGenres[g.Name] = true
+	}
+
+	report := Report{
+		DryRun: opts.DryRun,
+		Rows:   make([]RowResult, len(rows)),
+	}
+
+	// the row of the first book of each isbn of the file
+	isbnRows := make(map[string]int)
+	// the indexes of the rows of the books to create
+	var pending []int
+	var books []book.Book
+
+	for i, row := range rows {
+		result := &report.Rows[i]
+		result.Row = i + 1
+
+		b, err := cs.prepare(ctx, row, knownGenres, isbnRows, opts, result)
+		if err != nil {
+			return Report{}, err
+		}
+		if result.Status != "" {
+			continue
+		}
+
+		if b.Isbn13 != "" {
+			isbnRows[b.Isbn13] = result.Row
+		}
+		pending = append(pending, i)
+		books = append(books, b)
+	}
+
+	if opts.DryRun {
+		for _, i := range pending {
+			report.Rows[i].Status = StatusCreated
+		}
+	} else {
+		cs.create(ctx, books, pending, opts, report.Rows)
+	}
+
+	for _, result := range report.Rows {
+		switch result.Status {
+		case StatusCreated:
+			report.Created++
+		case StatusSkipped:
+			report.Skipped++
+		case StatusFailed:
+			report.Failed++
+		}
+	}
+
+	return report, nil
+}
+
+// prepare returns the book of the row, the status of the result is set if the row isn't created
+func (cs *CatalogService) prepare(ctx context.Context, row Row, knownGenres map[string]bool, isbnRows map[string]int, opts Options, result *RowResult) (book.Book, error) {
+	fail := func(reason string) (book.Book, error) {
+		result.Status = StatusFailed
+		result.Reason = reason
+		return book.Book{}, nil
+	}
+
+	if row.err != nil {
+		return fail(row.err.Error())
+	}
+
+	if err := cs.validator.ValidateStruct(&row); err != nil {
+		return fail(err.Error())
+	}
+
+	if row.Price.Currency != book.DefaultCurrency {
+		return fail(fmt.Sprintf("'price' should be in %s", book.DefaultCurrency))
+	}
+
+	if !opts.CreateGenres {
+		for _, genre := range row.Genres {
+			if !knownGenres[genre] {
+				return fail(fmt.Sprintf("unknown genre '%s'", genre))
+			}
+		}
+	}
+
+	var isbn13 string
+	if row.Isbn != "" {
+		isbn, err := book.ParseISBN(row.Isbn)
+		if err != nil {
+			return fail(err.Error())
+		}
+		isbn13 = isbn
+
+		if first, ok := isbnRows[isbn13]; ok {
+			result.Status = StatusSkipped
+			result.Reason = fmt.Sprintf("the isbn is a duplicate of row %d", first)
+			return book.Book{}, nil
+		}
+
+		existing, err := cs.repository.GetBookByIsbn(ctx, isbn13, book.DefaultCurrency)
+		switch {
+		case err == nil:
+			result.Status = StatusSkipped
+			result.Id = existing.Id
+			result.Reason = msgIsbnAlreadyExists
+			return book.Book{}, nil
+		case !errors.Is(err, book.ErrNotFound):
+			return book.Book{}, err
+		}
+	}
+
+	return book.Book{
+		Title:           row.Title,
+		Author:          row.Author,
+		Description:     row.Description,
+		CoverImage:      book.CoverImage(row.CoverImage),
+		Price:           *row.Price,
+		Genres:          row.Genres,
+		Isbn13:          isbn13,
+		Publisher:       row.Publisher,
+		PublicationDate: row.PublicationDate,
+		Edition:         row.Edition,
+		PageCount:       row.PageCount,
+		Language:        row.Language,
+		Format:          row.Format,
+	}, nil
+}
+
+// create creates the books in chunks and sets the results of their rows, pending is the index of the row of each book
+func (cs *CatalogService) create(ctx context.Context, books []book.Book, pending []int, opts Options, results []RowResult) {
+	size := opts.ChunkSize
+	if size <= 0 {
+		size = len(books)
+	}
+
+	for start := 0; start < len(books); start += size {
+		end := min(start+size, len(books))
+
+		created, err := cs.repository.CreateBooks(ctx, books[start:end], opts.CreateGenres)
+		if err != nil {
+			reason := err.Error()
+			failedRow := 0
+
+			var bookErr *BookError
+			if errors.As(err, &bookErr) {
+				failedRow = results[pending[start+bookErr.Index]].Row
+				reason = fmt.Sprintf("row %d failed in the same transaction", failedRow)
+			}
+
+			for _, i := range pending[start:end] {
+				results[i].Status = StatusFailed
+				results[i].Reason = reason
+				if results[i].Row == failedRow {
+					results[i].Reason = bookErrReason(bookErr.Err)
+				}
+			}
+
+			continue
+		}
+
+		for j, b := range created {
+			results[pending[start+j]].Status = StatusCreated
+			results[pending[start+j]].Id = b.Id
+		}
+	}
+}
+
+func bookErrReason(err error) string {
+	switch {
+	case errors.Is(err, book.ErrAlreadyExists):
+		return msgIsbnAlreadyExists
+	case errors.Is(err, book.ErrInvalidGenre):
+		return "invalid genre"
+	default:
+		return err.Error()
+	}
+}
@@ -0,0 +1,34 @@
+package change
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type Entity string
+
+const (
+	EntityBook  Entity = "book"
+	EntityGenre Entity = "genre"
+)
+
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	// ActionDelete is a tombstone, the entity has to be removed by the consumers
+	ActionDelete Action = "delete"
+)
+
+// Change is an event of the change feed of the catalog.
+// Data is the entity after the change, e.g. a book.Book, it is null for a delete.
+type Change struct {
+	CreatedAt time.Time `json:"created_at"`
+	// Token resumes the feed after this change
+	Token    string          `json:"token"`
+	Entity   Entity          `json:"entity"`
+	EntityId string          `json:"entity_id"`
+	Action   Action          `json:"action"`
+	Data     json.RawMessage `json:"data"`
+}
@@ -0,0 +1,40 @@
+package change
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+type ChangeRepository interface {
+	// GetChanges returns at most limit changes after the token in the order they were committed, an empty token starts from the first change.
+	// It returns ErrInvalidToken if the token was not returned by the feed.
+	GetChanges(ctx context.Context, since string, limit int) ([]Change, error)
+}
+
+type ChangeService struct {
+	repository ChangeRepository
+}
+
+func NewChangeService(r ChangeRepository) *ChangeService {
+	return &ChangeService{
+		repository: r,
+	}
+}
+
+// GetChanges returns the changes after the token and the token to resume from,
+// the token is the same as since if there is no change yet so it can be polled again.
+func (cs *ChangeService) GetChanges(ctx context.Context, since string, limit int) ([]Change, string, error) {
+	changes, err := cs.repository.GetChanges(ctx, since, limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	next := since
+	if len(changes) > 0 {
+		next = changes[len(changes)-1].Token
+	}
+
+	return changes, next, nil
+}
@@ -0,0 +1,108 @@
+package cover
+
+import (
+	"image"
+	"image/color"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/cativovo/bookstore/internal/book"
+)
+
+const (
+	// MaxSize is the largest cover that can be uploaded in bytes
+	MaxSize = 5 << 20
+	// MaxPixels bounds the dimensions of a cover so a small file can't decode to a huge image,
+	// e.g. 2000x3000 decodes to 23 MiB of RGBA, plenty for a large thumbnail 600 pixels wide
+	MaxPixels = 6_000_000
+	// sniffLen is the number of bytes http.DetectContentType looks at
+	sniffLen = 512
+)
+
+// thumbnailWidths are the widths the thumbnails are resized to, the height keeps the aspect ratio
+var thumbnailWidths = map[string]int{
+	book.CoverSizeLarge:  600,
+	book.CoverSizeMedium: 300,
+	book.CoverSizeSmall:  150,
+}
+
+// contentTypes are the types of image a cover can be uploaded as, with the extension the original is stored with
+var contentTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// the files of the uploaded covers, e.g. 9b1deb4d3b7d4bad9bdd2b0d7b3dcb6d_small.jpg
+var keyPattern = regexp.MustCompile("^[0-9a-f]{32}_(" + strings.Join(book.CoverSizes, "|") + `)\.(jpg|png|gif)$`)
+
+// ValidKey reports whether the key is the name of a file of an uploaded cover, the other keys are never served.
+func ValidKey(key string) bool {
+	return keyPattern.MatchString(key)
+}
+
+// sniff returns the extension of the original from the content of the file, the type the client claims is ignored
+func sniff(head []byte) (string, bool) {
+	contentType := http.DetectContentType(head)
+	ext, ok := contentTypes[contentType]
+	return ext, ok
+}
+
+// thumbnail scales the image down to the width by averaging the pixels each pixel of the thumbnail covers,
+// an image narrower than the width is kept at its size
+func thumbnail(src image.Image, width int) *image.RGBA {
+	bounds := src.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+
+	if srcWidth <= width {
+		width = srcWidth
+	}
+
+	height := srcHeight * width / srcWidth
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*srcHeight/height
+		y1 := max(bounds.Min.Y+(y+1)*srcHeight/height, y0+1)
+
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*srcWidth/width
+			x1 := max(bounds.Min.X+(x+1)*srcWidth/width, x0+1)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(b / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+
+	return dst
+}
+
+// flatten puts the transparent pixels of the image on white in place, jpeg has no transparency
+func flatten(img *image.RGBA) {
+	for i := 0; i < len(img.Pix); i += 4 {
+		// the pixels are alpha premultiplied, adding the white left uncovered can't overflow
+		white := 0xff - img.Pix[i+3]
+		img.Pix[i] += white
+		img.Pix[i+1] += white
+		img.Pix[i+2] += white
+		img.Pix[i+3] = 0xff
+	}
+}
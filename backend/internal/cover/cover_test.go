@@ -0,0 +1,226 @@
+package cover
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThumbnail(t *testing.T) {
+	tests := []struct {
+		name           string
+		width          int
+		height         int
+		thumbnailWidth int
+		expectedSize   image.Point
+	}{
+		{
+			name:           "Scaled down",
+			width:          1200,
+			height:         1800,
+			thumbnailWidth: 600,
+			expectedSize:   image.Pt(600, 900),
+		},
+		{
+			name:           "Aspect ratio rounded down",
+			width:          1000,
+			height:         1001,
+			thumbnailWidth: 150,
+			expectedSize:   image.Pt(150, 150),
+		},
+		{
+			name:           "Narrower than the width",
+			width:          400,
+			height:         100,
+			thumbnailWidth: 600,
+			expectedSize:   image.Pt(400, 100),
+		},
+		{
+			name:           "Height of at least 1",
+			width:          3000,
+			height:         2,
+			thumbnailWidth: 150,
+			expectedSize:   image.Pt(150, 1),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			src := image.NewRGBA(image.Rect(0, 0, test.width, test.height))
+
+			assert.Equal(t, test.expectedSize, thumbnail(src, test.thumbnailWidth).Bounds().Size())
+		})
+	}
+}
+
+func TestThumbnailAverage(t *testing.T) {
+	// the bounds don't start at 0, e.g. a sub image
+	src := image.NewRGBA(image.Rect(10, 10, 14, 12))
+	for x := 10; x < 14; x++ {
+		for y := 10; y < 12; y++ {
+			src.Set(x, y, color.RGBA{R: 200, A: 255})
+		}
+	}
+	src.Set(12, 10, color.RGBA{B: 200, A: 255})
+	src.Set(13, 10, color.RGBA{B: 200, A: 255})
+	src.Set(12, 11, color.RGBA{B: 200, A: 255})
+	src.Set(13, 11, color.RGBA{B: 200, A: 255})
+
+	thumb := thumbnail(src, 2)
+
+	assert.Equal(t, image.Rect(0, 0, 2, 1), thumb.Bounds())
+	assert.Equal(t, color.RGBA{R: 200, A: 255}, thumb.RGBAAt(0, 0))
+	assert.Equal(t, color.RGBA{B: 200, A: 255}, thumb.RGBAAt(1, 0))
+}
+
+func TestFlatten(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          color.RGBA
+		expectedOutput color.RGBA
+	}{
+		{
+			name:           "Opaque",
+			input:          color.RGBA{R: 200, G: 100, B: 50, A: 255},
+			expectedOutput: color.RGBA{R: 200, G: 100, B: 50, A: 255},
+		},
+		{
+			name:           "Transparent",
+			input:          color.RGBA{},
+			expectedOutput: color.RGBA{R: 255, G: 255, B: 255, A: 255},
+		},
+		{
+			// half transparent red is premultiplied to 128, the white under it adds 127
+			name:           "Half transparent",
+			input:          color.RGBA{R: 128, A: 128},
+			expectedOutput: color.RGBA{R: 255, G: 127, B: 127, A: 255},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+			img.SetRGBA(0, 0, test.input)
+
+			flatten(img)
+
+			assert.Equal(t, test.expectedOutput, img.RGBAAt(0, 0))
+		})
+	}
+}
+
+func TestFlattenThumbnail(t *testing.T) {
+	// an opaque white pixel next to a transparent one is white once put on white
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	src.Set(0, 0, color.White)
+	src.Set(1, 0, color.Transparent)
+
+	thumb := thumbnail(src, 1)
+	flatten(thumb)
+
+	assert.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, thumb.RGBAAt(0, 0))
+}
+
+func TestSniff(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          []byte
+		expectedOutput string
+		expectedOk     bool
+	}{
+		{
+			name:           "Jpeg",
+			input:          []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00"),
+			expectedOutput: ".jpg",
+			expectedOk:     true,
+		},
+		{
+			name:           "Png",
+			input:          []byte("\x89PNG\x0d\x0a\x1a\x0a\x00\x00\x00\x0dIHDR"),
+			expectedOutput: ".png",
+			expectedOk:     true,
+		},
+		{
+			name:           "Gif",
+			input:          []byte("GIF89a\x01\x00\x01\x00"),
+			expectedOutput: ".gif",
+			expectedOk:     true,
+		},
+		{
+			name:  "Webp",
+			input: []byte("RIFF\x00\x00\x00\x00WEBPVP8 "),
+		},
+		{
+			name:  "Svg",
+			input: []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`),
+		},
+		{
+			name:  "Text",
+			input: []byte("this is not an image"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ext, ok := sniff(test.input)
+
+			assert.Equal(t, test.expectedOk, ok)
+			assert.Equal(t, test.expectedOutput, ext)
+		})
+	}
+}
+
+func TestValidKey(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		expectedOutput bool
+	}{
+		{
+			name:           "Original",
+			input:          "9b1deb4d3b7d4bad9bdd2b0d7b3dcb6d_original.png",
+			expectedOutput: true,
+		},
+		{
+			name:           "Thumbnail",
+			input:          "9b1deb4d3b7d4bad9bdd2b0d7b3dcb6d_small.jpg",
+			expectedOutput: true,
+		},
+		{
+			name:  "Unknown size",
+			input: "9b1deb4d3b7d4bad9bdd2b0d7b3dcb6d_huge.jpg",
+		},
+		{
+			name:  "Unknown extension",
+			input: "9b1deb4d3b7d4bad9bdd2b0d7b3dcb6d_original.svg",
+		},
+		{
+			name:  "Uppercase id",
+			input: "9B1DEB4D3B7D4BAD9BDD2B0D7B3DCB6D_small.jpg",
+		},
+		{
+			name:  "Short id",
+			input: "9b1deb4d_small.jpg",
+		},
+		{
+			name:  "Path",
+			input: "../9b1deb4d3b7d4bad9bdd2b0d7b3dcb6d_small.jpg",
+		},
+		{
+			name:  "Temporary file",
+			input: ".upload-123456",
+		},
+		{
+			name:  "Empty",
+			input: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expectedOutput, ValidKey(test.input))
+		})
+	}
+}
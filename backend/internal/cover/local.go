@@ -0,0 +1,95 @@
+package cover
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// LocalBlobStore stores the files in a directory of the local filesystem.
+type LocalBlobStore struct {
+	dir string
+}
+
+// NewLocalBlobStore creates the directory if it doesn't exist.
+func NewLocalBlobStore(dir string) (*LocalBlobStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &LocalBlobStore{dir: dir}, nil
+}
+
+// Put writes the file to a temporary file first so a file is never read half written.
+func (s *LocalBlobStore) Put(ctx context.Context, key string, r io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *LocalBlobStore) Get(ctx context.Context, key string) (Blob, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return Blob{}, ErrNotFound
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return Blob{}, ErrNotFound
+		}
+
+		return Blob{}, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return Blob{}, err
+	}
+
+	return Blob{ReadSeekCloser: f, ModTime: info.ModTime()}, nil
+}
+
+func (s *LocalBlobStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// path returns the path of the file of the key, the key can't point outside of the directory
+func (s *LocalBlobStore) path(key string) (string, error) {
+	if key == "" || key != filepath.Base(key) || key[0] == '.' {
+		return "", errors.New("invalid key: " + key)
+	}
+
+	return filepath.Join(s.dir, key), nil
+}
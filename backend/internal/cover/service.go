@@ -0,0 +1,166 @@
+package cover
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"image"
+	"image/jpeg"
+	"io"
+	"time"
+
+	// the decoders of the types in contentTypes
+	_ "image/gif"
+	_ "image/png"
+
+	"github.com/cativovo/bookstore/internal/book"
+)
+
+var (
+	ErrNotFound        = errors.New("cover not found")
+	ErrTooLarge        = errors.New("cover too large")
+	ErrUnsupportedType = errors.New("unsupported cover type")
+	ErrInvalidImage    = errors.New("invalid image")
+)
+
+// Blob is a stored file, it has to be closed.
+type Blob struct {
+	io.ReadSeekCloser
+	ModTime time.Time
+}
+
+// BlobStore stores the files of the covers by key, the key is the name of the file.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	// Get returns ErrNotFound if there is no file with the key.
+	Get(ctx context.Context, key string) (Blob, error)
+	// Delete doesn't fail if there is no file with the key.
+	Delete(ctx context.Context, key string) error
+}
+
+type CoverService struct {
+	store       BlobStore
+	bookService *book.BookService
+}
+
+func NewCoverService(s BlobStore, bs *book.BookService) *CoverService {
+	return &CoverService{
+		store:       s,
+		bookService: bs,
+	}
+}
+
+// UploadCover stores the image with its thumbnails and sets it as the cover of the book, the previous uploaded cover is deleted.
+// It returns ErrTooLarge if the image is bigger than MaxSize or MaxPixels, ErrUnsupportedType if it is not a jpeg, png or gif
+// and ErrInvalidImage if it can't be decoded.
+func (cs *CoverService) UploadCover(ctx context.Context, bookId string, r io.Reader) (book.Book, error) {
+	b, err := cs.bookService.GetBookById(ctx, bookId, "")
+	if err != nil {
+		return book.Book{}, err
+	}
+
+	// one more byte tells if the file is too large
+	data, err := io.ReadAll(io.LimitReader(r, MaxSize+1))
+	if err != nil {
+		return book.Book{}, err
+	}
+
+	if len(data) > MaxSize {
+		return book.Book{}, ErrTooLarge
+	}
+
+	ext, ok := sniff(data[:min(len(data), sniffLen)])
+	if !ok {
+		return book.Book{}, ErrUnsupportedType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return book.Book{}, ErrInvalidImage
+	}
+
+	if config.Width*config.Height > MaxPixels {
+		return book.Book{}, ErrTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return book.Book{}, ErrInvalidImage
+	}
+
+	id, err := newUploadId()
+	if err != nil {
+		return book.Book{}, err
+	}
+
+	coverImage := book.UploadedCoverImage(id, ext)
+	keys := coverImage.Keys()
+
+	if err := cs.putCover(ctx, id, ext, data, img); err != nil {
+		cs.deleteKeys(ctx, keys)
+		return book.Book{}, err
+	}
+
+	url := string(coverImage)
+	updated, err := cs.bookService.UpdateBook(ctx, bookId, book.UpdateBookOptions{CoverImage: &url})
+	if err != nil {
+		cs.deleteKeys(ctx, keys)
+		return book.Book{}, err
+	}
+
+	// the urls of the previous cover are cached as immutable, a new upload always has new urls
+	cs.deleteKeys(ctx, b.CoverImage.Keys())
+
+	return updated, nil
+}
+
+// GetCover returns the file of a size of an uploaded cover, it returns ErrNotFound if the key is not a file of an uploaded cover.
+func (cs *CoverService) GetCover(ctx context.Context, key string) (Blob, error) {
+	if !ValidKey(key) {
+		return Blob{}, ErrNotFound
+	}
+
+	return cs.store.Get(ctx, key)
+}
+
+// putCover stores the original as uploaded and a jpeg thumbnail for each size
+func (cs *CoverService) putCover(ctx context.Context, id string, ext string, original []byte, img image.Image) error {
+	if err := cs.store.Put(ctx, book.CoverKey(id, book.CoverSizeOriginal, ext), bytes.NewReader(original)); err != nil {
+		return err
+	}
+
+	for size, width := range thumbnailWidths {
+		// the thumbnail is put on white rather than the original, a copy of the original would double the memory of an upload
+		thumb := thumbnail(img, width)
+		flatten(thumb)
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: 85}); err != nil {
+			return err
+		}
+
+		if err := cs.store.Put(ctx, book.CoverKey(id, size, ext), &buf); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteKeys deletes the files best effort, a file left behind only takes space
+func (cs *CoverService) deleteKeys(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		_ = cs.store.Delete(ctx, key)
+	}
+}
+
+func newUploadId() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,17 @@
+package exchange
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Rate converts prices from book.DefaultCurrency to Currency.
+type Rate struct {
+	UpdatedAt time.Time `json:"updated_at"`
+	Currency  string    `json:"currency"`
+	// Rate is the amount of Currency for 1 book.DefaultCurrency, it is kept as the decimal text so it stays exact
+	Rate json.Number `json:"rate"`
+	// Rounding is the increment in the minor unit of Currency that converted prices are rounded to,
+	// e.g. 5 rounds CHF to 0.05 and 100 rounds JPY to 100 yen
+	Rounding int64 `json:"rounding"`
+}
@@ -0,0 +1,26 @@
+package exchange
+
+import (
+	"encoding/json"
+	"os"
+)
+
+type ratesFile struct {
+	Rates []Rate `json:"rates"`
+}
+
+// ReadRatesFile reads the rates from a json file shaped like {"rates":[{"currency":"EUR","rate":"0.93"}]}
+// so they can be loaded without the admin endpoint.
+func ReadRatesFile(path string) ([]Rate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f ratesFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+
+	return f.Rates, nil
+}
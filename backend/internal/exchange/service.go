@@ -0,0 +1,61 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/cativovo/bookstore/internal/book"
+)
+
+var ErrInvalidRate = errors.New("invalid rate")
+
+// the rates are stored as NUMERIC(20, 10)
+var rateRegexp = regexp.MustCompile(`^[0-9]{1,10}(\.[0-9]{1,10})?$`)
+
+type ExchangeRepository interface {
+	UpsertExchangeRates(ctx context.Context, rates []Rate) error
+	GetExchangeRates(ctx context.Context) ([]Rate, error)
+}
+
+type ExchangeService struct {
+	repository ExchangeRepository
+}
+
+func NewExchangeService(r ExchangeRepository) *ExchangeService {
+	return &ExchangeService{
+		repository: r,
+	}
+}
+
+func (es *ExchangeService) GetRates(ctx context.Context) ([]Rate, error) {
+	return es.repository.GetExchangeRates(ctx)
+}
+
+// UpdateRates adds the rates or replaces the ones of the same currencies, a zero Rounding rounds to the minor unit.
+func (es *ExchangeService) UpdateRates(ctx context.Context, rates []Rate) error {
+	for i, r := range rates {
+		if r.Currency == book.DefaultCurrency {
+			return fmt.Errorf("%w: the rates are relative to %s", book.ErrInvalidCurrency, book.DefaultCurrency)
+		}
+
+		if !rateRegexp.MatchString(r.Rate.String()) {
+			return fmt.Errorf("%w: '%s' of %s should be a decimal number", ErrInvalidRate, r.Rate, r.Currency)
+		}
+
+		if f, err := r.Rate.Float64(); err != nil || f == 0 {
+			return fmt.Errorf("%w: '%s' of %s should be greater than 0", ErrInvalidRate, r.Rate, r.Currency)
+		}
+
+		if r.Rounding < 0 {
+			return fmt.Errorf("%w: rounding of %s should not be negative", ErrInvalidRate, r.Currency)
+		}
+
+		if r.Rounding == 0 {
+			rates[i].Rounding = 1
+		}
+	}
+
+	return es.repository.UpsertExchangeRates(ctx, rates)
+}
@@ -0,0 +1,30 @@
+package inventory
+
+import "time"
+
+type Reason string
+
+const (
+	ReasonRestock    Reason = "restock"
+	ReasonSale       Reason = "sale"
+	ReasonReturn     Reason = "return"
+	ReasonDamaged    Reason = "damaged"
+	ReasonCorrection Reason = "correction"
+)
+
+type Stock struct {
+	BookId         string `json:"book_id"`
+	QuantityOnHand int    `json:"quantity_on_hand"`
+	Reserved       int    `json:"reserved"`
+}
+
+// Adjustment is a change of the quantity on hand of a book, Quantity is negative when stock is removed.
+type Adjustment struct {
+	CreatedAt      time.Time `json:"created_at"`
+	Id             string    `json:"id"`
+	BookId         string    `json:"book_id"`
+	Reason         Reason    `json:"reason"`
+	Note           string    `json:"note"`
+	Quantity       int       `json:"quantity"`
+	QuantityOnHand int       `json:"quantity_on_hand"`
+}
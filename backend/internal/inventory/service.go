@@ -0,0 +1,33 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrInsufficientStock = errors.New("insufficient stock")
+
+type InventoryRepository interface {
+	AdjustStock(ctx context.Context, a Adjustment) (Adjustment, error)
+	GetAdjustments(ctx context.Context, bookId string, limit int, offset int) (adjustments []Adjustment, count int, err error)
+}
+
+type InventoryService struct {
+	repository InventoryRepository
+}
+
+func NewInventoryService(r InventoryRepository) *InventoryService {
+	return &InventoryService{
+		repository: r,
+	}
+}
+
+// AdjustStock adds a.Quantity to the quantity on hand of the book and records the adjustment.
+// It returns ErrInsufficientStock if the quantity on hand would go below zero or below the reserved quantity.
+func (is *InventoryService) AdjustStock(ctx context.Context, a Adjustment) (Adjustment, error) {
+	return is.repository.AdjustStock(ctx, a)
+}
+
+func (is *InventoryService) GetAdjustments(ctx context.Context, bookId string, limit int, offset int) (adjustments []Adjustment, count int, err error) {
+	return is.repository.GetAdjustments(ctx, bookId, limit, offset)
+}
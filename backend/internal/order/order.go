@@ -0,0 +1,72 @@
+package order
+
+import (
+	"time"
+
+	"github.com/cativovo/bookstore/internal/book"
+)
+
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusPaid      Status = "paid"
+	StatusShipped   Status = "shipped"
+	StatusDelivered Status = "delivered"
+	StatusCancelled Status = "cancelled"
+	StatusRefunded  Status = "refunded"
+)
+
+var transitions = map[Status][]Status{
+	StatusPending:   {StatusPaid, StatusCancelled},
+	StatusPaid:      {StatusShipped, StatusRefunded},
+	StatusShipped:   {StatusDelivered},
+	StatusDelivered: {StatusRefunded},
+}
+
+func (s Status) CanTransitionTo(next Status) bool {
+	for _, v := range transitions[s] {
+		if v == next {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Item is a snapshot of the book at purchase time, BookId is empty if the book has been deleted since.
+type Item struct {
+	BookId   string     `json:"book_id"`
+	Title    string     `json:"title"`
+	Author   string     `json:"author"`
+	Price    book.Money `json:"price"`
+	Quantity int        `json:"quantity"`
+	Subtotal book.Money `json:"subtotal"`
+}
+
+type Order struct {
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	Id        string     `json:"id"`
+	Status    Status     `json:"status"`
+	Items     []Item     `json:"items"`
+	Total     book.Money `json:"total"`
+}
+
+// calculateTotal sets the subtotal of every item and the total of the order from the snapshotted prices.
+func (o *Order) calculateTotal() error {
+	o.Total = book.NewMoney(0, book.DefaultCurrency)
+
+	for i := range o.Items {
+		o.Items[i].Subtotal = o.Items[i].Price.Mul(o.Items[i].Quantity)
+
+		total, err := o.Total.Add(o.Items[i].Subtotal)
+		if err != nil {
+			return err
+		}
+
+		o.Total = total
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package order
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrNotFound          = errors.New("order not found")
+	ErrEmptyOrder        = errors.New("order has no items")
+	ErrInvalidTransition = errors.New("invalid status transition")
+)
+
+// LineItem is a book to order, CreateOrder snapshots the title, author and price of the book.
+type LineItem struct {
+	BookId   string
+	Quantity int
+}
+
+// OrderRepository returns book.ErrNotFound when a book id does not match any book and
+// inventory.ErrInsufficientStock when the stock of a book cannot be reserved.
+type OrderRepository interface {
+	CreateOrder(ctx context.Context, items []LineItem) (Order, error)
+	CreateOrderFromCart(ctx context.Context, cartId string) (Order, error)
+	GetOrderById(ctx context.Context, id string) (Order, error)
+	GetOrders(ctx context.Context, limit int, offset int) (orders []Order, count int, err error)
+	// UpdateOrderStatus returns ErrInvalidTransition if the status of the order is no longer current.
+	UpdateOrderStatus(ctx context.Context, id string, current Status, next Status) error
+}
+
+type OrderService struct {
+	repository OrderRepository
+}
+
+func NewOrderService(r OrderRepository) *OrderService {
+	return &OrderService{
+		repository: r,
+	}
+}
+
+// Checkout creates a pending order, quantities of the same book are added together.
+func (os *OrderService) Checkout(ctx context.Context, items []LineItem) (Order, error) {
+	quantities := make(map[string]int)
+
+	for _, v := range items {
+		quantities[v.BookId] += v.Quantity
+	}
+
+	if len(quantities) == 0 {
+		return Order{}, ErrEmptyOrder
+	}
+
+	lineItems := make([]LineItem, 0, len(quantities))
+
+	for bookId, quantity := range quantities {
+		lineItems = append(lineItems, LineItem{BookId: bookId, Quantity: quantity})
+	}
+
+	o, err := os.repository.CreateOrder(ctx, lineItems)
+	if err != nil {
+		return Order{}, err
+	}
+
+	if err := o.calculateTotal(); err != nil {
+		return Order{}, err
+	}
+
+	return o, nil
+}
+
+// CheckoutCart creates a pending order from the items of the cart then deletes the cart.
+func (os *OrderService) CheckoutCart(ctx context.Context, cartId string) (Order, error) {
+	o, err := os.repository.CreateOrderFromCart(ctx, cartId)
+	if err != nil {
+		return Order{}, err
+	}
+
+	if err := o.calculateTotal(); err != nil {
+		return Order{}, err
+	}
+
+	return o, nil
+}
+
+func (os *OrderService) GetOrderById(ctx context.Context, id string) (Order, error) {
+	o, err := os.repository.GetOrderById(ctx, id)
+	if err != nil {
+		return Order{}, err
+	}
+
+	if err := o.calculateTotal(); err != nil {
+		return Order{}, err
+	}
+
+	return o, nil
+}
+
+func (os *OrderService) GetOrders(ctx context.Context, limit int, offset int) (orders []Order, count int, err error) {
+	orders, count, err = os.repository.GetOrders(ctx, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i := range orders {
+		if err := orders[i].calculateTotal(); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return orders, count, nil
+}
+
+func (os *OrderService) UpdateOrderStatus(ctx context.Context, id string, status Status) (Order, error) {
+	o, err := os.repository.GetOrderById(ctx, id)
+	if err != nil {
+		return Order{}, err
+	}
+
+	if !o.Status.CanTransitionTo(status) {
+		return Order{}, ErrInvalidTransition
+	}
+
+	if err := os.repository.UpdateOrderStatus(ctx, id, o.Status, status); err != nil {
+		return Order{}, err
+	}
+
+	return os.GetOrderById(ctx, id)
+}
@@ -0,0 +1,180 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// magic card numbers of the fake provider, every other card number is approved
+const (
+	CardApproved          = "4242424242424242"
+	CardDeclined          = "4000000000000002"
+	CardInsufficientFunds = "4000000000009995"
+)
+
+const FakeSignatureHeader = "X-Fake-Signature"
+
+// FakeProvider is an in-process PaymentProvider for development and tests.
+// It posts signed events to webhookURL when a charge is captured or refunded, no event is sent if webhookURL is empty.
+type FakeProvider struct {
+	client     *http.Client
+	charges    map[string]Charge
+	secret     []byte
+	webhookURL string
+	// the events are sent in the background so there is no caller to return their error to
+	webhookErr error
+	mu         sync.Mutex
+}
+
+func NewFakeProvider(secret string, webhookURL string) *FakeProvider {
+	return &FakeProvider{
+		client:     &http.Client{Timeout: time.Second * 5},
+		charges:    make(map[string]Charge),
+		secret:     []byte(secret),
+		webhookURL: webhookURL,
+	}
+}
+
+func (fp *FakeProvider) Authorize(ctx context.Context, r AuthorizeRequest) (Charge, error) {
+	switch r.CardNumber {
+	case CardDeclined, CardInsufficientFunds:
+		return Charge{}, ErrDeclined
+	}
+
+	id, err := newChargeId()
+	if err != nil {
+		return Charge{}, err
+	}
+
+	c := Charge{
+		Id:       id,
+		Status:   StatusAuthorized,
+		Currency: r.Currency,
+		Amount:   r.Amount,
+	}
+
+	fp.mu.Lock()
+	fp.charges[id] = c
+	fp.mu.Unlock()
+
+	return c, nil
+}
+
+func (fp *FakeProvider) Capture(ctx context.Context, chargeId string) (Charge, error) {
+	return fp.updateCharge(chargeId, StatusAuthorized, StatusCaptured, EventChargeCaptured)
+}
+
+func (fp *FakeProvider) Void(ctx context.Context, chargeId string) (Charge, error) {
+	return fp.updateCharge(chargeId, StatusAuthorized, StatusVoided, "")
+}
+
+func (fp *FakeProvider) Refund(ctx context.Context, chargeId string) (Charge, error) {
+	return fp.updateCharge(chargeId, StatusCaptured, StatusRefunded, EventChargeRefunded)
+}
+
+// WebhookErr returns the error of the last event that couldn't be sent to the webhook, nil if every event has been sent.
+func (fp *FakeProvider) WebhookErr() error {
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+
+	return fp.webhookErr
+}
+
+func (fp *FakeProvider) VerifyWebhook(payload []byte, header http.Header) (Event, error) {
+	expected, err := hex.DecodeString(header.Get(FakeSignatureHeader))
+	if err != nil || !hmac.Equal(expected, fp.sign(payload)) {
+		return Event{}, ErrInvalidSignature
+	}
+
+	var e Event
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return Event{}, err
+	}
+
+	return e, nil
+}
+
+// Sign returns the signature of the payload the way the fake provider signs its webhook requests.
+func (fp *FakeProvider) Sign(payload []byte) string {
+	return hex.EncodeToString(fp.sign(payload))
+}
+
+func (fp *FakeProvider) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, fp.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+func (fp *FakeProvider) updateCharge(chargeId string, from Status, to Status, eventType EventType) (Charge, error) {
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+
+	c, ok := fp.charges[chargeId]
+	if !ok {
+		return Charge{}, ErrChargeNotFound
+	}
+
+	if c.Status != from {
+		return Charge{}, fmt.Errorf("cannot change charge status from '%s' to '%s'", c.Status, to)
+	}
+
+	c.Status = to
+	fp.charges[chargeId] = c
+
+	// a voided charge has no event
+	if fp.webhookURL != "" && eventType != "" {
+		go func(e Event) {
+			if err := fp.sendEvent(e); err != nil {
+				fp.mu.Lock()
+				fp.webhookErr = err
+				fp.mu.Unlock()
+			}
+		}(Event{Type: eventType, Charge: c})
+	}
+
+	return c, nil
+}
+
+func (fp *FakeProvider) sendEvent(e Event) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fp.webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(FakeSignatureHeader, fp.Sign(payload))
+
+	res, err := fp.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("webhook responded to '%s' with status %d", e.Type, res.StatusCode)
+	}
+
+	return nil
+}
+
+func newChargeId() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return "ch_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,31 @@
+package payment
+
+import "time"
+
+type Status string
+
+const (
+	// StatusPending claims the order before the card is charged, the payment has no charge yet
+	StatusPending    Status = "pending"
+	StatusAuthorized Status = "authorized"
+	StatusCaptured   Status = "captured"
+	// StatusRefunding claims the captured payment while the provider refunds it
+	StatusRefunding Status = "refunding"
+	StatusRefunded  Status = "refunded"
+	// StatusFailed is a payment that was declined or whose charge was voided
+	StatusFailed Status = "failed"
+	// StatusVoided is a charge whose authorization was released without being captured
+	StatusVoided Status = "voided"
+)
+
+// Payment is a charge of the payment provider for an order, Amount is in the minor unit of Currency.
+type Payment struct {
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	Id                string    `json:"id"`
+	OrderId           string    `json:"order_id"`
+	ProviderPaymentId string    `json:"provider_payment_id"`
+	Status            Status    `json:"status"`
+	Currency          string    `json:"currency"`
+	Amount            int64     `json:"amount"`
+}
@@ -0,0 +1,52 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+var (
+	ErrDeclined         = errors.New("payment declined")
+	ErrChargeNotFound   = errors.New("charge not found")
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+type EventType string
+
+const (
+	EventChargeCaptured EventType = "charge.captured"
+	EventChargeRefunded EventType = "charge.refunded"
+)
+
+type AuthorizeRequest struct {
+	// Reference is our id of the payment, the order id
+	Reference  string
+	CardNumber string
+	Currency   string
+	Amount     int64
+}
+
+type Charge struct {
+	Id       string `json:"id"`
+	Status   Status `json:"status"`
+	Currency string `json:"currency"`
+	Amount   int64  `json:"amount"`
+}
+
+// Event is sent by the provider to the webhook when a charge changes.
+type Event struct {
+	Type   EventType `json:"type"`
+	Charge Charge    `json:"charge"`
+}
+
+type PaymentProvider interface {
+	// Authorize holds the amount on the card, it returns ErrDeclined if the card is declined.
+	Authorize(ctx context.Context, r AuthorizeRequest) (Charge, error)
+	Capture(ctx context.Context, chargeId string) (Charge, error)
+	// Void releases the amount held by an authorized charge that won't be captured.
+	Void(ctx context.Context, chargeId string) (Charge, error)
+	Refund(ctx context.Context, chargeId string) (Charge, error)
+	// VerifyWebhook checks the signature in the header of the webhook request and returns the event the payload holds.
+	VerifyWebhook(payload []byte, header http.Header) (Event, error)
+}
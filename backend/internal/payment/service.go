@@ -0,0 +1,268 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/cativovo/bookstore/internal/order"
+)
+
+var (
+	ErrNotFound = errors.New("payment not found")
+	// ErrAlreadyExists is returned when the order already has a payment that isn't failed or refunded
+	ErrAlreadyExists     = errors.New("payment already exists")
+	ErrInvalidTransition = errors.New("invalid payment status transition")
+)
+
+type PaymentRepository interface {
+	// CreatePayment returns ErrAlreadyExists if the order has a payment that isn't failed or refunded.
+	CreatePayment(ctx context.Context, p Payment) (Payment, error)
+	// AuthorizePayment sets the charge of the pending payment and marks it as authorized.
+	AuthorizePayment(ctx context.Context, id string, providerPaymentId string) (Payment, error)
+	// UpdatePaymentStatus returns ErrInvalidTransition if the status of the payment is no longer current.
+	UpdatePaymentStatus(ctx context.Context, id string, current Status, next Status) (Payment, error)
+	// GetPaymentByOrderId returns the latest payment of the order with the status.
+	GetPaymentByOrderId(ctx context.Context, orderId string, status Status) (Payment, error)
+	GetPaymentByProviderPaymentId(ctx context.Context, providerPaymentId string) (Payment, error)
+}
+
+type PaymentService struct {
+	repository   PaymentRepository
+	provider     PaymentProvider
+	orderService *order.OrderService
+}
+
+func NewPaymentService(r PaymentRepository, p PaymentProvider, os *order.OrderService) *PaymentService {
+	return &PaymentService{
+		repository:   r,
+		provider:     p,
+		orderService: os,
+	}
+}
+
+// Pay charges the total of a pending order to the card then marks the order as paid.
+// The order is claimed by a pending payment before the card is charged so a concurrent Pay of the same order fails instead of charging it twice,
+// the charge is voided or refunded if a later step fails.
+func (ps *PaymentService) Pay(ctx context.Context, orderId string, cardNumber string) (Payment, error) {
+	o, err := ps.orderService.GetOrderById(ctx, orderId)
+	if err != nil {
+		return Payment{}, err
+	}
+
+	if !o.Status.CanTransitionTo(order.StatusPaid) {
+		return Payment{}, order.ErrInvalidTransition
+	}
+
+	p, err := ps.repository.CreatePayment(ctx, Payment{
+		OrderId:  orderId,
+		Status:   StatusPending,
+		Currency: o.Total.Currency,
+		Amount:   o.Total.Amount,
+	})
+	if err != nil {
+		// another payment of the order is in progress or has been captured
+		if errors.Is(err, ErrAlreadyExists) {
+			return Payment{}, order.ErrInvalidTransition
+		}
+
+		return Payment{}, err
+	}
+
+	charge, err := ps.provider.Authorize(ctx, AuthorizeRequest{
+		Reference:  orderId,
+		CardNumber: cardNumber,
+		Currency:   p.Currency,
+		Amount:     p.Amount,
+	})
+	if err != nil {
+		return Payment{}, ps.abort(ctx, p, Charge{}, err)
+	}
+
+	authorized, err := ps.repository.AuthorizePayment(ctx, p.Id, charge.Id)
+	if err != nil {
+		return Payment{}, ps.abort(ctx, p, charge, err)
+	}
+	p = authorized
+
+	captured, err := ps.provider.Capture(ctx, charge.Id)
+	if err != nil {
+		return Payment{}, ps.abort(ctx, p, charge, err)
+	}
+	charge = captured
+
+	p, err = ps.repository.UpdatePaymentStatus(ctx, authorized.Id, StatusAuthorized, StatusCaptured)
+	// the capture has already been applied by the webhook
+	if errors.Is(err, ErrInvalidTransition) {
+		p, err = ps.repository.GetPaymentByProviderPaymentId(ctx, charge.Id)
+	}
+	if err != nil {
+		return Payment{}, ps.abort(ctx, authorized, charge, err)
+	}
+
+	if _, err := ps.orderService.UpdateOrderStatus(ctx, orderId, order.StatusPaid); err != nil {
+		// the webhook of the charge has already marked the order as paid,
+		// the payment is the only one of the order that isn't failed or refunded so the order was paid by it
+		if errors.Is(err, order.ErrInvalidTransition) {
+			o, getErr := ps.orderService.GetOrderById(ctx, orderId)
+			if getErr == nil && o.Status == order.StatusPaid {
+				return p, nil
+			}
+		}
+
+		return Payment{}, ps.abort(ctx, p, charge, err)
+	}
+
+	return p, nil
+}
+
+// abort gives back the charge of a payment that couldn't be completed then marks the payment as failed, err is the error that stopped the payment.
+// An authorized charge is voided and a captured one is refunded, the payment keeps the order claimed if the charge can't be given back.
+func (ps *PaymentService) abort(ctx context.Context, p Payment, c Charge, err error) error {
+	status := StatusFailed
+
+	switch c.Status {
+	case StatusAuthorized:
+		if _, voidErr := ps.provider.Void(ctx, c.Id); voidErr != nil {
+			return errors.Join(err, voidErr)
+		}
+	case StatusCaptured:
+		if _, refundErr := ps.provider.Refund(ctx, c.Id); refundErr != nil {
+			return errors.Join(err, refundErr)
+		}
+		status = StatusRefunded
+	}
+
+	_, updateErr := ps.repository.UpdatePaymentStatus(ctx, p.Id, p.Status, status)
+	// the refund has already been applied by the webhook
+	if updateErr != nil && !errors.Is(updateErr, ErrInvalidTransition) {
+		return errors.Join(err, updateErr)
+	}
+
+	return err
+}
+
+// Refund gives back the captured payment of the order then marks the order as refunded.
+// The payment is claimed as refunding before the provider is called so a concurrent Refund of the same order fails instead of refunding it twice.
+func (ps *PaymentService) Refund(ctx context.Context, orderId string) (Payment, error) {
+	o, err := ps.orderService.GetOrderById(ctx, orderId)
+	if err != nil {
+		return Payment{}, err
+	}
+
+	if !o.Status.CanTransitionTo(order.StatusRefunded) {
+		return Payment{}, order.ErrInvalidTransition
+	}
+
+	p, err := ps.repository.GetPaymentByOrderId(ctx, orderId, StatusCaptured)
+	if err != nil {
+		return Payment{}, err
+	}
+
+	p, err = ps.repository.UpdatePaymentStatus(ctx, p.Id, StatusCaptured, StatusRefunding)
+	if err != nil {
+		// another refund of the payment is in progress
+		if errors.Is(err, ErrInvalidTransition) {
+			return Payment{}, order.ErrInvalidTransition
+		}
+
+		return Payment{}, err
+	}
+
+	if _, err := ps.provider.Refund(ctx, p.ProviderPaymentId); err != nil {
+		// the charge is still captured so the refund can be retried
+		if _, updateErr := ps.repository.UpdatePaymentStatus(ctx, p.Id, StatusRefunding, StatusCaptured); updateErr != nil {
+			return Payment{}, errors.Join(err, updateErr)
+		}
+
+		return Payment{}, err
+	}
+
+	refunded, err := ps.repository.UpdatePaymentStatus(ctx, p.Id, StatusRefunding, StatusRefunded)
+	// the refund has already been applied by the webhook
+	if errors.Is(err, ErrInvalidTransition) {
+		refunded, err = ps.repository.GetPaymentByProviderPaymentId(ctx, p.ProviderPaymentId)
+	}
+	if err != nil {
+		return Payment{}, err
+	}
+
+	if _, err := ps.orderService.UpdateOrderStatus(ctx, orderId, order.StatusRefunded); err != nil {
+		// the webhook of the refund has already marked the order as refunded
+		if errors.Is(err, order.ErrInvalidTransition) {
+			o, getErr := ps.orderService.GetOrderById(ctx, orderId)
+			if getErr == nil && o.Status == order.StatusRefunded {
+				return refunded, nil
+			}
+		}
+
+		return Payment{}, err
+	}
+
+	return refunded, nil
+}
+
+// HandleWebhook applies the event sent by the provider, events that are already applied are ignored.
+// An event that arrives after the payment has moved past it, such as the capture of a charge that has since been refunded, is ignored too.
+func (ps *PaymentService) HandleWebhook(ctx context.Context, payload []byte, header http.Header) error {
+	e, err := ps.provider.VerifyWebhook(payload, header)
+	if err != nil {
+		return err
+	}
+
+	var status Status
+	// the statuses of the payment the event can be applied to
+	var from []Status
+	var orderStatus order.Status
+
+	switch e.Type {
+	case EventChargeCaptured:
+		status = StatusCaptured
+		from = []Status{StatusAuthorized}
+		orderStatus = order.StatusPaid
+	case EventChargeRefunded:
+		status = StatusRefunded
+		from = []Status{StatusCaptured, StatusRefunding}
+		orderStatus = order.StatusRefunded
+	default:
+		return nil
+	}
+
+	p, err := ps.repository.GetPaymentByProviderPaymentId(ctx, e.Charge.Id)
+	if err != nil {
+		return err
+	}
+
+	if p.Status != status {
+		if !slices.Contains(from, p.Status) {
+			return nil
+		}
+
+		_, err := ps.repository.UpdatePaymentStatus(ctx, p.Id, p.Status, status)
+		// the payment has been updated by the request that made the charge, it updates the order too
+		if errors.Is(err, ErrInvalidTransition) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	o, err := ps.orderService.GetOrderById(ctx, p.OrderId)
+	if err != nil {
+		return err
+	}
+
+	if !o.Status.CanTransitionTo(orderStatus) {
+		return nil
+	}
+
+	_, err = ps.orderService.UpdateOrderStatus(ctx, p.OrderId, orderStatus)
+	// the order has been updated by the request that made the charge
+	if errors.Is(err, order.ErrInvalidTransition) {
+		return nil
+	}
+
+	return err
+}
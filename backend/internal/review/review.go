@@ -0,0 +1,26 @@
+package review
+
+import "time"
+
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+type Review struct {
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Id        string    `json:"id"`
+	BookId    string    `json:"book_id"`
+	UserId    string    `json:"user_id"`
+	// UserName is the name of the user who posted the review
+	UserName string `json:"user_name"`
+	Rating   int    `json:"rating"`
+	Body     string `json:"body"`
+}
+
+// UpdateReviewOptions holds the fields to change on a review, nil fields are left untouched.
+type UpdateReviewOptions struct {
+	Rating *int
+	Body   *string
+}
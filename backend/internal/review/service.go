@@ -0,0 +1,72 @@
+package review
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cativovo/bookstore/internal/user"
+)
+
+var (
+	ErrNotFound      = errors.New("review not found")
+	ErrAlreadyExists = errors.New("review already exists")
+)
+
+// ReviewRepository keeps the average rating and the review count of the books up to date when the reviews change,
+// it returns book.ErrNotFound when the book id does not match any book.
+type ReviewRepository interface {
+	// CreateReview returns ErrAlreadyExists if the user already reviewed the book.
+	CreateReview(ctx context.Context, r Review) (Review, error)
+	// GetReviews returns the reviews of the book, the newest first.
+	GetReviews(ctx context.Context, bookId string, limit int, offset int) (reviews []Review, count int, err error)
+	// UpdateReview and DeleteReview return ErrNotFound if the review doesn't exist or belongs to another user.
+	UpdateReview(ctx context.Context, userId string, id string, options UpdateReviewOptions) (Review, error)
+	DeleteReview(ctx context.Context, userId string, id string) error
+}
+
+type ReviewService struct {
+	repository ReviewRepository
+}
+
+func NewReviewService(r ReviewRepository) *ReviewService {
+	return &ReviewService{
+		repository: r,
+	}
+}
+
+// CreateReview posts the review of the user for the book, a user can review a book once.
+func (rs *ReviewService) CreateReview(ctx context.Context, u user.User, bookId string, rating int, body string) (Review, error) {
+	r, err := rs.repository.CreateReview(ctx, Review{
+		BookId: bookId,
+		UserId: u.Id,
+		Rating: rating,
+		Body:   body,
+	})
+	if err != nil {
+		return Review{}, err
+	}
+
+	r.UserName = u.Name
+
+	return r, nil
+}
+
+func (rs *ReviewService) GetReviews(ctx context.Context, bookId string, limit int, offset int) ([]Review, int, error) {
+	return rs.repository.GetReviews(ctx, bookId, limit, offset)
+}
+
+// UpdateReview edits a review of the user, the reviews of the other users can't be edited.
+func (rs *ReviewService) UpdateReview(ctx context.Context, u user.User, id string, options UpdateReviewOptions) (Review, error) {
+	r, err := rs.repository.UpdateReview(ctx, u.Id, id, options)
+	if err != nil {
+		return Review{}, err
+	}
+
+	r.UserName = u.Name
+
+	return r, nil
+}
+
+func (rs *ReviewService) DeleteReview(ctx context.Context, u user.User, id string) error {
+	return rs.repository.DeleteReview(ctx, u.Id, id)
+}
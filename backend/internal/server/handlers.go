@@ -4,17 +4,40 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"math"
 	"net/http"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/cativovo/bookstore/internal/book"
+	"github.com/cativovo/bookstore/internal/cart"
+	"github.com/cativovo/bookstore/internal/catalog"
+	"github.com/cativovo/bookstore/internal/change"
+	"github.com/cativovo/bookstore/internal/cover"
+	"github.com/cativovo/bookstore/internal/exchange"
+	"github.com/cativovo/bookstore/internal/inventory"
+	"github.com/cativovo/bookstore/internal/order"
+	"github.com/cativovo/bookstore/internal/payment"
+	"github.com/cativovo/bookstore/internal/review"
+	"github.com/cativovo/bookstore/internal/user"
+	"github.com/cativovo/bookstore/internal/webhook"
 	"github.com/labstack/echo/v4"
 )
 
 type handler struct {
-	bookService *book.BookService
+	bookService      *book.BookService
+	inventoryService *inventory.InventoryService
+	cartService      *cart.CartService
+	orderService     *order.OrderService
+	paymentService   *payment.PaymentService
+	exchangeService  *exchange.ExchangeService
+	changeService    *change.ChangeService
+	webhookService   *webhook.WebhookService
+	userService      *user.UserService
+	reviewService    *review.ReviewService
+	coverService     *cover.CoverService
+	catalogService   *catalog.CatalogService
+	maxPageSize      int
 }
 
 const (
@@ -22,18 +45,96 @@ const (
 	msgInvalidPayload    = "unable to parse the request"
 )
 
+var msgInvalidPriceCurrency = fmt.Sprintf("'price' should be in %s", book.DefaultCurrency)
+
+const (
+	msgInvalidAuthors    = "'authors' should credit at least one author"
+	msgIsbnAlreadyExists = "a book with the isbn already exists"
+	// the position is checked by the service when a book is put in a series without it
+	msgInvalidSeriesPosition = "'series_position' is required with 'series_id'"
+	msgCoverTooLarge         = "'cover' should be at most 5 MiB and 6 megapixels"
+)
+
 func (s *Server) registerHandlers() {
 	h := handler{
-		bookService: s.bookService,
+		bookService:      s.bookService,
+		inventoryService: s.inventoryService,
+		cartService:      s.cartService,
+		orderService:     s.orderService,
+		paymentService:   s.paymentService,
+		exchangeService:  s.exchangeService,
+		changeService:    s.changeService,
+		webhookService:   s.webhookService,
+		userService:      s.userService,
+		reviewService:    s.reviewService,
+		coverService:     s.coverService,
+		catalogService:   s.catalogService,
+		maxPageSize:      s.maxPageSize,
 	}
 
+	s.echo.Use(h.authenticate)
+
+	// the reads are public, the mutations of the catalog need a permission
+	catalogWrite := requirePermission(user.PermissionCatalogWrite)
+	catalogAdmin := requirePermission(user.PermissionCatalogAdmin)
+	// the orders have no owner, only the staff sees the orders of every customer and handles them
+	ordersManage := requirePermission(user.PermissionOrdersManage)
+
 	s.echo.GET("/health", h.healthCheck)
 	s.echo.GET("/books", h.getBooks)
+	s.echo.GET("/books/new", h.getNewBooks)
 	s.echo.GET("/book/:id", h.getBookById)
+	s.echo.GET("/book/isbn/:isbn", h.getBookByIsbn)
 	s.echo.GET("/genres", h.getGenres)
-	s.echo.POST("/genre", h.createGenre)
-	s.echo.DELETE("/genre/:name", h.deleteGenre)
-	s.echo.POST("/book", h.createBook)
+	s.echo.GET("/authors", h.getAuthors)
+	s.echo.GET("/author/:id", h.getAuthorById)
+	s.echo.GET("/series/:id", h.getSeriesById)
+	s.echo.POST("/series", h.createSeries, catalogWrite)
+	s.echo.POST("/genre", h.createGenre, catalogWrite)
+	s.echo.PATCH("/genre/:id", h.updateGenre, catalogWrite)
+	s.echo.POST("/genre/:id/merge", h.mergeGenres, catalogAdmin)
+	s.echo.DELETE("/genre/:id", h.deleteGenre, catalogAdmin)
+	s.echo.POST("/book", h.createBook, catalogWrite)
+	s.echo.POST("/books/import", h.importBooks, catalogWrite)
+	s.echo.PUT("/book/:id", h.replaceBook, catalogWrite)
+	s.echo.PATCH("/book/:id", h.updateBook, catalogWrite)
+	s.echo.DELETE("/book/:id", h.deleteBook, catalogWrite)
+	s.echo.PUT("/book/:id/prices", h.setBookPrice, catalogWrite)
+	s.echo.DELETE("/book/:id/prices/:currency", h.deleteBookPrice, catalogWrite)
+	s.echo.POST("/book/:id/cover", h.uploadCover, catalogWrite)
+	s.echo.GET("/covers/:key", h.getCover)
+	s.echo.POST("/book/:id/stock/adjust", h.adjustStock, catalogWrite)
+	s.echo.GET("/book/:id/stock/adjustments", h.getStockAdjustments)
+	s.echo.GET("/book/:id/reviews", h.getReviews)
+	s.echo.POST("/book/:id/reviews", h.createReview)
+	s.echo.PATCH("/reviews/:id", h.updateReview)
+	s.echo.DELETE("/reviews/:id", h.deleteReview)
+	s.echo.POST("/cart", h.createCart)
+	s.echo.GET("/cart/:id", h.getCartById)
+	s.echo.POST("/cart/:id/items", h.addCartItem)
+	s.echo.PATCH("/cart/:id/items/:book_id", h.updateCartItem)
+	s.echo.DELETE("/cart/:id/items/:book_id", h.removeCartItem)
+	s.echo.POST("/checkout", h.checkout)
+	s.echo.GET("/orders", h.getOrders, ordersManage)
+	s.echo.GET("/orders/:id", h.getOrderById)
+	s.echo.PATCH("/orders/:id", h.updateOrderStatus, ordersManage)
+	s.echo.POST("/orders/:id/pay", h.payOrder)
+	s.echo.POST("/orders/:id/refund", h.refundOrder, ordersManage)
+	s.echo.POST("/payments/webhook", h.paymentWebhook)
+	s.echo.GET("/exchange-rates", h.getExchangeRates)
+	s.echo.POST("/exchange-rates", h.updateExchangeRates, catalogAdmin)
+	s.echo.GET("/changes", h.getChanges)
+	s.echo.POST("/webhooks", h.createWebhook, catalogAdmin)
+	s.echo.DELETE("/webhooks/:id", h.deleteWebhook, catalogAdmin)
+	s.echo.POST("/auth/register", h.register)
+	s.echo.POST("/auth/login", h.login)
+	s.echo.POST("/auth/refresh", h.refreshToken)
+	s.echo.POST("/auth/logout", h.logout)
+	s.echo.GET("/auth/me", h.getCurrentUser)
+	s.echo.PUT("/users/:id/role", h.setUserRole, requirePermission(user.PermissionUsersAdmin))
+	s.echo.GET("/api-keys", h.getApiKeys)
+	s.echo.POST("/api-keys", h.createApiKey)
+	s.echo.DELETE("/api-keys/:id", h.deleteApiKey)
 }
 
 func (h *handler) healthCheck(ctx echo.Context) error {
@@ -41,77 +142,307 @@ func (h *handler) healthCheck(ctx echo.Context) error {
 }
 
 type getBooksQueryParam struct {
-	OrderBy string `query:"order_by"`
-	Author  string `query:"author"`
-	Genres  string `query:"genres"`
-	Title   string `query:"title"`
-	Page    int    `query:"page"`
-	Desc    bool   `query:"desc"`
+	Query         string    `query:"q"`
+	Currency      string    `query:"currency"`
+	OrderBy       string    `query:"order_by"`
+	Author        string    `query:"author"`
+	Genres        string    `query:"genres"`
+	Title         string    `query:"title"`
+	Cursor        string    `query:"cursor"`
+	Sort          string    `query:"sort"`
+	MinPrice      string    `query:"min_price"`
+	MaxPrice      string    `query:"max_price"`
+	MinRating     float64   `query:"min_rating"`
+	ExcludeGenres string    `query:"exclude_genres"`
+	GenreMatch    string    `query:"genre_match"`
+	ExactAuthor   bool      `query:"exact_author"`
+	Facets        bool      `query:"facets"`
+	UpdatedSince  time.Time `query:"updated_since"`
+	Desc          bool      `query:"desc"`
+	InStock       bool      `query:"in_stock"`
+	IncludeCount  bool      `query:"include_count"`
 }
 
 func (h *handler) getBooks(ctx echo.Context) error {
-	var queryParam getBooksQueryParam
+	queryParam := getBooksQueryParam{
+		IncludeCount: true,
+	}
 
 	err := echo.QueryParamsBinder(ctx).
-		Int("page", &queryParam.Page).
 		Bool("desc", &queryParam.Desc).
 		String("order_by", &queryParam.OrderBy).
+		String("currency", &queryParam.Currency).
+		String("q", &queryParam.Query).
 		String("author", &queryParam.Author).
 		String("genres", &queryParam.Genres).
 		String("title", &queryParam.Title).
+		Bool("in_stock", &queryParam.InStock).
+		String("cursor", &queryParam.Cursor).
+		Bool("include_count", &queryParam.IncludeCount).
+		String("sort", &queryParam.Sort).
+		String("min_price", &queryParam.MinPrice).
+		String("max_price", &queryParam.MaxPrice).
+		Float64("min_rating", &queryParam.MinRating).
+		String("exclude_genres", &queryParam.ExcludeGenres).
+		Bool("exact_author", &queryParam.ExactAuthor).
+		String("genre_match", &queryParam.GenreMatch).
+		Bool("facets", &queryParam.Facets).
+		Time("updated_since", &queryParam.UpdatedSince, time.RFC3339).
 		BindError()
 	if err != nil {
 		bindingErr := err.(*echo.BindingError)
 		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid value for '%s'", bindingErr.Field))
 	}
 
-	if queryParam.Page <= 0 {
-		queryParam.Page = 1
+	p, err := h.bindPagination(ctx)
+	if err != nil {
+		return err
+	}
+
+	var inStock *bool
+	if ctx.QueryParam("in_stock") != "" {
+		inStock = &queryParam.InStock
 	}
 
-	const limit = 10
+	switch queryParam.GenreMatch {
+	case "", book.GenreMatchAny, book.GenreMatchAll:
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("'genre_match' should be %s or %s", book.GenreMatchAll, book.GenreMatchAny))
+	}
 
-	genres := make([]string, 0)
+	var updatedSince *time.Time
+	if !queryParam.UpdatedSince.IsZero() {
+		updatedSince = &queryParam.UpdatedSince
+	}
 
-	if queryParam.Genres != "" {
-		for _, genre := range strings.Split(queryParam.Genres, ",") {
-			genres = append(genres, strings.TrimSpace(genre))
+	var minRating *float64
+	if ctx.QueryParam("min_rating") != "" {
+		if queryParam.MinRating < review.MinRating || queryParam.MinRating > review.MaxRating {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("'min_rating' should be between %d and %d", review.MinRating, review.MaxRating))
 		}
+
+		minRating = &queryParam.MinRating
 	}
 
-	books, count, err := h.bookService.GetBooks(
+	var sort []book.SortKey
+	if queryParam.Sort != "" {
+		sort, err = book.ParseSort(queryParam.Sort)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	}
+
+	currency := strings.ToUpper(queryParam.Currency)
+
+	// the price range is in the requested currency
+	priceCurrency := currency
+	if priceCurrency == "" {
+		priceCurrency = book.DefaultCurrency
+	}
+
+	minPrice, err := parseMoneyQueryParam(queryParam.MinPrice, priceCurrency)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid value for 'min_price'")
+	}
+
+	maxPrice, err := parseMoneyQueryParam(queryParam.MaxPrice, priceCurrency)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid value for 'max_price'")
+	}
+
+	page, err := h.bookService.GetBooks(
 		ctx.Request().Context(),
 		book.GetBooksOptions{
-			Limit:   limit,
-			Offset:  (queryParam.Page - 1) * limit,
-			OrderBy: queryParam.OrderBy,
-			Desc:    queryParam.Desc,
+			Limit:     p.limit(),
+			Offset:    p.offset(),
+			Cursor:    queryParam.Cursor,
+			SkipCount: !queryParam.IncludeCount,
+			Facets:    queryParam.Facets,
+			OrderBy:   queryParam.OrderBy,
+			Sort:      sort,
+			Desc:      queryParam.Desc,
+			Currency:  currency,
 			Filter: book.GetBooksFilter{
-				Author: queryParam.Author,
-				Title:  queryParam.Title,
-				Genres: genres,
+				Query:         strings.TrimSpace(queryParam.Query),
+				Author:        queryParam.Author,
+				ExactAuthor:   queryParam.ExactAuthor,
+				Title:         queryParam.Title,
+				Genres:        splitQueryParam(queryParam.Genres),
+				GenreMatch:    queryParam.GenreMatch,
+				ExcludeGenres: splitQueryParam(queryParam.ExcludeGenres),
+				InStock:       inStock,
+				MinPrice:      minPrice,
+				MaxPrice:      maxPrice,
+				UpdatedSince:  updatedSince,
+				MinRating:     minRating,
 			},
 		},
 	)
 	if err != nil {
+		if errors.Is(err, book.ErrInvalidCurrency) {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("unsupported currency '%s'", queryParam.Currency))
+		}
+		if errors.Is(err, book.ErrInvalidCursor) {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid value for 'cursor'")
+		}
+		if errors.Is(err, book.ErrInvalidSort) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+
 		ctx.Logger().Error(err)
 		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
 	}
-	pages := math.Ceil(float64(count) / limit)
 
-	return ctx.JSON(http.StatusOK, map[string]any{
-		"books": books,
-		"pages": pages,
-	})
+	total := page.Count
+	if !queryParam.IncludeCount {
+		total = -1
+	}
+
+	response := paginate(ctx, "books", page.Books, p, total)
+
+	// the repository knows if there is a book after this page, a full page isn't enough to tell
+	if !queryParam.IncludeCount && page.NextCursor == "" {
+		response["next"] = nil
+	}
+
+	if page.Facets != nil {
+		response["facets"] = page.Facets
+	}
+
+	// next_cursor continues after the last book of this page, it is more stable than the next page when books are added
+	if page.NextCursor != "" {
+		response["next_cursor"] = page.NextCursor
+	}
+
+	// a page of a cursor has no number, the links continue after the cursor
+	if queryParam.Cursor != "" {
+		delete(response, "page")
+		response["prev"] = nil
+		response["next"] = nil
+
+		if page.NextCursor != "" {
+			response["next"] = pageLink(ctx, "cursor", page.NextCursor)
+		}
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+const (
+	defaultNewBooksDays = 30
+	maxNewBooksDays     = 365
+)
+
+// getNewBooks returns the books added in the last days, the newest first
+func (h *handler) getNewBooks(ctx echo.Context) error {
+	days := defaultNewBooksDays
+	var currency string
+
+	err := echo.QueryParamsBinder(ctx).
+		Int("days", &days).
+		String("currency", &currency).
+		BindError()
+	if err != nil {
+		bindingErr := err.(*echo.BindingError)
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid value for '%s'", bindingErr.Field))
+	}
+
+	if days < 1 || days > maxNewBooksDays {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("'days' should be between 1 and %d", maxNewBooksDays))
+	}
+
+	p, err := h.bindPagination(ctx)
+	if err != nil {
+		return err
+	}
+
+	createdSince := time.Now().AddDate(0, 0, -days)
+
+	page, err := h.bookService.GetBooks(
+		ctx.Request().Context(),
+		book.GetBooksOptions{
+			Limit:    p.limit(),
+			Offset:   p.offset(),
+			Currency: strings.ToUpper(currency),
+			Sort:     []book.SortKey{{Field: book.SortByCreatedAt, Desc: true}},
+			Filter: book.GetBooksFilter{
+				CreatedSince: &createdSince,
+			},
+		},
+	)
+	if err != nil {
+		if errors.Is(err, book.ErrInvalidCurrency) {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("unsupported currency '%s'", currency))
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.JSON(http.StatusOK, paginate(ctx, "books", page.Books, p, page.Count))
+}
+
+// splitQueryParam splits a comma separated query param, e.g. "fantasy, horror", a query param that is not set is an empty list
+func splitQueryParam(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+
+	values := strings.Split(s, ",")
+	for i, v := range values {
+		values[i] = strings.TrimSpace(v)
+	}
+
+	return values
+}
+
+// parseMoneyQueryParam parses an amount in the currency, it returns nil if the query param is not set
+func parseMoneyQueryParam(s string, currency string) (*book.Money, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	m, err := book.ParseMoney(s, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	return &m, nil
 }
 
 func (h *handler) getBookById(ctx echo.Context) error {
 	id := ctx.Param("id")
-	b, err := h.bookService.GetBookById(ctx.Request().Context(), id)
+	currency := ctx.QueryParam("currency")
+	b, err := h.bookService.GetBookById(ctx.Request().Context(), id, strings.ToUpper(currency))
+	if err != nil {
+		if errors.Is(err, book.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "book not found")
+		}
+		if errors.Is(err, book.ErrInvalidCurrency) {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("unsupported currency '%s'", currency))
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.JSON(http.StatusOK, b)
+}
+
+// getBookByIsbn accepts an ISBN-10 or an ISBN-13, with or without hyphens
+func (h *handler) getBookByIsbn(ctx echo.Context) error {
+	currency := ctx.QueryParam("currency")
+	b, err := h.bookService.GetBookByIsbn(ctx.Request().Context(), ctx.Param("isbn"), strings.ToUpper(currency))
 	if err != nil {
+		if errors.Is(err, book.ErrInvalidISBN) {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid isbn")
+		}
 		if errors.Is(err, book.ErrNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "book not found")
 		}
+		if errors.Is(err, book.ErrInvalidCurrency) {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("unsupported currency '%s'", currency))
+		}
 
 		ctx.Logger().Error(err)
 		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
@@ -157,9 +488,72 @@ func (h *handler) createGenre(ctx echo.Context) error {
 	return ctx.NoContent(http.StatusCreated)
 }
 
+type payloadUpdateGenre struct {
+	Name string `json:"name" validate:"required"`
+}
+
+func (h *handler) updateGenre(ctx echo.Context) error {
+	var payload payloadUpdateGenre
+	if err := ctx.Bind(&payload); err != nil {
+		return getBindErr(err)
+	}
+
+	if err := ctx.Validate(&payload); err != nil {
+		return err
+	}
+
+	genre, err := h.bookService.UpdateGenre(ctx.Request().Context(), ctx.Param("id"), payload.Name)
+	if err != nil {
+		if errors.Is(err, book.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "genre not found")
+		}
+		if errors.Is(err, book.ErrAlreadyExists) {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("genre '%s' already exists", payload.Name))
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.JSON(http.StatusOK, genre)
+}
+
+type payloadMergeGenres struct {
+	TargetId string `json:"target_id" validate:"required"`
+}
+
+func (h *handler) mergeGenres(ctx echo.Context) error {
+	var payload payloadMergeGenres
+	if err := ctx.Bind(&payload); err != nil {
+		return getBindErr(err)
+	}
+
+	if err := ctx.Validate(&payload); err != nil {
+		return err
+	}
+
+	genre, err := h.bookService.MergeGenres(ctx.Request().Context(), ctx.Param("id"), payload.TargetId)
+	if err != nil {
+		if errors.Is(err, book.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "genre not found")
+		}
+		if errors.Is(err, book.ErrInvalidGenre) {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid target genre")
+		}
+		if errors.Is(err, book.ErrMergeIntoItself) {
+			return echo.NewHTTPError(http.StatusBadRequest, "cannot merge a genre into itself")
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.JSON(http.StatusOK, genre)
+}
+
 func (h *handler) deleteGenre(ctx echo.Context) error {
-	name := ctx.Param("name")
-	if err := h.bookService.DeleteGenre(ctx.Request().Context(), name); err != nil {
+	id := ctx.Param("id")
+	if err := h.bookService.DeleteGenre(ctx.Request().Context(), id); err != nil {
 		if errors.Is(err, book.ErrNotFound) {
 			return echo.NewHTTPError(http.StatusNotFound, "genre not found")
 		}
@@ -171,14 +565,50 @@ func (h *handler) deleteGenre(ctx echo.Context) error {
 	return ctx.NoContent(http.StatusNoContent)
 }
 
+// payloadBookAuthor credits an author by name, the role is author if it is empty
+type payloadBookAuthor struct {
+	Name string          `json:"name" validate:"required"`
+	Role book.AuthorRole `json:"role" validate:"omitempty,oneof=author translator illustrator editor"`
+}
+
+// bookSeries returns the series of the id, nil if the id is empty
+func bookSeries(id string) *book.BookSeries {
+	if id == "" {
+		return nil
+	}
+
+	return &book.BookSeries{Id: id}
+}
+
+func toBookAuthors(payload []payloadBookAuthor) []book.BookAuthor {
+	authors := make([]book.BookAuthor, len(payload))
+
+	for i, v := range payload {
+		authors[i] = book.BookAuthor{Name: v.Name, Role: v.Role}
+	}
+
+	return authors
+}
+
+// a book is credited with either the author or the authors, the isbn can be an ISBN-10 or an ISBN-13
 type payloadCreateBook struct {
 	// https://github.com/go-playground/validator/issues/692#issuecomment-737039536
-	Price       *float64 `json:"price" validate:"required,gt=0"`
-	Title       string   `json:"title" validate:"required"`
-	Author      string   `json:"author" validate:"required"`
-	Description string   `json:"description"`
-	CoverImage  string   `json:"cover_image"`
-	Genres      []string `json:"genres" validate:"required"`
+	Price           *book.Money         `json:"price" validate:"required,gt=0"`
+	Title           string              `json:"title" validate:"required"`
+	Author          string              `json:"author" validate:"required_without=Authors"`
+	Authors         []payloadBookAuthor `json:"authors" validate:"excluded_with=Author,dive"`
+	Description     string              `json:"description"`
+	CoverImage      string              `json:"cover_image"`
+	Genres          []string            `json:"genres" validate:"required"`
+	Isbn            string              `json:"isbn" validate:"omitempty,isbn"`
+	Publisher       string              `json:"publisher" validate:"max=255"`
+	PublicationDate *book.Date          `json:"publication_date"`
+	Edition         string              `json:"edition" validate:"max=64"`
+	PageCount       *int                `json:"page_count" validate:"omitempty,gt=0"`
+	Language        string              `json:"language" validate:"omitempty,bcp47_language_tag"`
+	Format          book.Format         `json:"format" validate:"omitempty,oneof=hardcover paperback ebook audiobook"`
+	SeriesId        string              `json:"series_id" validate:"required_with=SeriesPosition"`
+	SeriesPosition  *float64            `json:"series_position" validate:"required_with=SeriesId,omitempty,series_position"`
 }
 
 func (h *handler) createBook(ctx echo.Context) error {
@@ -191,18 +621,48 @@ func (h *handler) createBook(ctx echo.Context) error {
 		return err
 	}
 
+	var authors []book.BookAuthor
+	if len(payload.Authors) > 0 {
+		authors = toBookAuthors(payload.Authors)
+	}
+
 	b, err := h.bookService.CreateBook(ctx.Request().Context(), book.Book{
-		Title:       payload.Title,
-		Author:      payload.Author,
-		Description: payload.Description,
-		CoverImage:  payload.CoverImage,
-		Price:       *payload.Price,
-		Genres:      payload.Genres,
+		Title:           payload.Title,
+		Author:          payload.Author,
+		Authors:         authors,
+		Description:     payload.Description,
+		CoverImage:      book.CoverImage(payload.CoverImage),
+		Price:           *payload.Price,
+		Genres:          payload.Genres,
+		Isbn13:          payload.Isbn,
+		Publisher:       payload.Publisher,
+		PublicationDate: payload.PublicationDate,
+		Edition:         payload.Edition,
+		PageCount:       payload.PageCount,
+		Language:        payload.Language,
+		Format:          payload.Format,
+		Series:          bookSeries(payload.SeriesId),
+		SeriesPosition:  payload.SeriesPosition,
 	})
 	if err != nil {
-		if errors.Is(err, book.ErrNotFound) {
+		if errors.Is(err, book.ErrInvalidGenre) {
 			return echo.NewHTTPError(http.StatusBadRequest, "invalid genre")
 		}
+		if errors.Is(err, book.ErrAlreadyExists) {
+			return echo.NewHTTPError(http.StatusConflict, msgIsbnAlreadyExists)
+		}
+		if errors.Is(err, book.ErrSeriesNotFound) {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid series")
+		}
+		if errors.Is(err, book.ErrInvalidSeriesPosition) {
+			return echo.NewHTTPError(http.StatusBadRequest, msgInvalidSeriesPosition)
+		}
+		if errors.Is(err, book.ErrInvalidAuthor) {
+			return echo.NewHTTPError(http.StatusBadRequest, msgInvalidAuthors)
+		}
+		if errors.Is(err, book.ErrInvalidCurrency) {
+			return echo.NewHTTPError(http.StatusBadRequest, msgInvalidPriceCurrency)
+		}
 		ctx.Logger().Error(err)
 		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
 	}
@@ -210,6 +670,155 @@ func (h *handler) createBook(ctx echo.Context) error {
 	return ctx.JSON(http.StatusCreated, b)
 }
 
+func (h *handler) replaceBook(ctx echo.Context) error {
+	var payload payloadCreateBook
+	if err := ctx.Bind(&payload); err != nil {
+		return getBindErr(err)
+	}
+
+	if err := ctx.Validate(&payload); err != nil {
+		return err
+	}
+
+	options := book.UpdateBookOptions{
+		Title:           &payload.Title,
+		Author:          &payload.Author,
+		Description:     &payload.Description,
+		CoverImage:      &payload.CoverImage,
+		Price:           payload.Price,
+		Genres:          &payload.Genres,
+		Isbn:            &payload.Isbn,
+		Publisher:       &payload.Publisher,
+		PublicationDate: payload.PublicationDate,
+		Edition:         &payload.Edition,
+		PageCount:       payload.PageCount,
+		Language:        &payload.Language,
+		Format:          &payload.Format,
+		SeriesId:        &payload.SeriesId,
+		SeriesPosition:  payload.SeriesPosition,
+	}
+
+	// the fields left out are cleared like the empty strings
+	if options.PublicationDate == nil {
+		options.PublicationDate = &book.Date{}
+	}
+
+	if options.PageCount == nil {
+		pageCount := 0
+		options.PageCount = &pageCount
+	}
+
+	if len(payload.Authors) > 0 {
+		authors := toBookAuthors(payload.Authors)
+		options.Author = nil
+		options.Authors = &authors
+	}
+
+	return h.saveBook(ctx, options)
+}
+
+type payloadUpdateBook struct {
+	Price       *book.Money          `json:"price" validate:"omitempty,gt=0"`
+	Title       *string              `json:"title" validate:"omitempty,min=1"`
+	Author      *string              `json:"author" validate:"omitempty,min=1"`
+	Authors     *[]payloadBookAuthor `json:"authors" validate:"excluded_with=Author,omitempty,min=1,dive"`
+	Description *string              `json:"description"`
+	CoverImage  *string              `json:"cover_image"`
+	Genres      *[]string            `json:"genres"`
+	// an empty string clears the isbn, publisher, publication_date or edition, a page_count of 0 clears it
+	Isbn            *string      `json:"isbn" validate:"omitempty,isbn"`
+	Publisher       *string      `json:"publisher" validate:"omitempty,max=255"`
+	PublicationDate *book.Date   `json:"publication_date"`
+	Edition         *string      `json:"edition" validate:"omitempty,max=64"`
+	PageCount       *int         `json:"page_count" validate:"omitempty,gte=0"`
+	Language        *string      `json:"language" validate:"omitempty,bcp47_language_tag"`
+	Format          *book.Format `json:"format" validate:"omitempty,oneof=hardcover paperback ebook audiobook"`
+	// an empty series_id removes the book from its series, the position is then not needed
+	SeriesId       *string  `json:"series_id" validate:"required_with=SeriesPosition"`
+	SeriesPosition *float64 `json:"series_position" validate:"omitempty,series_position"`
+}
+
+func (h *handler) updateBook(ctx echo.Context) error {
+	var payload payloadUpdateBook
+	if err := ctx.Bind(&payload); err != nil {
+		return getBindErr(err)
+	}
+
+	if err := ctx.Validate(&payload); err != nil {
+		return err
+	}
+
+	options := book.UpdateBookOptions{
+		Title:           payload.Title,
+		Author:          payload.Author,
+		Description:     payload.Description,
+		CoverImage:      payload.CoverImage,
+		Price:           payload.Price,
+		Genres:          payload.Genres,
+		Isbn:            payload.Isbn,
+		Publisher:       payload.Publisher,
+		PublicationDate: payload.PublicationDate,
+		Edition:         payload.Edition,
+		PageCount:       payload.PageCount,
+		Language:        payload.Language,
+		Format:          payload.Format,
+		SeriesId:        payload.SeriesId,
+		SeriesPosition:  payload.SeriesPosition,
+	}
+
+	if payload.Authors != nil {
+		authors := toBookAuthors(*payload.Authors)
+		options.Authors = &authors
+	}
+
+	return h.saveBook(ctx, options)
+}
+
+func (h *handler) saveBook(ctx echo.Context, options book.UpdateBookOptions) error {
+	b, err := h.bookService.UpdateBook(ctx.Request().Context(), ctx.Param("id"), options)
+	if err != nil {
+		if errors.Is(err, book.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "book not found")
+		}
+		if errors.Is(err, book.ErrInvalidGenre) {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid genre")
+		}
+		if errors.Is(err, book.ErrAlreadyExists) {
+			return echo.NewHTTPError(http.StatusConflict, msgIsbnAlreadyExists)
+		}
+		if errors.Is(err, book.ErrSeriesNotFound) {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid series")
+		}
+		if errors.Is(err, book.ErrInvalidSeriesPosition) {
+			return echo.NewHTTPError(http.StatusBadRequest, msgInvalidSeriesPosition)
+		}
+		if errors.Is(err, book.ErrInvalidAuthor) {
+			return echo.NewHTTPError(http.StatusBadRequest, msgInvalidAuthors)
+		}
+		if errors.Is(err, book.ErrInvalidCurrency) {
+			return echo.NewHTTPError(http.StatusBadRequest, msgInvalidPriceCurrency)
+		}
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.JSON(http.StatusOK, b)
+}
+
+func (h *handler) deleteBook(ctx echo.Context) error {
+	id := ctx.Param("id")
+	if err := h.bookService.DeleteBook(ctx.Request().Context(), id); err != nil {
+		if errors.Is(err, book.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "book not found")
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
 func getBindErr(err error) *echo.HTTPError {
 	defaultStatusCode := http.StatusBadRequest
 
@@ -217,6 +826,9 @@ func getBindErr(err error) *echo.HTTPError {
 		if ute, ok := httpErr.Internal.(*json.UnmarshalTypeError); ok && ute.Type.Kind() != reflect.Struct {
 			return echo.NewHTTPError(defaultStatusCode, fmt.Sprintf("'%s' should be %s", ute.Field, ute.Type))
 		}
+		if errors.Is(httpErr.Internal, book.ErrInvalidMoney) || errors.Is(httpErr.Internal, book.ErrInvalidCurrency) || errors.Is(httpErr.Internal, book.ErrInvalidDate) {
+			return echo.NewHTTPError(defaultStatusCode, httpErr.Internal.Error())
+		}
 	}
 
 	return echo.NewHTTPError(defaultStatusCode, msgInvalidPayload)
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cativovo/bookstore/internal/user"
+	"github.com/labstack/echo/v4"
+)
+
+// keyOwner returns the user managing its api keys, an api key can't be used to manage the api keys
+func keyOwner(ctx echo.Context) (user.User, error) {
+	u, ok := user.FromContext(ctx.Request().Context())
+	if !ok {
+		return user.User{}, unauthorized(ctx, "authentication required")
+	}
+
+	if u.Scopes != nil {
+		return user.User{}, echo.NewHTTPError(http.StatusForbidden, "api keys can't be managed with an api key")
+	}
+
+	return u, nil
+}
+
+type payloadCreateApiKey struct {
+	Name   string            `json:"name" validate:"required"`
+	Scopes []user.Permission `json:"scopes" validate:"required,min=1,unique,dive,oneof=catalog:write catalog:admin orders:manage users:admin"`
+}
+
+func (h *handler) createApiKey(ctx echo.Context) error {
+	u, err := keyOwner(ctx)
+	if err != nil {
+		return err
+	}
+
+	var payload payloadCreateApiKey
+	if err := ctx.Bind(&payload); err != nil {
+		return getBindErr(err)
+	}
+
+	if err := ctx.Validate(&payload); err != nil {
+		return err
+	}
+
+	k, err := h.userService.CreateApiKey(ctx.Request().Context(), u, payload.Name, payload.Scopes)
+	if err != nil {
+		if errors.Is(err, user.ErrScopeNotGranted) {
+			return echo.NewHTTPError(http.StatusForbidden, "'scopes' should be granted by your role")
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.JSON(http.StatusCreated, k)
+}
+
+func (h *handler) getApiKeys(ctx echo.Context) error {
+	u, err := keyOwner(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys, err := h.userService.GetApiKeys(ctx.Request().Context(), u.Id)
+	if err != nil {
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"api_keys": keys,
+	})
+}
+
+func (h *handler) deleteApiKey(ctx echo.Context) error {
+	u, err := keyOwner(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := h.userService.RevokeApiKey(ctx.Request().Context(), u.Id, ctx.Param("id")); err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "api key not found")
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,149 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cativovo/bookstore/internal/user"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateApiKey(t *testing.T) {
+	staff := testUser
+	staff.Role = user.RoleStaff
+
+	createdAt := time.Date(2024, time.May, 6, 14, 2, 12, 0, time.UTC)
+
+	tests := []struct {
+		name               string
+		user               *user.User
+		payload            string
+		expectedServiceArg []user.Permission
+		expectedOutput     any
+	}{
+		{
+			name:               "Success",
+			user:               &staff,
+			payload:            `{"name":"nightly import","scopes":["catalog:write"]}`,
+			expectedServiceArg: []user.Permission{user.PermissionCatalogWrite},
+		},
+		{
+			name:           "Anonymous",
+			payload:        `{"name":"nightly import","scopes":["catalog:write"]}`,
+			expectedOutput: echo.NewHTTPError(http.StatusUnauthorized, "authentication required"),
+		},
+		{
+			name:           "With an api key",
+			user:           &testApiKeyUser,
+			payload:        `{"name":"nightly import","scopes":["catalog:write"]}`,
+			expectedOutput: echo.NewHTTPError(http.StatusForbidden, "api keys can't be managed with an api key"),
+		},
+		{
+			name:           "Scope not granted by the role",
+			user:           &staff,
+			payload:        `{"name":"nightly import","scopes":["catalog:write","catalog:admin"]}`,
+			expectedOutput: echo.NewHTTPError(http.StatusForbidden, "'scopes' should be granted by your role"),
+		},
+		{
+			name:           "Unknown scope",
+			user:           &staff,
+			payload:        `{"name":"nightly import","scopes":["catalog:read"]}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'scopes[0]' should be one of [catalog:write, catalog:admin, orders:manage, users:admin]"),
+		},
+		{
+			name:           "No scopes",
+			user:           &staff,
+			payload:        `{"name":"nightly import"}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'scopes' is required"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodPost, "/api-keys", strings.NewReader(test.payload))
+			if test.user != nil {
+				ctx.SetRequest(ctx.Request().WithContext(user.NewContext(ctx.Request().Context(), *test.user)))
+			}
+
+			mockRepository := new(MockUserRepository)
+			// the key is random, only its prefix is stored in clear
+			matchKey := mock.MatchedBy(func(k user.ApiKey) bool {
+				return k.Name == "nightly import" && strings.HasPrefix(k.Prefix, "bk_") && len(k.Prefix) == 11 && k.Key == ""
+			})
+			call := mockRepository.On("CreateApiKey", ctx.Request().Context(), testUser.Id, matchKey, mock.AnythingOfType("string"))
+			call.Run(func(args mock.Arguments) {
+				k := args.Get(2).(user.ApiKey)
+				k.Id = "1234"
+				k.CreatedAt = createdAt
+				call.Return(k, nil)
+			})
+			h := handler{userService: user.NewUserService(mockRepository, testJwtSecret)}
+
+			err := h.createApiKey(ctx)
+
+			if err != nil {
+				assert.Equal(t, test.expectedOutput, err)
+				mockRepository.AssertNotCalled(t, "CreateApiKey", ctx.Request().Context(), mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+
+			var k user.ApiKey
+			if err := json.Unmarshal(rec.Body.Bytes(), &k); err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, http.StatusCreated, rec.Code)
+			assert.Equal(t, test.expectedServiceArg, k.Scopes)
+			assert.True(t, strings.HasPrefix(k.Key, k.Prefix+"_"))
+			assert.Equal(t, "1234", k.Id)
+			mockRepository.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeleteApiKey(t *testing.T) {
+	tests := []struct {
+		name               string
+		serviceReturn      error
+		expectedOutput     any
+		expectedStatusCode int
+	}{
+		{
+			name:               "Success",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name:           "Key of another user",
+			serviceReturn:  user.ErrNotFound,
+			expectedOutput: echo.NewHTTPError(http.StatusNotFound, "api key not found"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodDelete, "/api-keys/:id", nil)
+			ctx.SetRequest(ctx.Request().WithContext(user.NewContext(ctx.Request().Context(), testUser)))
+
+			mockRepository := new(MockUserRepository)
+			mockRepository.On("RevokeApiKey", ctx.Request().Context(), testUser.Id, "1234").Return(test.serviceReturn)
+			h := handler{userService: user.NewUserService(mockRepository, testJwtSecret)}
+
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("1234")
+			err := h.deleteApiKey(ctx)
+
+			if err != nil {
+				assert.Equal(t, test.expectedOutput, err)
+				return
+			}
+
+			assert.Equal(t, test.expectedStatusCode, rec.Code)
+			mockRepository.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cativovo/bookstore/internal/book"
+	"github.com/labstack/echo/v4"
+)
+
+// getAuthors returns the authors by name, q matches a part of the name
+func (h *handler) getAuthors(ctx echo.Context) error {
+	p, err := h.bindPagination(ctx)
+	if err != nil {
+		return err
+	}
+
+	authors, count, err := h.bookService.GetAuthors(ctx.Request().Context(), strings.TrimSpace(ctx.QueryParam("q")), p.limit(), p.offset())
+	if err != nil {
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.JSON(http.StatusOK, paginate(ctx, "authors", authors, p, count))
+}
+
+// getAuthorById returns the author with a page of the books crediting the author in any role
+func (h *handler) getAuthorById(ctx echo.Context) error {
+	currency := ctx.QueryParam("currency")
+
+	p, err := h.bindPagination(ctx)
+	if err != nil {
+		return err
+	}
+
+	a, err := h.bookService.GetAuthorById(ctx.Request().Context(), ctx.Param("id"))
+	if err != nil {
+		if errors.Is(err, book.ErrAuthorNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "author not found")
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	page, err := h.bookService.GetBooks(
+		ctx.Request().Context(),
+		book.GetBooksOptions{
+			Limit:    p.limit(),
+			Offset:   p.offset(),
+			Currency: strings.ToUpper(currency),
+			Filter: book.GetBooksFilter{
+				AuthorId: a.Id,
+			},
+		},
+	)
+	if err != nil {
+		if errors.Is(err, book.ErrInvalidCurrency) {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("unsupported currency '%s'", currency))
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	response := paginate(ctx, "books", page.Books, p, page.Count)
+	response["author"] = a
+
+	return ctx.JSON(http.StatusOK, response)
+}
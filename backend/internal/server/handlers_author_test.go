@@ -0,0 +1,174 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cativovo/bookstore/internal/book"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+var testAuthor = book.Author{
+	CreatedAt: time.Date(2024, 5, 10, 9, 35, 12, 0, time.UTC),
+	Id:        "9876",
+	Name:      "Albert Camus",
+	Bio:       "French philosopher and novelist",
+	BookCount: 1,
+}
+
+func TestGetAuthors(t *testing.T) {
+	authors := []book.Author{testAuthor}
+
+	tests := []struct {
+		expectedOutput     any
+		name               string
+		query              string
+		expectedName       string
+		serviceReturn      []any
+		expectedStatusCode int
+	}{
+		{
+			name:          "Success",
+			serviceReturn: []any{authors, 1, nil},
+			expectedOutput: newTestJson(t, map[string]any{
+				"authors":   authors,
+				"total":     1,
+				"page":      1,
+				"page_size": 10,
+				"pages":     1,
+				"next":      nil,
+				"prev":      nil,
+			}),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:          "Success search",
+			query:         "?q=%20camus%20",
+			expectedName:  "camus",
+			serviceReturn: []any{authors, 11, nil},
+			expectedOutput: newTestJson(t, map[string]any{
+				"authors":   authors,
+				"total":     11,
+				"page":      1,
+				"page_size": 10,
+				"pages":     2,
+				"next":      "/authors?page=2&q=+camus+",
+				"prev":      nil,
+			}),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Internal server error",
+			serviceReturn:  []any{[]book.Author(nil), 0, errors.New("internal server error")},
+			expectedOutput: echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodGet, "/authors"+test.query, nil)
+
+			mockRepository := new(MockBookRepository)
+			mockRepository.On("GetAuthors", ctx.Request().Context(), test.expectedName, 10, 0).Return(test.serviceReturn...)
+			h := handler{bookService: book.NewBookService(mockRepository)}
+
+			err := h.getAuthors(ctx)
+
+			if err != nil {
+				assert.Equal(t, test.expectedOutput, err)
+				return
+			}
+
+			assert.Equal(t, test.expectedStatusCode, rec.Code)
+			assert.Equal(t, test.expectedOutput, strings.TrimSpace(rec.Body.String()))
+			mockRepository.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetAuthorById(t *testing.T) {
+	books := []book.Book{
+		{
+			Id:      "1234",
+			Title:   "The Stranger",
+			Author:  "Albert Camus",
+			Authors: []book.BookAuthor{{Id: testAuthor.Id, Name: testAuthor.Name, Role: book.AuthorRoleAuthor}},
+			Genres:  []string{"Fiction"},
+			Price:   book.NewMoney(1299, book.DefaultCurrency),
+		},
+	}
+
+	tests := []struct {
+		expectedOutput     any
+		name               string
+		authorReturn       []any
+		booksCalled        bool
+		booksReturn        []any
+		expectedStatusCode int
+	}{
+		{
+			name:         "Success",
+			authorReturn: []any{testAuthor, nil},
+			booksCalled:  true,
+			booksReturn:  []any{book.BooksPage{Books: books, Count: 1}, nil},
+			expectedOutput: newTestJson(t, map[string]any{
+				"author":    testAuthor,
+				"books":     books,
+				"total":     1,
+				"page":      1,
+				"page_size": 10,
+				"pages":     1,
+				"next":      nil,
+				"prev":      nil,
+			}),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Not found",
+			authorReturn:   []any{book.Author{}, book.ErrAuthorNotFound},
+			expectedOutput: echo.NewHTTPError(http.StatusNotFound, "author not found"),
+		},
+		{
+			name:           "Internal server error",
+			authorReturn:   []any{testAuthor, nil},
+			booksCalled:    true,
+			booksReturn:    []any{book.BooksPage{}, errors.New("internal server error")},
+			expectedOutput: echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodGet, "/author/9876", nil)
+
+			mockRepository := new(MockBookRepository)
+			mockRepository.On("GetAuthorById", ctx.Request().Context(), "9876").Return(test.authorReturn...)
+			if test.booksCalled {
+				mockRepository.On("GetBooks", ctx.Request().Context(), book.GetBooksOptions{
+					Currency: book.DefaultCurrency,
+					Limit:    10,
+					Filter:   book.GetBooksFilter{AuthorId: testAuthor.Id},
+				}).Return(test.booksReturn...)
+			}
+			h := handler{bookService: book.NewBookService(mockRepository)}
+
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("9876")
+			err := h.getAuthorById(ctx)
+
+			mockRepository.AssertExpectations(t)
+
+			if err != nil {
+				assert.Equal(t, test.expectedOutput, err)
+				return
+			}
+
+			assert.Equal(t, test.expectedStatusCode, rec.Code)
+			assert.Equal(t, test.expectedOutput, strings.TrimSpace(rec.Body.String()))
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cativovo/bookstore/internal/book"
+	"github.com/cativovo/bookstore/internal/cart"
+	"github.com/labstack/echo/v4"
+)
+
+func (h *handler) createCart(ctx echo.Context) error {
+	c, err := h.cartService.CreateCart(ctx.Request().Context())
+	if err != nil {
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.JSON(http.StatusCreated, c)
+}
+
+func (h *handler) getCartById(ctx echo.Context) error {
+	c, err := h.cartService.GetCartById(ctx.Request().Context(), ctx.Param("id"))
+	if err != nil {
+		return getCartErr(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusOK, c)
+}
+
+type payloadAddCartItem struct {
+	BookId   string `json:"book_id" validate:"required"`
+	Quantity int    `json:"quantity" validate:"required,gt=0"`
+}
+
+func (h *handler) addCartItem(ctx echo.Context) error {
+	var payload payloadAddCartItem
+	if err := ctx.Bind(&payload); err != nil {
+		return getBindErr(err)
+	}
+
+	if err := ctx.Validate(&payload); err != nil {
+		return err
+	}
+
+	c, err := h.cartService.AddCartItem(ctx.Request().Context(), ctx.Param("id"), payload.BookId, payload.Quantity)
+	if err != nil {
+		if errors.Is(err, book.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid book")
+		}
+
+		return getCartErr(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusOK, c)
+}
+
+type payloadUpdateCartItem struct {
+	Quantity int `json:"quantity" validate:"required,gt=0"`
+}
+
+func (h *handler) updateCartItem(ctx echo.Context) error {
+	var payload payloadUpdateCartItem
+	if err := ctx.Bind(&payload); err != nil {
+		return getBindErr(err)
+	}
+
+	if err := ctx.Validate(&payload); err != nil {
+		return err
+	}
+
+	c, err := h.cartService.UpdateCartItem(ctx.Request().Context(), ctx.Param("id"), ctx.Param("book_id"), payload.Quantity)
+	if err != nil {
+		return getCartErr(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusOK, c)
+}
+
+func (h *handler) removeCartItem(ctx echo.Context) error {
+	c, err := h.cartService.RemoveCartItem(ctx.Request().Context(), ctx.Param("id"), ctx.Param("book_id"))
+	if err != nil {
+		return getCartErr(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusOK, c)
+}
+
+func getCartErr(ctx echo.Context, err error) *echo.HTTPError {
+	if errors.Is(err, cart.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "cart not found")
+	}
+	if errors.Is(err, cart.ErrItemNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, "item not found")
+	}
+
+	ctx.Logger().Error(err)
+	return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+}
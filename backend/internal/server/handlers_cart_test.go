@@ -0,0 +1,298 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cativovo/bookstore/internal/book"
+	"github.com/cativovo/bookstore/internal/cart"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCartRepository struct {
+	mock.Mock
+}
+
+func (m *MockCartRepository) CreateCart(ctx context.Context) (cart.Cart, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(cart.Cart), args.Error(1)
+}
+
+func (m *MockCartRepository) GetCartById(ctx context.Context, id string) (cart.Cart, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(cart.Cart), args.Error(1)
+}
+
+func (m *MockCartRepository) AddCartItem(ctx context.Context, cartId string, bookId string, quantity int) error {
+	args := m.Called(ctx, cartId, bookId, quantity)
+	return args.Error(0)
+}
+
+func (m *MockCartRepository) UpdateCartItem(ctx context.Context, cartId string, bookId string, quantity int) error {
+	args := m.Called(ctx, cartId, bookId, quantity)
+	return args.Error(0)
+}
+
+func (m *MockCartRepository) RemoveCartItem(ctx context.Context, cartId string, bookId string) error {
+	args := m.Called(ctx, cartId, bookId)
+	return args.Error(0)
+}
+
+func newTestCart() cart.Cart {
+	return cart.Cart{
+		Id: "1234",
+		Items: []cart.Item{
+			{BookId: "5678", Title: "Moby Dick", Author: "Herman Melville", Price: book.NewMoney(1010, book.DefaultCurrency), Quantity: 3},
+			{BookId: "9012", Title: "The Stranger", Author: "Albert Camus", Price: book.NewMoney(20, book.DefaultCurrency), Quantity: 1},
+		},
+	}
+}
+
+// newTestCartWithTotal returns the test cart with the subtotals and the total the service calculates
+func newTestCartWithTotal() cart.Cart {
+	c := newTestCart()
+	c.Items[0].Subtotal = book.NewMoney(3030, book.DefaultCurrency)
+	c.Items[1].Subtotal = book.NewMoney(20, book.DefaultCurrency)
+	c.Total = book.NewMoney(3050, book.DefaultCurrency)
+
+	return c
+}
+
+func TestGetCartById(t *testing.T) {
+	tests := []struct {
+		name               string
+		expectedOutput     any
+		serviceReturn      []any
+		expectedStatusCode int
+	}{
+		{
+			name:               "Success",
+			serviceReturn:      []any{newTestCart(), nil},
+			expectedOutput:     newTestJson(t, newTestCartWithTotal()),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Not found",
+			serviceReturn:  []any{cart.Cart{}, cart.ErrNotFound},
+			expectedOutput: echo.NewHTTPError(http.StatusNotFound, "cart not found"),
+		},
+		{
+			name:           "Internal server error",
+			serviceReturn:  []any{cart.Cart{}, errors.New("internal server error")},
+			expectedOutput: echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodGet, "/cart/:id", nil)
+
+			mockRepository := new(MockCartRepository)
+			mockRepository.On("GetCartById", ctx.Request().Context(), "1234").Return(test.serviceReturn...)
+			h := handler{cartService: cart.NewCartService(mockRepository)}
+
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("1234")
+			err := h.getCartById(ctx)
+
+			if err != nil {
+				if !assert.Equal(t, test.expectedOutput, err) {
+					return
+				}
+			} else {
+				assert.Equal(t, test.expectedStatusCode, rec.Code)
+				assert.Equal(t, test.expectedOutput, strings.TrimSpace(rec.Body.String()))
+			}
+
+			mockRepository.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAddCartItem(t *testing.T) {
+	tests := []struct {
+		name               string
+		payload            string
+		expectedOutput     any
+		serviceReturn      error
+		expectedBookId     string
+		expectedQuantity   int
+		expectedStatusCode int
+	}{
+		{
+			name:               "Success",
+			payload:            `{"book_id":"5678","quantity":3}`,
+			expectedBookId:     "5678",
+			expectedQuantity:   3,
+			expectedOutput:     newTestJson(t, newTestCartWithTotal()),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:           "No book id",
+			payload:        `{"quantity":3}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'book_id' is required"),
+		},
+		{
+			name:           "Negative quantity",
+			payload:        `{"book_id":"5678","quantity":-3}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'quantity' should be greater than 0"),
+		},
+		{
+			name:             "Invalid book",
+			payload:          `{"book_id":"5678","quantity":3}`,
+			serviceReturn:    book.ErrNotFound,
+			expectedBookId:   "5678",
+			expectedQuantity: 3,
+			expectedOutput:   echo.NewHTTPError(http.StatusBadRequest, "invalid book"),
+		},
+		{
+			name:             "Cart not found",
+			payload:          `{"book_id":"5678","quantity":3}`,
+			serviceReturn:    cart.ErrNotFound,
+			expectedBookId:   "5678",
+			expectedQuantity: 3,
+			expectedOutput:   echo.NewHTTPError(http.StatusNotFound, "cart not found"),
+		},
+		{
+			name:             "Internal server error",
+			payload:          `{"book_id":"5678","quantity":3}`,
+			serviceReturn:    errors.New("internal server error"),
+			expectedBookId:   "5678",
+			expectedQuantity: 3,
+			expectedOutput:   echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodPost, "/cart/:id/items", strings.NewReader(test.payload))
+
+			mockRepository := new(MockCartRepository)
+			mockRepository.On("AddCartItem", ctx.Request().Context(), "1234", test.expectedBookId, test.expectedQuantity).Return(test.serviceReturn)
+			mockRepository.On("GetCartById", ctx.Request().Context(), "1234").Return(newTestCart(), nil)
+			h := handler{cartService: cart.NewCartService(mockRepository)}
+
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("1234")
+			err := h.addCartItem(ctx)
+
+			if err != nil {
+				assert.Equal(t, test.expectedOutput, err)
+				return
+			}
+
+			assert.Equal(t, test.expectedStatusCode, rec.Code)
+			assert.Equal(t, test.expectedOutput, strings.TrimSpace(rec.Body.String()))
+			mockRepository.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateCartItem(t *testing.T) {
+	tests := []struct {
+		name               string
+		payload            string
+		expectedOutput     any
+		serviceReturn      error
+		expectedQuantity   int
+		expectedStatusCode int
+	}{
+		{
+			name:               "Success",
+			payload:            `{"quantity":3}`,
+			expectedQuantity:   3,
+			expectedOutput:     newTestJson(t, newTestCartWithTotal()),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Zero quantity",
+			payload:        `{"quantity":0}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'quantity' is required"),
+		},
+		{
+			name:             "Item not found",
+			payload:          `{"quantity":3}`,
+			serviceReturn:    cart.ErrItemNotFound,
+			expectedQuantity: 3,
+			expectedOutput:   echo.NewHTTPError(http.StatusNotFound, "item not found"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodPatch, "/cart/:id/items/:book_id", strings.NewReader(test.payload))
+
+			mockRepository := new(MockCartRepository)
+			mockRepository.On("UpdateCartItem", ctx.Request().Context(), "1234", "5678", test.expectedQuantity).Return(test.serviceReturn)
+			mockRepository.On("GetCartById", ctx.Request().Context(), "1234").Return(newTestCart(), nil)
+			h := handler{cartService: cart.NewCartService(mockRepository)}
+
+			ctx.SetParamNames("id", "book_id")
+			ctx.SetParamValues("1234", "5678")
+			err := h.updateCartItem(ctx)
+
+			if err != nil {
+				assert.Equal(t, test.expectedOutput, err)
+				return
+			}
+
+			assert.Equal(t, test.expectedStatusCode, rec.Code)
+			assert.Equal(t, test.expectedOutput, strings.TrimSpace(rec.Body.String()))
+			mockRepository.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRemoveCartItem(t *testing.T) {
+	tests := []struct {
+		name               string
+		expectedOutput     any
+		serviceReturn      error
+		expectedStatusCode int
+	}{
+		{
+			name:               "Success",
+			expectedOutput:     newTestJson(t, newTestCartWithTotal()),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Item not found",
+			serviceReturn:  cart.ErrItemNotFound,
+			expectedOutput: echo.NewHTTPError(http.StatusNotFound, "item not found"),
+		},
+		{
+			name:           "Internal server error",
+			serviceReturn:  errors.New("internal server error"),
+			expectedOutput: echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodDelete, "/cart/:id/items/:book_id", nil)
+
+			mockRepository := new(MockCartRepository)
+			mockRepository.On("RemoveCartItem", ctx.Request().Context(), "1234", "5678").Return(test.serviceReturn)
+			mockRepository.On("GetCartById", ctx.Request().Context(), "1234").Return(newTestCart(), nil)
+			h := handler{cartService: cart.NewCartService(mockRepository)}
+
+			ctx.SetParamNames("id", "book_id")
+			ctx.SetParamValues("1234", "5678")
+			err := h.removeCartItem(ctx)
+
+			if err != nil {
+				assert.Equal(t, test.expectedOutput, err)
+				return
+			}
+
+			assert.Equal(t, test.expectedStatusCode, rec.Code)
+			assert.Equal(t, test.expectedOutput, strings.TrimSpace(rec.Body.String()))
+			mockRepository.AssertExpectations(t)
+		})
+	}
+}
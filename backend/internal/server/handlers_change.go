@@ -0,0 +1,34 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cativovo/bookstore/internal/change"
+	"github.com/labstack/echo/v4"
+)
+
+// getChanges returns the changes of the catalog after the since token in the order they were committed.
+// The feed has no pages, page_size is the max number of changes and next is the token of the following request.
+func (h *handler) getChanges(ctx echo.Context) error {
+	p, err := h.bindPagination(ctx)
+	if err != nil {
+		return err
+	}
+
+	changes, next, err := h.changeService.GetChanges(ctx.Request().Context(), ctx.QueryParam("since"), p.limit())
+	if err != nil {
+		if errors.Is(err, change.ErrInvalidToken) {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid value for 'since'")
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"changes":  changes,
+		"next":     next,
+		"has_more": len(changes) == p.limit(),
+	})
+}
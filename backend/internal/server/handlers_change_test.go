@@ -0,0 +1,131 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cativovo/bookstore/internal/change"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockChangeRepository struct {
+	mock.Mock
+}
+
+func (m *MockChangeRepository) GetChanges(ctx context.Context, since string, limit int) ([]change.Change, error) {
+	args := m.Called(ctx, since, limit)
+	return args.Get(0).([]change.Change), args.Error(1)
+}
+
+func TestGetChanges(t *testing.T) {
+	createdAt := time.Date(2024, time.April, 28, 14, 19, 6, 0, time.UTC)
+	changes := []change.Change{
+		{
+			CreatedAt: createdAt,
+			Token:     "MTAwLjE",
+			Entity:    change.EntityGenre,
+			EntityId:  "4a2f1c7e-6d1b-4e55-9a43-0c8f5d7b2e11",
+			Action:    change.ActionCreate,
+			Data:      json.RawMessage(`{"id":"4a2f1c7e-6d1b-4e55-9a43-0c8f5d7b2e11","name":"Fantasy"}`),
+		},
+		{
+			CreatedAt: createdAt,
+			Token:     "MTAxLjI",
+			Entity:    change.EntityGenre,
+			EntityId:  "4a2f1c7e-6d1b-4e55-9a43-0c8f5d7b2e11",
+			Action:    change.ActionDelete,
+			Data:      json.RawMessage("null"),
+		},
+	}
+
+	tests := []struct {
+		name               string
+		query              string
+		expectedSince      string
+		expectedLimit      int
+		serviceReturn      []change.Change
+		serviceErr         error
+		expectedOutput     any
+		expectedStatusCode int
+	}{
+		{
+			name:          "Success",
+			query:         "page_size=2",
+			expectedLimit: 2,
+			serviceReturn: changes,
+			expectedOutput: map[string]any{
+				"changes":  changes,
+				"next":     "MTAxLjI",
+				"has_more": true,
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:          "No more changes",
+			query:         "since=MTAxLjI",
+			expectedSince: "MTAxLjI",
+			expectedLimit: defaultPageSize,
+			serviceReturn: []change.Change{},
+			expectedOutput: map[string]any{
+				"changes":  []change.Change{},
+				"next":     "MTAxLjI",
+				"has_more": false,
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Invalid token",
+			query:          "since=abc",
+			expectedSince:  "abc",
+			expectedLimit:  defaultPageSize,
+			serviceReturn:  []change.Change{},
+			serviceErr:     change.ErrInvalidToken,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "invalid value for 'since'"),
+		},
+		{
+			name:           "Invalid page size",
+			query:          "page_size=0",
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'page_size' should be between 1 and 100"),
+		},
+		{
+			name:           "Internal server error",
+			expectedLimit:  defaultPageSize,
+			serviceReturn:  []change.Change{},
+			serviceErr:     errors.New("internal server error"),
+			expectedOutput: echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodGet, "/changes?"+test.query, nil)
+
+			mockRepository := new(MockChangeRepository)
+			mockRepository.On("GetChanges", ctx.Request().Context(), test.expectedSince, test.expectedLimit).Return(test.serviceReturn, test.serviceErr)
+			h := handler{changeService: change.NewChangeService(mockRepository)}
+
+			err := h.getChanges(ctx)
+
+			if err != nil {
+				assert.Equal(t, test.expectedOutput, err)
+				return
+			}
+
+			expected, err := json.Marshal(test.expectedOutput)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, test.expectedStatusCode, rec.Code)
+			assert.Equal(t, string(expected), strings.TrimSpace(rec.Body.String()))
+			mockRepository.AssertExpectations(t)
+		})
+	}
+}
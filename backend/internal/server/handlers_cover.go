@@ -0,0 +1,83 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/cativovo/bookstore/internal/book"
+	"github.com/cativovo/bookstore/internal/cover"
+	"github.com/labstack/echo/v4"
+)
+
+// the room the boundaries and the headers of the multipart form take besides the file
+const multipartOverhead = 64 << 10
+
+// uploadCover sets the cover of the book to the image of the 'cover' field of a multipart form
+func (h *handler) uploadCover(ctx echo.Context) error {
+	req := ctx.Request()
+	req.Body = http.MaxBytesReader(ctx.Response(), req.Body, cover.MaxSize+multipartOverhead)
+
+	fh, err := ctx.FormFile("cover")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return echo.NewHTTPError(http.StatusRequestEntityTooLarge, msgCoverTooLarge)
+		}
+
+		return echo.NewHTTPError(http.StatusBadRequest, "'cover' is required")
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+	defer f.Close()
+
+	b, err := h.coverService.UploadCover(req.Context(), ctx.Param("id"), f)
+	if err != nil {
+		if errors.Is(err, book.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "book not found")
+		}
+		if errors.Is(err, cover.ErrTooLarge) {
+			return echo.NewHTTPError(http.StatusRequestEntityTooLarge, msgCoverTooLarge)
+		}
+		if errors.Is(err, cover.ErrUnsupportedType) {
+			return echo.NewHTTPError(http.StatusUnsupportedMediaType, "'cover' should be a jpeg, png or gif image")
+		}
+		if errors.Is(err, cover.ErrInvalidImage) {
+			return echo.NewHTTPError(http.StatusBadRequest, "'cover' is not a valid image")
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.JSON(http.StatusOK, b)
+}
+
+// getCover serves a file of an uploaded cover, a new upload has new keys so the files are cached as immutable
+func (h *handler) getCover(ctx echo.Context) error {
+	key := ctx.Param("key")
+
+	blob, err := h.coverService.GetCover(ctx.Request().Context(), key)
+	if err != nil {
+		if errors.Is(err, cover.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "cover not found")
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+	defer blob.Close()
+
+	header := ctx.Response().Header()
+	header.Set("Cache-Control", "public, max-age=31536000, immutable")
+	header.Set("ETag", fmt.Sprintf("%q", key))
+
+	// ServeContent sets the content type from the extension of the key and answers the conditional and range requests
+	http.ServeContent(ctx.Response(), ctx.Request(), key, blob.ModTime, blob)
+
+	return nil
+}
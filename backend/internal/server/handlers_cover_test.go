@@ -0,0 +1,257 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cativovo/bookstore/internal/book"
+	"github.com/cativovo/bookstore/internal/cover"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockBlobStore struct {
+	mock.Mock
+}
+
+func (m *MockBlobStore) Put(ctx context.Context, key string, r io.Reader) error {
+	args := m.Called(ctx, key, r)
+	return args.Error(0)
+}
+
+func (m *MockBlobStore) Get(ctx context.Context, key string) (cover.Blob, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(cover.Blob), args.Error(1)
+}
+
+func (m *MockBlobStore) Delete(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+type nopSeekCloser struct {
+	*bytes.Reader
+}
+
+func (nopSeekCloser) Close() error {
+	return nil
+}
+
+func newTestPng(t *testing.T, width int, height int) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// newMultipartCover returns a multipart form with the file in the field and its content type
+func newMultipartCover(t *testing.T, field string, file []byte) (*bytes.Buffer, string) {
+	t.Helper()
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+
+	fw, err := w.CreateFormFile(field, "cover.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := fw.Write(file); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return &body, w.FormDataContentType()
+}
+
+func TestUploadCover(t *testing.T) {
+	updated := book.Book{
+		Id:         "1234",
+		Title:      "this is a title",
+		Author:     "john doe",
+		CoverImage: book.UploadedCoverImage("9b1deb4d3b7d4bad9bdd2b0d7b3dcb6d", ".png"),
+		Genres:     []string{"horror"},
+		Price:      book.NewMoney(6900, book.DefaultCurrency),
+	}
+
+	updatedJson, err := json.Marshal(updated)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the magic number of png followed by garbage
+	corrupted := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0xff}, 64)...)
+
+	tests := []struct {
+		name               string
+		field              string
+		file               []byte
+		storeCalled        bool
+		updateReturn       []any
+		expectedOutput     any
+		expectedStatusCode int
+	}{
+		{
+			name:               "Success",
+			field:              "cover",
+			file:               newTestPng(t, 800, 1200),
+			storeCalled:        true,
+			updateReturn:       []any{updated, nil},
+			expectedOutput:     string(updatedJson),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:           "No cover",
+			field:          "image",
+			file:           newTestPng(t, 80, 120),
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'cover' is required"),
+		},
+		{
+			name:           "Unsupported type",
+			field:          "cover",
+			file:           []byte("this is not an image"),
+			expectedOutput: echo.NewHTTPError(http.StatusUnsupportedMediaType, "'cover' should be a jpeg, png or gif image"),
+		},
+		{
+			name:           "Corrupted image",
+			field:          "cover",
+			file:           corrupted,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'cover' is not a valid image"),
+		},
+		{
+			name:           "Too large",
+			field:          "cover",
+			file:           append(newTestPng(t, 80, 120), make([]byte, cover.MaxSize)...),
+			expectedOutput: echo.NewHTTPError(http.StatusRequestEntityTooLarge, msgCoverTooLarge),
+		},
+		{
+			name:           "Too many pixels",
+			field:          "cover",
+			file:           newTestPng(t, 2000, 3001),
+			expectedOutput: echo.NewHTTPError(http.StatusRequestEntityTooLarge, msgCoverTooLarge),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			body, contentType := newMultipartCover(t, test.field, test.file)
+			ctx, rec := newEchoContext(t, http.MethodPost, "/book/:id/cover", body)
+			ctx.Request().Header.Set(echo.HeaderContentType, contentType)
+
+			mockStore := new(MockBlobStore)
+			mockRepository := new(MockBookRepository)
+			if test.storeCalled {
+				// the original and a thumbnail per size
+				mockStore.On("Put", ctx.Request().Context(), mock.MatchedBy(cover.ValidKey), mock.Anything).Return(nil).Times(len(book.CoverSizes))
+				mockRepository.On("UpdateBook", ctx.Request().Context(), "1234", mock.MatchedBy(func(options book.UpdateBookOptions) bool {
+					return options.CoverImage != nil && strings.HasSuffix(*options.CoverImage, "_original.png")
+				})).Return(test.updateReturn...)
+			}
+			h := handler{coverService: cover.NewCoverService(mockStore, book.NewBookService(mockRepository))}
+
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("1234")
+			err := h.uploadCover(ctx)
+
+			mockStore.AssertExpectations(t)
+			mockRepository.AssertExpectations(t)
+
+			if err != nil {
+				assert.Equal(t, test.expectedOutput, err)
+				return
+			}
+
+			assert.Equal(t, test.expectedStatusCode, rec.Code)
+			assert.Equal(t, test.expectedOutput, strings.TrimSpace(rec.Body.String()))
+		})
+	}
+}
+
+func TestGetCover(t *testing.T) {
+	const key = "9b1deb4d3b7d4bad9bdd2b0d7b3dcb6d_small.jpg"
+	content := []byte("jpeg content")
+
+	tests := []struct {
+		name               string
+		key                string
+		storeCalled        bool
+		storeReturn        []any
+		expectedOutput     any
+		expectedStatusCode int
+	}{
+		{
+			name:               "Success",
+			key:                key,
+			storeCalled:        true,
+			storeReturn:        []any{cover.Blob{ReadSeekCloser: nopSeekCloser{bytes.NewReader(content)}, ModTime: time.Date(2024, 5, 16, 10, 0, 0, 0, time.UTC)}, nil},
+			expectedOutput:     string(content),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Not found",
+			key:            key,
+			storeCalled:    true,
+			storeReturn:    []any{cover.Blob{}, cover.ErrNotFound},
+			expectedOutput: echo.NewHTTPError(http.StatusNotFound, "cover not found"),
+		},
+		{
+			name:           "Not a cover",
+			key:            "..%2F.env",
+			expectedOutput: echo.NewHTTPError(http.StatusNotFound, "cover not found"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodGet, "/covers/:key", nil)
+
+			mockStore := new(MockBlobStore)
+			if test.storeCalled {
+				mockStore.On("Get", ctx.Request().Context(), test.key).Return(test.storeReturn...)
+			}
+			h := handler{coverService: cover.NewCoverService(mockStore, nil)}
+
+			ctx.SetParamNames("key")
+			ctx.SetParamValues(test.key)
+			err := h.getCover(ctx)
+
+			mockStore.AssertExpectations(t)
+
+			if err != nil {
+				assert.Equal(t, test.expectedOutput, err)
+				return
+			}
+
+			assert.Equal(t, test.expectedStatusCode, rec.Code)
+			assert.Equal(t, test.expectedOutput, rec.Body.String())
+			assert.Equal(t, "image/jpeg", rec.Header().Get(echo.HeaderContentType))
+			assert.Equal(t, "public, max-age=31536000, immutable", rec.Header().Get("Cache-Control"))
+			assert.Equal(t, `"`+key+`"`, rec.Header().Get("ETag"))
+		})
+	}
+}
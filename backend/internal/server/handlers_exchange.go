@@ -0,0 +1,111 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cativovo/bookstore/internal/book"
+	"github.com/cativovo/bookstore/internal/exchange"
+	"github.com/labstack/echo/v4"
+)
+
+func (h *handler) getExchangeRates(ctx echo.Context) error {
+	rates, err := h.exchangeService.GetRates(ctx.Request().Context())
+	if err != nil {
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"rates": rates,
+	})
+}
+
+type payloadExchangeRate struct {
+	Currency string      `json:"currency" validate:"required,iso4217"`
+	Rate     json.Number `json:"rate" validate:"required"`
+	Rounding int64       `json:"rounding" validate:"gte=0"`
+}
+
+type payloadUpdateExchangeRates struct {
+	Rates []payloadExchangeRate `json:"rates" validate:"required,min=1,dive"`
+}
+
+func (h *handler) updateExchangeRates(ctx echo.Context) error {
+	var payload payloadUpdateExchangeRates
+	if err := ctx.Bind(&payload); err != nil {
+		return getBindErr(err)
+	}
+
+	if err := ctx.Validate(&payload); err != nil {
+		return err
+	}
+
+	rates := make([]exchange.Rate, len(payload.Rates))
+
+	for i, v := range payload.Rates {
+		rates[i] = exchange.Rate{
+			Currency: v.Currency,
+			Rate:     v.Rate,
+			Rounding: v.Rounding,
+		}
+	}
+
+	if err := h.exchangeService.UpdateRates(ctx.Request().Context(), rates); err != nil {
+		if errors.Is(err, book.ErrInvalidCurrency) || errors.Is(err, exchange.ErrInvalidRate) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return h.getExchangeRates(ctx)
+}
+
+type payloadSetBookPrice struct {
+	Price *book.Money `json:"price" validate:"required,gt=0"`
+}
+
+func (h *handler) setBookPrice(ctx echo.Context) error {
+	var payload payloadSetBookPrice
+	if err := ctx.Bind(&payload); err != nil {
+		return getBindErr(err)
+	}
+
+	if err := ctx.Validate(&payload); err != nil {
+		return err
+	}
+
+	err := h.bookService.SetBookPrice(ctx.Request().Context(), ctx.Param("id"), *payload.Price)
+	if err != nil {
+		if errors.Is(err, book.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "book not found")
+		}
+		if errors.Is(err, book.ErrInvalidCurrency) {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("'price' should not be in %s, update the price of the book instead", book.DefaultCurrency))
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func (h *handler) deleteBookPrice(ctx echo.Context) error {
+	err := h.bookService.DeleteBookPrice(ctx.Request().Context(), ctx.Param("id"), strings.ToUpper(ctx.Param("currency")))
+	if err != nil {
+		if errors.Is(err, book.ErrPriceNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "price not found")
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,207 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/cativovo/bookstore/internal/book"
+	"github.com/cativovo/bookstore/internal/exchange"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockExchangeRepository struct {
+	mock.Mock
+}
+
+func (m *MockExchangeRepository) UpsertExchangeRates(ctx context.Context, rates []exchange.Rate) error {
+	args := m.Called(ctx, rates)
+	return args.Error(0)
+}
+
+func (m *MockExchangeRepository) GetExchangeRates(ctx context.Context) ([]exchange.Rate, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]exchange.Rate), args.Error(1)
+}
+
+func TestUpdateExchangeRates(t *testing.T) {
+	data, err := os.ReadFile("../../testdata/exchange_rates.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rates, err := exchange.ReadRatesFile("../../testdata/exchange_rates.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a missing rounding rounds to the minor unit
+	expectedRates := make([]exchange.Rate, len(rates))
+	copy(expectedRates, rates)
+	for i := range expectedRates {
+		if expectedRates[i].Rounding == 0 {
+			expectedRates[i].Rounding = 1
+		}
+	}
+
+	tests := []struct {
+		name               string
+		payload            string
+		expectedOutput     any
+		expectedServiceArg []exchange.Rate
+		serviceReturn      error
+		expectedStatusCode int
+	}{
+		{
+			name:               "Success",
+			payload:            string(data),
+			expectedServiceArg: expectedRates,
+			expectedOutput:     `{"rates":[]}`,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "Number rate",
+			payload:            `{"rates":[{"currency":"EUR","rate":0.93}]}`,
+			expectedServiceArg: []exchange.Rate{{Currency: "EUR", Rate: "0.93", Rounding: 1}},
+			expectedOutput:     `{"rates":[]}`,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Empty rates",
+			payload:        `{"rates":[]}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'rates' should have a length of at least 1"),
+		},
+		{
+			name:           "Invalid currency",
+			payload:        `{"rates":[{"currency":"EURO","rate":"0.93"}]}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'currency' should be an ISO 4217 currency code"),
+		},
+		{
+			name:           "Default currency",
+			payload:        `{"rates":[{"currency":"USD","rate":"1"}]}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "invalid currency: the rates are relative to USD"),
+		},
+		{
+			name:           "Negative rate",
+			payload:        `{"rates":[{"currency":"EUR","rate":"-0.93"}]}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "invalid rate: '-0.93' of EUR should be a decimal number"),
+		},
+		{
+			name:           "Zero rate",
+			payload:        `{"rates":[{"currency":"EUR","rate":"0.0"}]}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "invalid rate: '0.0' of EUR should be greater than 0"),
+		},
+		{
+			name:           "Negative rounding",
+			payload:        `{"rates":[{"currency":"EUR","rate":"0.93","rounding":-5}]}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'rounding' should be greater than or equal to 0"),
+		},
+		{
+			name:               "Internal server error",
+			payload:            `{"rates":[{"currency":"EUR","rate":"0.93","rounding":1}]}`,
+			expectedServiceArg: []exchange.Rate{{Currency: "EUR", Rate: "0.93", Rounding: 1}},
+			serviceReturn:      errors.New("internal server error"),
+			expectedOutput:     echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodPost, "/exchange-rates", strings.NewReader(test.payload))
+
+			mockRepository := new(MockExchangeRepository)
+			mockRepository.On("UpsertExchangeRates", ctx.Request().Context(), test.expectedServiceArg).Return(test.serviceReturn)
+			mockRepository.On("GetExchangeRates", ctx.Request().Context()).Return([]exchange.Rate{}, nil)
+			h := handler{exchangeService: exchange.NewExchangeService(mockRepository)}
+
+			err := h.updateExchangeRates(ctx)
+
+			if err != nil {
+				assert.Equal(t, test.expectedOutput, err)
+				if test.expectedServiceArg == nil {
+					mockRepository.AssertNotCalled(t, "UpsertExchangeRates", ctx.Request().Context(), mock.Anything)
+				}
+				return
+			}
+
+			assert.Equal(t, test.expectedStatusCode, rec.Code)
+			assert.Equal(t, test.expectedOutput, strings.TrimSpace(rec.Body.String()))
+			mockRepository.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSetBookPrice(t *testing.T) {
+	tests := []struct {
+		name               string
+		payload            string
+		expectedOutput     any
+		expectedServiceArg book.Money
+		serviceReturn      error
+		expectedStatusCode int
+	}{
+		{
+			name:               "Success",
+			payload:            `{"price":{"amount":"1500","currency":"JPY"}}`,
+			expectedServiceArg: book.NewMoney(1500, "JPY"),
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name:           "Minor unit of the currency",
+			payload:        `{"price":{"amount":"1500.50","currency":"JPY"}}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "invalid money: JPY has 0 digits after the decimal point"),
+		},
+		{
+			name:           "Default currency",
+			payload:        `{"price":{"amount":"10.00","currency":"USD"}}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'price' should not be in USD, update the price of the book instead"),
+		},
+		{
+			name:           "Zero price",
+			payload:        `{"price":{"amount":"0","currency":"EUR"}}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'price' should be greater than 0"),
+		},
+		{
+			name:           "No price",
+			payload:        `{}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'price' is required"),
+		},
+		{
+			name:               "Book not found",
+			payload:            `{"price":{"amount":"9.50","currency":"EUR"}}`,
+			expectedServiceArg: book.NewMoney(950, "EUR"),
+			serviceReturn:      book.ErrNotFound,
+			expectedOutput:     echo.NewHTTPError(http.StatusNotFound, "book not found"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodPut, "/book/:id/prices", strings.NewReader(test.payload))
+
+			mockRepository := new(MockBookRepository)
+			mockRepository.On("SetBookPrice", ctx.Request().Context(), "1234", test.expectedServiceArg).Return(test.serviceReturn)
+			h := handler{bookService: book.NewBookService(mockRepository)}
+
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("1234")
+			err := h.setBookPrice(ctx)
+
+			if err != nil {
+				assert.Equal(t, test.expectedOutput, err)
+				if test.expectedServiceArg == (book.Money{}) {
+					mockRepository.AssertNotCalled(t, "SetBookPrice", ctx.Request().Context(), "1234", mock.Anything)
+				}
+				return
+			}
+
+			assert.Equal(t, test.expectedStatusCode, rec.Code)
+			mockRepository.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/cativovo/bookstore/internal/catalog"
+	"github.com/labstack/echo/v4"
+)
+
+// the largest file of an import
+const maxImportSize = 32 << 20
+
+var msgImportTooLarge = fmt.Sprintf("the file should be at most %d MB", maxImportSize>>20)
+
+const mimeTextCSV = "text/csv"
+
+type importBooksQueryParam struct {
+	DryRun       bool `query:"dry_run"`
+	CreateGenres bool `query:"create_genres"`
+	ChunkSize    int  `query:"chunk_size"`
+}
+
+// importBooks creates the books of a csv or json file, the body is the file and its content type is text/csv or application/json.
+// The response is the report of every row even when rows failed.
+func (h *handler) importBooks(ctx echo.Context) error {
+	var queryParam importBooksQueryParam
+
+	err := echo.QueryParamsBinder(ctx).
+		Bool("dry_run", &queryParam.DryRun).
+		Bool("create_genres", &queryParam.CreateGenres).
+		Int("chunk_size", &queryParam.ChunkSize).
+		BindError()
+	if err != nil {
+		bindingErr := err.(*echo.BindingError)
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid value for '%s'", bindingErr.Field))
+	}
+
+	if queryParam.ChunkSize < 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "'chunk_size' should be greater than or equal to 0")
+	}
+
+	req := ctx.Request()
+	req.Body = http.MaxBytesReader(ctx.Response(), req.Body, maxImportSize)
+
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get(echo.HeaderContentType))
+
+	var rows []catalog.Row
+	switch mediaType {
+	case mimeTextCSV:
+		rows, err = catalog.ReadCSV(req.Body)
+	case echo.MIMEApplicationJSON:
+		rows, err = catalog.ReadJSON(req.Body)
+	default:
+		return echo.NewHTTPError(http.StatusUnsupportedMediaType, "the file should be text/csv or application/json")
+	}
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return echo.NewHTTPError(http.StatusRequestEntityTooLarge, msgImportTooLarge)
+		}
+		if errors.Is(err, catalog.ErrInvalidFile) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	report, err := h.catalogService.Import(req.Context(), rows, catalog.Options{
+		CreateGenres: queryParam.CreateGenres,
+		ChunkSize:    queryParam.ChunkSize,
+		DryRun:       queryParam.DryRun,
+	})
+	if err != nil {
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.JSON(http.StatusOK, report)
+}
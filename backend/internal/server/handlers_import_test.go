@@ -0,0 +1,189 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cativovo/bookstore/internal/book"
+	"github.com/cativovo/bookstore/internal/catalog"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportBooks(t *testing.T) {
+	genres := []book.Genre{{Id: "1", Name: "horror"}, {Id: "2", Name: "science"}}
+
+	dune := book.Book{
+		Title:  "Dune",
+		Author: "Frank Herbert",
+		Genres: []string{"horror", "science"},
+		Price:  book.NewMoney(1010, book.DefaultCurrency),
+		Isbn13: "9780441013593",
+	}
+	createdDune := dune
+	createdDune.Id = "1"
+
+	emma := book.Book{
+		Title:      "Emma",
+		Author:     "Jane Austen",
+		CoverImage: "https://placehold.co/600x400",
+		Genres:     []string{"romance"},
+		Price:      book.NewMoney(567, book.DefaultCurrency),
+	}
+	createdEmma := emma
+	createdEmma.Id = "2"
+
+	tests := []struct {
+		name               string
+		target             string
+		contentType        string
+		body               string
+		setupMock          func(ctx context.Context, m *MockBookRepository)
+		expectedOutput     any
+		expectedStatusCode int
+	}{
+		{
+			name:        "Success csv",
+			target:      "/books/import",
+			contentType: "text/csv",
+			body: "title,author,genres,price,isbn\n" +
+				"Dune,Frank Herbert,horror;science,10.10,978-0-441-01359-3\n" +
+				"Dune again,Frank Herbert,horror,10.10,0441013597\n" +
+				",nobody,horror,1.00,\n" +
+				"Emma,Jane Austen,romance,5.67,\n" +
+				"Existing,John Doe,horror,5.00,9780306406157\n" +
+				"Free,John Doe,horror,free,\n",
+			setupMock: func(ctx context.Context, m *MockBookRepository) {
+				m.On("GetGenres", ctx).Return(genres, nil)
+				m.On("GetBookByIsbn", ctx, "9780441013593", book.DefaultCurrency).Return(book.Book{}, book.ErrNotFound)
+				m.On("GetBookByIsbn", ctx, "9780306406157", book.DefaultCurrency).Return(book.Book{Id: "42"}, nil)
+				m.On("CreateBooks", ctx, []book.Book{dune}, false).Return([]book.Book{createdDune}, nil)
+			},
+			expectedOutput: catalog.Report{
+				Created: 1,
+				Skipped: 2,
+				Failed:  3,
+				Rows: []catalog.RowResult{
+					{Row: 1, Status: catalog.StatusCreated, Id: "1"},
+					{Row: 2, Status: catalog.StatusSkipped, Reason: "the isbn is a duplicate of row 1"},
+					{Row: 3, Status: catalog.StatusFailed, Reason: "'title' is required"},
+					{Row: 4, Status: catalog.StatusFailed, Reason: "unknown genre 'romance'"},
+					{Row: 5, Status: catalog.StatusSkipped, Id: "42", Reason: "a book with the isbn already exists"},
+					{Row: 6, Status: catalog.StatusFailed, Reason: "invalid value for 'price'"},
+				},
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:        "Dry run json creating the genres",
+			target:      "/books/import?dry_run=true&create_genres=true",
+			contentType: echo.MIMEApplicationJSON,
+			body: `[
+				{"id": "55976dc9-74d3-4982-a2ec-966b9f803d97", "title": "Emma", "author": "Jane Austen", "cover_image": "https://placehold.co/600x400", "genres": ["romance"], "price": 5.67},
+				{"title": "Dune", "author": "Frank Herbert", "genres": ["horror"], "price": 0},
+				"not a book"
+			]`,
+			setupMock: func(ctx context.Context, m *MockBookRepository) {
+				m.On("GetGenres", ctx).Return(genres, nil)
+			},
+			expectedOutput: catalog.Report{
+				DryRun:  true,
+				Created: 1,
+				Failed:  2,
+				Rows: []catalog.RowResult{
+					{Row: 1, Status: catalog.StatusCreated},
+					{Row: 2, Status: catalog.StatusFailed, Reason: "'price' should be greater than 0"},
+					{Row: 3, Status: catalog.StatusFailed, Reason: "should be an object"},
+				},
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:        "Chunk rolled back",
+			target:      "/books/import?chunk_size=2&create_genres=true",
+			contentType: echo.MIMEApplicationJSON,
+			body: `[
+				{"title": "Dune", "author": "Frank Herbert", "genres": ["horror", "science"], "price": 10.10, "isbn": "9780441013593"},
+				{"title": "Emma", "author": "Jane Austen", "cover_image": "https://placehold.co/600x400", "genres": ["romance"], "price": 5.67},
+				{"title": "Emma", "author": "Jane Austen", "cover_image": "https://placehold.co/600x400", "genres": ["romance"], "price": 5.67}
+			]`,
+			setupMock: func(ctx context.Context, m *MockBookRepository) {
+				m.On("GetGenres", ctx).Return(genres, nil)
+				m.On("GetBookByIsbn", ctx, "9780441013593", book.DefaultCurrency).Return(book.Book{}, book.ErrNotFound)
+				m.On("CreateBooks", ctx, []book.Book{dune, emma}, true).Return([]book.Book(nil), &catalog.BookError{Index: 0, Err: book.ErrAlreadyExists})
+				m.On("CreateBooks", ctx, []book.Book{emma}, true).Return([]book.Book{createdEmma}, nil)
+			},
+			expectedOutput: catalog.Report{
+				Created: 1,
+				Failed:  2,
+				Rows: []catalog.RowResult{
+					{Row: 1, Status: catalog.StatusFailed, Reason: "a book with the isbn already exists"},
+					{Row: 2, Status: catalog.StatusFailed, Reason: "row 1 failed in the same transaction"},
+					{Row: 3, Status: catalog.StatusCreated, Id: "2"},
+				},
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Unknown column",
+			target:         "/books/import",
+			contentType:    "text/csv",
+			body:           "title,writer\nDune,Frank Herbert\n",
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "invalid file: unknown column 'writer'"),
+		},
+		{
+			name:           "Not an array",
+			target:         "/books/import",
+			contentType:    echo.MIMEApplicationJSON,
+			body:           `{"title": "Dune"}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "invalid file: should be an array of books"),
+		},
+		{
+			name:           "Unsupported type",
+			target:         "/books/import",
+			contentType:    echo.MIMETextPlain,
+			body:           "Dune",
+			expectedOutput: echo.NewHTTPError(http.StatusUnsupportedMediaType, "the file should be text/csv or application/json"),
+		},
+		{
+			name:           "Invalid chunk size",
+			target:         "/books/import?chunk_size=-1",
+			contentType:    "text/csv",
+			body:           "title\n",
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'chunk_size' should be greater than or equal to 0"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodPost, test.target, strings.NewReader(test.body))
+			ctx.Request().Header.Set(echo.HeaderContentType, test.contentType)
+
+			mockRepository := new(MockBookRepository)
+			if test.setupMock != nil {
+				test.setupMock(ctx.Request().Context(), mockRepository)
+			}
+			h := handler{catalogService: catalog.NewCatalogService(mockRepository, NewValidator())}
+
+			err := h.importBooks(ctx)
+
+			mockRepository.AssertExpectations(t)
+
+			if err != nil {
+				assert.Equal(t, test.expectedOutput, err)
+				return
+			}
+
+			expectedOutput, err := json.Marshal(test.expectedOutput)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, test.expectedStatusCode, rec.Code)
+			assert.Equal(t, string(expectedOutput), strings.TrimSpace(rec.Body.String()))
+		})
+	}
+}
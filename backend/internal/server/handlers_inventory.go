@@ -0,0 +1,66 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cativovo/bookstore/internal/book"
+	"github.com/cativovo/bookstore/internal/inventory"
+	"github.com/labstack/echo/v4"
+)
+
+type payloadAdjustStock struct {
+	// https://github.com/go-playground/validator/issues/692#issuecomment-737039536
+	Quantity *int   `json:"quantity" validate:"required,ne=0"`
+	Reason   string `json:"reason" validate:"required,oneof=restock sale return damaged correction"`
+	Note     string `json:"note"`
+}
+
+func (h *handler) adjustStock(ctx echo.Context) error {
+	var payload payloadAdjustStock
+	if err := ctx.Bind(&payload); err != nil {
+		return getBindErr(err)
+	}
+
+	if err := ctx.Validate(&payload); err != nil {
+		return err
+	}
+
+	a, err := h.inventoryService.AdjustStock(ctx.Request().Context(), inventory.Adjustment{
+		BookId:   ctx.Param("id"),
+		Quantity: *payload.Quantity,
+		Reason:   inventory.Reason(payload.Reason),
+		Note:     payload.Note,
+	})
+	if err != nil {
+		if errors.Is(err, book.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "book not found")
+		}
+		if errors.Is(err, inventory.ErrInsufficientStock) {
+			return echo.NewHTTPError(http.StatusConflict, "insufficient stock")
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.JSON(http.StatusCreated, a)
+}
+
+func (h *handler) getStockAdjustments(ctx echo.Context) error {
+	p, err := h.bindPagination(ctx)
+	if err != nil {
+		return err
+	}
+
+	adjustments, count, err := h.inventoryService.GetAdjustments(ctx.Request().Context(), ctx.Param("id"), p.limit(), p.offset())
+	if err != nil {
+		if errors.Is(err, book.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "book not found")
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+	return ctx.JSON(http.StatusOK, paginate(ctx, "adjustments", adjustments, p, count))
+}
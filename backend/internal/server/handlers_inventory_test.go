@@ -0,0 +1,254 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cativovo/bookstore/internal/book"
+	"github.com/cativovo/bookstore/internal/inventory"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockInventoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockInventoryRepository) AdjustStock(ctx context.Context, a inventory.Adjustment) (inventory.Adjustment, error) {
+	args := m.Called(ctx, a)
+	return args.Get(0).(inventory.Adjustment), args.Error(1)
+}
+
+func (m *MockInventoryRepository) GetAdjustments(ctx context.Context, bookId string, limit int, offset int) ([]inventory.Adjustment, int, error) {
+	args := m.Called(ctx, bookId, limit, offset)
+	return args.Get(0).([]inventory.Adjustment), args.Int(1), args.Error(2)
+}
+
+func TestAdjustStock(t *testing.T) {
+	successArg := inventory.Adjustment{
+		BookId:   "1234",
+		Quantity: -2,
+		Reason:   inventory.ReasonSale,
+		Note:     "order 69",
+	}
+
+	successAdjustment := successArg
+	successAdjustment.Id = "5678"
+	successAdjustment.QuantityOnHand = 8
+	successAdjustment.CreatedAt = time.Date(2024, 4, 6, 0, 0, 0, 0, time.UTC)
+
+	successAdjustmentJson, err := json.Marshal(successAdjustment)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name               string
+		payload            string
+		expectedOutput     any
+		serviceReturn      []any
+		expectedServiceArg inventory.Adjustment
+		expectedStatusCode int
+	}{
+		{
+			name:               "Success",
+			payload:            `{"quantity":-2,"reason":"sale","note":"order 69"}`,
+			serviceReturn:      []any{successAdjustment, nil},
+			expectedServiceArg: successArg,
+			expectedStatusCode: http.StatusCreated,
+			expectedOutput:     string(successAdjustmentJson),
+		},
+		{
+			name:           "No quantity",
+			payload:        `{"reason":"sale"}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'quantity' is required"),
+		},
+		{
+			name:           "Zero quantity",
+			payload:        `{"quantity":0,"reason":"sale"}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'quantity' should not be equal to 0"),
+		},
+		{
+			name:           "Invalid reason",
+			payload:        `{"quantity":-2,"reason":"stolen"}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'reason' should be one of [restock, sale, return, damaged, correction]"),
+		},
+		{
+			name:               "Not found",
+			payload:            `{"quantity":-2,"reason":"sale","note":"order 69"}`,
+			serviceReturn:      []any{inventory.Adjustment{}, book.ErrNotFound},
+			expectedServiceArg: successArg,
+			expectedOutput:     echo.NewHTTPError(http.StatusNotFound, "book not found"),
+		},
+		{
+			name:               "Insufficient stock",
+			payload:            `{"quantity":-2,"reason":"sale","note":"order 69"}`,
+			serviceReturn:      []any{inventory.Adjustment{}, inventory.ErrInsufficientStock},
+			expectedServiceArg: successArg,
+			expectedOutput:     echo.NewHTTPError(http.StatusConflict, "insufficient stock"),
+		},
+		{
+			name:               "Internal server error",
+			payload:            `{"quantity":-2,"reason":"sale","note":"order 69"}`,
+			serviceReturn:      []any{inventory.Adjustment{}, errors.New("internal server error")},
+			expectedServiceArg: successArg,
+			expectedOutput:     echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodPost, "/book/:id/stock/adjust", strings.NewReader(test.payload))
+
+			mockRepository := new(MockInventoryRepository)
+			mockRepository.On("AdjustStock", ctx.Request().Context(), test.expectedServiceArg).Return(test.serviceReturn...)
+			h := handler{inventoryService: inventory.NewInventoryService(mockRepository)}
+
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("1234")
+			err := h.adjustStock(ctx)
+
+			if err != nil {
+				if !assert.Equal(t, test.expectedOutput, err) {
+					return
+				}
+
+				if len(test.serviceReturn) == 0 {
+					mockRepository.AssertNotCalled(t, "AdjustStock", test.expectedServiceArg)
+					return
+				}
+			} else {
+				assert.Equal(t, test.expectedStatusCode, rec.Code)
+				assert.Equal(t, test.expectedOutput, strings.TrimSpace(rec.Body.String()))
+			}
+
+			mockRepository.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetStockAdjustments(t *testing.T) {
+	adjustments := []inventory.Adjustment{
+		{
+			Id:             "5678",
+			BookId:         "1234",
+			Quantity:       10,
+			Reason:         inventory.ReasonRestock,
+			QuantityOnHand: 10,
+			CreatedAt:      time.Date(2024, 4, 6, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	tests := []struct {
+		expectedOutput     any
+		name               string
+		query              string
+		serviceReturn      []any
+		expectedOffset     int
+		expectedLimit      int
+		expectedStatusCode int
+	}{
+		{
+			name:          "Success",
+			serviceReturn: []any{adjustments, 21, nil},
+			expectedOutput: newTestJson(t, map[string]any{
+				"adjustments": adjustments,
+				"total":       21,
+				"page":        1,
+				"page_size":   10,
+				"pages":       3,
+				"next":        "/book/:id/stock/adjustments?page=2",
+				"prev":        nil,
+			}),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Success page",
+			query:          "?page=3",
+			serviceReturn:  []any{adjustments, 21, nil},
+			expectedOffset: 20,
+			expectedOutput: newTestJson(t, map[string]any{
+				"adjustments": adjustments,
+				"total":       21,
+				"page":        3,
+				"page_size":   10,
+				"pages":       3,
+				"next":        nil,
+				"prev":        "/book/:id/stock/adjustments?page=2",
+			}),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:          "Success page size",
+			query:         "?page_size=50",
+			serviceReturn: []any{adjustments, 21, nil},
+			expectedLimit: 50,
+			expectedOutput: newTestJson(t, map[string]any{
+				"adjustments": adjustments,
+				"total":       21,
+				"page":        1,
+				"page_size":   50,
+				"pages":       1,
+				"next":        nil,
+				"prev":        nil,
+			}),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Invalid page",
+			query:          "?page=j",
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "invalid value for 'page'"),
+		},
+		{
+			name:           "Not found",
+			serviceReturn:  []any{[]inventory.Adjustment(nil), 0, book.ErrNotFound},
+			expectedOutput: echo.NewHTTPError(http.StatusNotFound, "book not found"),
+		},
+		{
+			name:           "Internal server error",
+			serviceReturn:  []any{[]inventory.Adjustment(nil), 0, errors.New("internal server error")},
+			expectedOutput: echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodGet, "/book/:id/stock/adjustments"+test.query, nil)
+
+			limit := test.expectedLimit
+			if limit == 0 {
+				limit = 10
+			}
+
+			mockRepository := new(MockInventoryRepository)
+			mockRepository.On("GetAdjustments", ctx.Request().Context(), "1234", limit, test.expectedOffset).Return(test.serviceReturn...)
+			h := handler{inventoryService: inventory.NewInventoryService(mockRepository)}
+
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("1234")
+			err := h.getStockAdjustments(ctx)
+
+			if err != nil {
+				if !assert.Equal(t, test.expectedOutput, err) {
+					return
+				}
+
+				if len(test.serviceReturn) == 0 {
+					mockRepository.AssertNotCalled(t, "GetAdjustments", "1234", 10, test.expectedOffset)
+					return
+				}
+			} else {
+				assert.Equal(t, test.expectedStatusCode, rec.Code)
+				assert.Equal(t, test.expectedOutput, strings.TrimSpace(rec.Body.String()))
+			}
+
+			mockRepository.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/cativovo/bookstore/internal/book"
+	"github.com/cativovo/bookstore/internal/cart"
+	"github.com/cativovo/bookstore/internal/inventory"
+	"github.com/cativovo/bookstore/internal/order"
+	"github.com/labstack/echo/v4"
+)
+
+type payloadCheckoutItem struct {
+	BookId   string `json:"book_id" validate:"required"`
+	Quantity int    `json:"quantity" validate:"required,gt=0"`
+}
+
+type payloadCheckout struct {
+	CartId string                `json:"cart_id" validate:"required_without=Items,excluded_with=Items"`
+	Items  []payloadCheckoutItem `json:"items" validate:"required_without=CartId,dive"`
+}
+
+func (h *handler) checkout(ctx echo.Context) error {
+	var payload payloadCheckout
+	if err := ctx.Bind(&payload); err != nil {
+		return getBindErr(err)
+	}
+
+	if err := ctx.Validate(&payload); err != nil {
+		return err
+	}
+
+	var o order.Order
+	var err error
+
+	if payload.CartId != "" {
+		o, err = h.orderService.CheckoutCart(ctx.Request().Context(), payload.CartId)
+	} else {
+		items := make([]order.LineItem, len(payload.Items))
+
+		for i, v := range payload.Items {
+			items[i] = order.LineItem{
+				BookId:   v.BookId,
+				Quantity: v.Quantity,
+			}
+		}
+
+		o, err = h.orderService.Checkout(ctx.Request().Context(), items)
+	}
+
+	if err != nil {
+		if errors.Is(err, cart.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "cart not found")
+		}
+		if errors.Is(err, book.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid book")
+		}
+		if errors.Is(err, order.ErrEmptyOrder) {
+			return echo.NewHTTPError(http.StatusBadRequest, "order has no items")
+		}
+		if errors.Is(err, inventory.ErrInsufficientStock) {
+			return echo.NewHTTPError(http.StatusConflict, "insufficient stock")
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.JSON(http.StatusCreated, o)
+}
+
+func (h *handler) getOrders(ctx echo.Context) error {
+	p, err := h.bindPagination(ctx)
+	if err != nil {
+		return err
+	}
+
+	orders, count, err := h.orderService.GetOrders(ctx.Request().Context(), p.limit(), p.offset())
+	if err != nil {
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+	return ctx.JSON(http.StatusOK, paginate(ctx, "orders", orders, p, count))
+}
+
+func (h *handler) getOrderById(ctx echo.Context) error {
+	o, err := h.orderService.GetOrderById(ctx.Request().Context(), ctx.Param("id"))
+	if err != nil {
+		if errors.Is(err, order.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "order not found")
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.JSON(http.StatusOK, o)
+}
+
+// payloadUpdateOrderStatus only has the statuses the staff sets by hand, an order is paid and refunded by the payment service
+type payloadUpdateOrderStatus struct {
+	Status string `json:"status" validate:"required,oneof=shipped delivered cancelled"`
+}
+
+func (h *handler) updateOrderStatus(ctx echo.Context) error {
+	var payload payloadUpdateOrderStatus
+	if err := ctx.Bind(&payload); err != nil {
+		return getBindErr(err)
+	}
+
+	if err := ctx.Validate(&payload); err != nil {
+		return err
+	}
+
+	o, err := h.orderService.UpdateOrderStatus(ctx.Request().Context(), ctx.Param("id"), order.Status(payload.Status))
+	if err != nil {
+		if errors.Is(err, order.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "order not found")
+		}
+		if errors.Is(err, order.ErrInvalidTransition) {
+			return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("cannot change order status to '%s'", payload.Status))
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.JSON(http.StatusOK, o)
+}
@@ -0,0 +1,351 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cativovo/bookstore/internal/book"
+	"github.com/cativovo/bookstore/internal/cart"
+	"github.com/cativovo/bookstore/internal/inventory"
+	"github.com/cativovo/bookstore/internal/order"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOrderRepository struct {
+	mock.Mock
+}
+
+func (m *MockOrderRepository) CreateOrder(ctx context.Context, items []order.LineItem) (order.Order, error) {
+	args := m.Called(ctx, items)
+	return args.Get(0).(order.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) CreateOrderFromCart(ctx context.Context, cartId string) (order.Order, error) {
+	args := m.Called(ctx, cartId)
+	return args.Get(0).(order.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) GetOrderById(ctx context.Context, id string) (order.Order, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(order.Order), args.Error(1)
+}
+
+func (m *MockOrderRepository) GetOrders(ctx context.Context, limit int, offset int) ([]order.Order, int, error) {
+	args := m.Called(ctx, limit, offset)
+	return args.Get(0).([]order.Order), args.Int(1), args.Error(2)
+}
+
+func (m *MockOrderRepository) UpdateOrderStatus(ctx context.Context, id string, current order.Status, next order.Status) error {
+	args := m.Called(ctx, id, current, next)
+	return args.Error(0)
+}
+
+func newTestOrder(status order.Status) order.Order {
+	return order.Order{
+		Id:     "1234",
+		Status: status,
+		Items: []order.Item{
+			{BookId: "5678", Title: "Moby Dick", Author: "Herman Melville", Price: book.NewMoney(1010, book.DefaultCurrency), Quantity: 3},
+		},
+		CreatedAt: time.Date(2024, 4, 13, 0, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2024, 4, 13, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+// newTestOrderWithTotal returns the test order with the subtotal and the total the service calculates
+func newTestOrderWithTotal(status order.Status) order.Order {
+	o := newTestOrder(status)
+	o.Items[0].Subtotal = book.NewMoney(3030, book.DefaultCurrency)
+	o.Total = book.NewMoney(3030, book.DefaultCurrency)
+
+	return o
+}
+
+func TestCheckout(t *testing.T) {
+	successItems := []order.LineItem{{BookId: "5678", Quantity: 3}}
+
+	tests := []struct {
+		name               string
+		payload            string
+		expectedOutput     any
+		serviceReturn      []any
+		expectedMethod     string
+		expectedServiceArg any
+		expectedStatusCode int
+	}{
+		{
+			name:               "Success items",
+			payload:            `{"items":[{"book_id":"5678","quantity":1},{"book_id":"5678","quantity":2}]}`,
+			serviceReturn:      []any{newTestOrder(order.StatusPending), nil},
+			expectedMethod:     "CreateOrder",
+			expectedServiceArg: successItems,
+			expectedOutput:     newTestJson(t, newTestOrderWithTotal(order.StatusPending)),
+			expectedStatusCode: http.StatusCreated,
+		},
+		{
+			name:               "Success cart",
+			payload:            `{"cart_id":"9012"}`,
+			serviceReturn:      []any{newTestOrder(order.StatusPending), nil},
+			expectedMethod:     "CreateOrderFromCart",
+			expectedServiceArg: "9012",
+			expectedOutput:     newTestJson(t, newTestOrderWithTotal(order.StatusPending)),
+			expectedStatusCode: http.StatusCreated,
+		},
+		{
+			name:           "Empty json",
+			payload:        `{}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'cart_id' is required without 'items','items' is required without 'cart_id'"),
+		},
+		{
+			name:           "Cart and items",
+			payload:        `{"cart_id":"9012","items":[{"book_id":"5678","quantity":1}]}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'cart_id' should not be set with 'items'"),
+		},
+		{
+			name:           "Invalid quantity",
+			payload:        `{"items":[{"book_id":"5678","quantity":-1}]}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'quantity' should be greater than 0"),
+		},
+		{
+			name:           "Empty items",
+			payload:        `{"items":[]}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "order has no items"),
+		},
+		{
+			name:               "Cart not found",
+			payload:            `{"cart_id":"9012"}`,
+			serviceReturn:      []any{order.Order{}, cart.ErrNotFound},
+			expectedMethod:     "CreateOrderFromCart",
+			expectedServiceArg: "9012",
+			expectedOutput:     echo.NewHTTPError(http.StatusNotFound, "cart not found"),
+		},
+		{
+			name:               "Invalid book",
+			payload:            `{"items":[{"book_id":"5678","quantity":3}]}`,
+			serviceReturn:      []any{order.Order{}, book.ErrNotFound},
+			expectedMethod:     "CreateOrder",
+			expectedServiceArg: successItems,
+			expectedOutput:     echo.NewHTTPError(http.StatusBadRequest, "invalid book"),
+		},
+		{
+			name:               "Insufficient stock",
+			payload:            `{"items":[{"book_id":"5678","quantity":3}]}`,
+			serviceReturn:      []any{order.Order{}, inventory.ErrInsufficientStock},
+			expectedMethod:     "CreateOrder",
+			expectedServiceArg: successItems,
+			expectedOutput:     echo.NewHTTPError(http.StatusConflict, "insufficient stock"),
+		},
+		{
+			name:               "Internal server error",
+			payload:            `{"items":[{"book_id":"5678","quantity":3}]}`,
+			serviceReturn:      []any{order.Order{}, errors.New("internal server error")},
+			expectedMethod:     "CreateOrder",
+			expectedServiceArg: successItems,
+			expectedOutput:     echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodPost, "/checkout", strings.NewReader(test.payload))
+
+			mockRepository := new(MockOrderRepository)
+			if test.expectedMethod != "" {
+				mockRepository.On(test.expectedMethod, ctx.Request().Context(), test.expectedServiceArg).Return(test.serviceReturn...)
+			}
+			h := handler{orderService: order.NewOrderService(mockRepository)}
+
+			err := h.checkout(ctx)
+
+			if err != nil {
+				assert.Equal(t, test.expectedOutput, err)
+			} else {
+				assert.Equal(t, test.expectedStatusCode, rec.Code)
+				assert.Equal(t, test.expectedOutput, strings.TrimSpace(rec.Body.String()))
+			}
+
+			mockRepository.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateOrderStatus(t *testing.T) {
+	tests := []struct {
+		name               string
+		payload            string
+		expectedOutput     any
+		current            order.Status
+		next               order.Status
+		serviceReturn      error
+		expectedStatusCode int
+	}{
+		{
+			name:               "Success shipped",
+			payload:            `{"status":"shipped"}`,
+			current:            order.StatusPaid,
+			next:               order.StatusShipped,
+			expectedOutput:     newTestJson(t, newTestOrderWithTotal(order.StatusShipped)),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "Success cancelled",
+			payload:            `{"status":"cancelled"}`,
+			current:            order.StatusPending,
+			next:               order.StatusCancelled,
+			expectedOutput:     newTestJson(t, newTestOrderWithTotal(order.StatusCancelled)),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Invalid status",
+			payload:        `{"status":"lost"}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'status' should be one of [shipped, delivered, cancelled]"),
+		},
+		{
+			name:           "Paid without a payment",
+			payload:        `{"status":"paid"}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'status' should be one of [shipped, delivered, cancelled]"),
+		},
+		{
+			name:           "Refunded without a refund",
+			payload:        `{"status":"refunded"}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'status' should be one of [shipped, delivered, cancelled]"),
+		},
+		{
+			name:           "Pending to shipped",
+			payload:        `{"status":"shipped"}`,
+			current:        order.StatusPending,
+			expectedOutput: echo.NewHTTPError(http.StatusConflict, "cannot change order status to 'shipped'"),
+		},
+		{
+			name:           "Delivered to cancelled",
+			payload:        `{"status":"cancelled"}`,
+			current:        order.StatusDelivered,
+			expectedOutput: echo.NewHTTPError(http.StatusConflict, "cannot change order status to 'cancelled'"),
+		},
+		{
+			name:           "Updated concurrently",
+			payload:        `{"status":"shipped"}`,
+			current:        order.StatusPaid,
+			next:           order.StatusShipped,
+			serviceReturn:  order.ErrInvalidTransition,
+			expectedOutput: echo.NewHTTPError(http.StatusConflict, "cannot change order status to 'shipped'"),
+		},
+		{
+			name:           "Internal server error",
+			payload:        `{"status":"shipped"}`,
+			current:        order.StatusPaid,
+			next:           order.StatusShipped,
+			serviceReturn:  errors.New("internal server error"),
+			expectedOutput: echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodPatch, "/orders/:id", strings.NewReader(test.payload))
+
+			mockRepository := new(MockOrderRepository)
+			mockRepository.On("GetOrderById", ctx.Request().Context(), "1234").Return(newTestOrder(test.current), nil).Once()
+			mockRepository.On("UpdateOrderStatus", ctx.Request().Context(), "1234", test.current, test.next).Return(test.serviceReturn)
+			mockRepository.On("GetOrderById", ctx.Request().Context(), "1234").Return(newTestOrder(test.next), nil).Once()
+			h := handler{orderService: order.NewOrderService(mockRepository)}
+
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("1234")
+			err := h.updateOrderStatus(ctx)
+
+			if err != nil {
+				assert.Equal(t, test.expectedOutput, err)
+				if test.next == "" {
+					mockRepository.AssertNotCalled(t, "UpdateOrderStatus", ctx.Request().Context(), "1234", test.current, test.next)
+				}
+				return
+			}
+
+			assert.Equal(t, test.expectedStatusCode, rec.Code)
+			assert.Equal(t, test.expectedOutput, strings.TrimSpace(rec.Body.String()))
+			mockRepository.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetOrders(t *testing.T) {
+	orders := []order.Order{newTestOrderWithTotal(order.StatusPaid)}
+
+	tests := []struct {
+		expectedOutput     any
+		name               string
+		query              string
+		serviceReturn      []any
+		expectedOffset     int
+		expectedStatusCode int
+	}{
+		{
+			name:          "Success",
+			serviceReturn: []any{[]order.Order{newTestOrder(order.StatusPaid)}, 11, nil},
+			expectedOutput: newTestJson(t, map[string]any{
+				"orders":    orders,
+				"total":     11,
+				"page":      1,
+				"page_size": 10,
+				"pages":     2,
+				"next":      "/orders?page=2",
+				"prev":      nil,
+			}),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Success page",
+			query:          "?page=2",
+			serviceReturn:  []any{[]order.Order{newTestOrder(order.StatusPaid)}, 11, nil},
+			expectedOffset: 10,
+			expectedOutput: newTestJson(t, map[string]any{
+				"orders":    orders,
+				"total":     11,
+				"page":      2,
+				"page_size": 10,
+				"pages":     2,
+				"next":      nil,
+				"prev":      "/orders?page=1",
+			}),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Invalid page",
+			query:          "?page=j",
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "invalid value for 'page'"),
+		},
+		{
+			name:           "Internal server error",
+			serviceReturn:  []any{[]order.Order(nil), 0, errors.New("internal server error")},
+			expectedOutput: echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodGet, "/orders"+test.query, nil)
+
+			mockRepository := new(MockOrderRepository)
+			mockRepository.On("GetOrders", ctx.Request().Context(), 10, test.expectedOffset).Return(test.serviceReturn...)
+			h := handler{orderService: order.NewOrderService(mockRepository)}
+
+			err := h.getOrders(ctx)
+
+			if err != nil {
+				assert.Equal(t, test.expectedOutput, err)
+				return
+			}
+
+			assert.Equal(t, test.expectedStatusCode, rec.Code)
+			assert.Equal(t, test.expectedOutput, strings.TrimSpace(rec.Body.String()))
+			mockRepository.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/cativovo/bookstore/internal/order"
+	"github.com/cativovo/bookstore/internal/payment"
+	"github.com/labstack/echo/v4"
+)
+
+type payloadPay struct {
+	CardNumber string `json:"card_number" validate:"required"`
+}
+
+func (h *handler) payOrder(ctx echo.Context) error {
+	var payload payloadPay
+	if err := ctx.Bind(&payload); err != nil {
+		return getBindErr(err)
+	}
+
+	if err := ctx.Validate(&payload); err != nil {
+		return err
+	}
+
+	p, err := h.paymentService.Pay(ctx.Request().Context(), ctx.Param("id"), payload.CardNumber)
+	if err != nil {
+		if errors.Is(err, order.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "order not found")
+		}
+		if errors.Is(err, order.ErrInvalidTransition) {
+			return echo.NewHTTPError(http.StatusConflict, "order cannot be paid")
+		}
+		if errors.Is(err, payment.ErrDeclined) {
+			return echo.NewHTTPError(http.StatusPaymentRequired, "payment declined")
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.JSON(http.StatusCreated, p)
+}
+
+func (h *handler) refundOrder(ctx echo.Context) error {
+	p, err := h.paymentService.Refund(ctx.Request().Context(), ctx.Param("id"))
+	if err != nil {
+		if errors.Is(err, order.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "order not found")
+		}
+		if errors.Is(err, order.ErrInvalidTransition) {
+			return echo.NewHTTPError(http.StatusConflict, "order cannot be refunded")
+		}
+		if errors.Is(err, payment.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusConflict, "order has no captured payment")
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.JSON(http.StatusOK, p)
+}
+
+func (h *handler) paymentWebhook(ctx echo.Context) error {
+	// the signature is computed over the raw body so it can't be bound
+	payload, err := io.ReadAll(ctx.Request().Body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, msgInvalidPayload)
+	}
+
+	err = h.paymentService.HandleWebhook(ctx.Request().Context(), payload, ctx.Request().Header)
+	if err != nil {
+		if errors.Is(err, payment.ErrInvalidSignature) {
+			return echo.NewHTTPError(http.StatusUnauthorized, "invalid signature")
+		}
+		if errors.Is(err, payment.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "payment not found")
+		}
+		if errors.Is(err, order.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "order not found")
+		}
+
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return echo.NewHTTPError(http.StatusBadRequest, msgInvalidPayload)
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,477 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cativovo/bookstore/internal/order"
+	"github.com/cativovo/bookstore/internal/payment"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPaymentRepository struct {
+	mock.Mock
+}
+
+func (m *MockPaymentRepository) CreatePayment(ctx context.Context, p payment.Payment) (payment.Payment, error) {
+	args := m.Called(ctx, p)
+	return args.Get(0).(payment.Payment), args.Error(1)
+}
+
+func (m *MockPaymentRepository) AuthorizePayment(ctx context.Context, id string, providerPaymentId string) (payment.Payment, error) {
+	args := m.Called(ctx, id, providerPaymentId)
+	return args.Get(0).(payment.Payment), args.Error(1)
+}
+
+func (m *MockPaymentRepository) UpdatePaymentStatus(ctx context.Context, id string, current payment.Status, next payment.Status) (payment.Payment, error) {
+	args := m.Called(ctx, id, current, next)
+	return args.Get(0).(payment.Payment), args.Error(1)
+}
+
+func (m *MockPaymentRepository) GetPaymentByOrderId(ctx context.Context, orderId string, status payment.Status) (payment.Payment, error) {
+	args := m.Called(ctx, orderId, status)
+	return args.Get(0).(payment.Payment), args.Error(1)
+}
+
+func (m *MockPaymentRepository) GetPaymentByProviderPaymentId(ctx context.Context, providerPaymentId string) (payment.Payment, error) {
+	args := m.Called(ctx, providerPaymentId)
+	return args.Get(0).(payment.Payment), args.Error(1)
+}
+
+func newTestPayment(status payment.Status) payment.Payment {
+	return payment.Payment{
+		Id:                "3456",
+		OrderId:           "1234",
+		ProviderPaymentId: "ch_1",
+		Status:            status,
+		Currency:          "USD",
+		Amount:            3030,
+		CreatedAt:         time.Date(2024, 4, 16, 0, 0, 0, 0, time.UTC),
+		UpdatedAt:         time.Date(2024, 4, 16, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestPayOrder(t *testing.T) {
+	isPending := func(p payment.Payment) bool {
+		return p.OrderId == "1234" && p.Status == payment.StatusPending && p.Amount == 3030 && p.Currency == "USD"
+	}
+
+	tests := []struct {
+		name               string
+		payload            string
+		setupMock          func(ctx context.Context, o *MockOrderRepository, p *MockPaymentRepository)
+		expectedOutput     any
+		expectedStatusCode int
+	}{
+		{
+			name:    "Success",
+			payload: `{"card_number":"4242424242424242"}`,
+			setupMock: func(ctx context.Context, o *MockOrderRepository, p *MockPaymentRepository) {
+				o.On("GetOrderById", ctx, "1234").Return(newTestOrder(order.StatusPending), nil).Twice()
+				o.On("UpdateOrderStatus", ctx, "1234", order.StatusPending, order.StatusPaid).Return(nil)
+				o.On("GetOrderById", ctx, "1234").Return(newTestOrder(order.StatusPaid), nil).Once()
+				p.On("CreatePayment", ctx, mock.MatchedBy(isPending)).Return(newTestPayment(payment.StatusPending), nil)
+				p.On("AuthorizePayment", ctx, "3456", mock.Anything).Return(newTestPayment(payment.StatusAuthorized), nil)
+				p.On("UpdatePaymentStatus", ctx, "3456", payment.StatusAuthorized, payment.StatusCaptured).Return(newTestPayment(payment.StatusCaptured), nil)
+			},
+			expectedOutput:     newTestJson(t, newTestPayment(payment.StatusCaptured)),
+			expectedStatusCode: http.StatusCreated,
+		},
+		{
+			name:    "Paid by the webhook",
+			payload: `{"card_number":"4242424242424242"}`,
+			setupMock: func(ctx context.Context, o *MockOrderRepository, p *MockPaymentRepository) {
+				o.On("GetOrderById", ctx, "1234").Return(newTestOrder(order.StatusPending), nil).Once()
+				o.On("GetOrderById", ctx, "1234").Return(newTestOrder(order.StatusPaid), nil)
+				p.On("CreatePayment", ctx, mock.MatchedBy(isPending)).Return(newTestPayment(payment.StatusPending), nil)
+				p.On("AuthorizePayment", ctx, "3456", mock.Anything).Return(newTestPayment(payment.StatusAuthorized), nil)
+				p.On("UpdatePaymentStatus", ctx, "3456", payment.StatusAuthorized, payment.StatusCaptured).Return(payment.Payment{}, payment.ErrInvalidTransition)
+				p.On("GetPaymentByProviderPaymentId", ctx, mock.Anything).Return(newTestPayment(payment.StatusCaptured), nil)
+			},
+			expectedOutput:     newTestJson(t, newTestPayment(payment.StatusCaptured)),
+			expectedStatusCode: http.StatusCreated,
+		},
+		{
+			name:           "Empty json",
+			payload:        `{}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'card_number' is required"),
+		},
+		{
+			name:    "Declined",
+			payload: `{"card_number":"4000000000000002"}`,
+			setupMock: func(ctx context.Context, o *MockOrderRepository, p *MockPaymentRepository) {
+				o.On("GetOrderById", ctx, "1234").Return(newTestOrder(order.StatusPending), nil)
+				p.On("CreatePayment", ctx, mock.MatchedBy(isPending)).Return(newTestPayment(payment.StatusPending), nil)
+				p.On("UpdatePaymentStatus", ctx, "3456", payment.StatusPending, payment.StatusFailed).Return(newTestPayment(payment.StatusFailed), nil)
+			},
+			expectedOutput: echo.NewHTTPError(http.StatusPaymentRequired, "payment declined"),
+		},
+		{
+			name:    "Insufficient funds",
+			payload: `{"card_number":"4000000000009995"}`,
+			setupMock: func(ctx context.Context, o *MockOrderRepository, p *MockPaymentRepository) {
+				o.On("GetOrderById", ctx, "1234").Return(newTestOrder(order.StatusPending), nil)
+				p.On("CreatePayment", ctx, mock.MatchedBy(isPending)).Return(newTestPayment(payment.StatusPending), nil)
+				p.On("UpdatePaymentStatus", ctx, "3456", payment.StatusPending, payment.StatusFailed).Return(newTestPayment(payment.StatusFailed), nil)
+			},
+			expectedOutput: echo.NewHTTPError(http.StatusPaymentRequired, "payment declined"),
+		},
+		{
+			name:    "Already paid",
+			payload: `{"card_number":"4242424242424242"}`,
+			setupMock: func(ctx context.Context, o *MockOrderRepository, p *MockPaymentRepository) {
+				o.On("GetOrderById", ctx, "1234").Return(newTestOrder(order.StatusPaid), nil)
+			},
+			expectedOutput: echo.NewHTTPError(http.StatusConflict, "order cannot be paid"),
+		},
+		{
+			name:    "Payment in progress",
+			payload: `{"card_number":"4242424242424242"}`,
+			setupMock: func(ctx context.Context, o *MockOrderRepository, p *MockPaymentRepository) {
+				o.On("GetOrderById", ctx, "1234").Return(newTestOrder(order.StatusPending), nil)
+				p.On("CreatePayment", ctx, mock.MatchedBy(isPending)).Return(payment.Payment{}, payment.ErrAlreadyExists)
+			},
+			expectedOutput: echo.NewHTTPError(http.StatusConflict, "order cannot be paid"),
+		},
+		{
+			name:    "Cancelled while paying",
+			payload: `{"card_number":"4242424242424242"}`,
+			setupMock: func(ctx context.Context, o *MockOrderRepository, p *MockPaymentRepository) {
+				o.On("GetOrderById", ctx, "1234").Return(newTestOrder(order.StatusPending), nil).Once()
+				o.On("GetOrderById", ctx, "1234").Return(newTestOrder(order.StatusCancelled), nil)
+				p.On("CreatePayment", ctx, mock.MatchedBy(isPending)).Return(newTestPayment(payment.StatusPending), nil)
+				p.On("AuthorizePayment", ctx, "3456", mock.Anything).Return(newTestPayment(payment.StatusAuthorized), nil)
+				p.On("UpdatePaymentStatus", ctx, "3456", payment.StatusAuthorized, payment.StatusCaptured).Return(newTestPayment(payment.StatusCaptured), nil)
+				p.On("UpdatePaymentStatus", ctx, "3456", payment.StatusCaptured, payment.StatusRefunded).Return(newTestPayment(payment.StatusRefunded), nil)
+			},
+			expectedOutput: echo.NewHTTPError(http.StatusConflict, "order cannot be paid"),
+		},
+		{
+			name:    "Authorization not saved",
+			payload: `{"card_number":"4242424242424242"}`,
+			setupMock: func(ctx context.Context, o *MockOrderRepository, p *MockPaymentRepository) {
+				o.On("GetOrderById", ctx, "1234").Return(newTestOrder(order.StatusPending), nil)
+				p.On("CreatePayment", ctx, mock.MatchedBy(isPending)).Return(newTestPayment(payment.StatusPending), nil)
+				p.On("AuthorizePayment", ctx, "3456", mock.Anything).Return(payment.Payment{}, errors.New("internal server error"))
+				p.On("UpdatePaymentStatus", ctx, "3456", payment.StatusPending, payment.StatusFailed).Return(newTestPayment(payment.StatusFailed), nil)
+			},
+			expectedOutput: echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr),
+		},
+		{
+			name:    "Order not found",
+			payload: `{"card_number":"4242424242424242"}`,
+			setupMock: func(ctx context.Context, o *MockOrderRepository, p *MockPaymentRepository) {
+				o.On("GetOrderById", ctx, "1234").Return(order.Order{}, order.ErrNotFound)
+			},
+			expectedOutput: echo.NewHTTPError(http.StatusNotFound, "order not found"),
+		},
+		{
+			name:    "Internal server error",
+			payload: `{"card_number":"4242424242424242"}`,
+			setupMock: func(ctx context.Context, o *MockOrderRepository, p *MockPaymentRepository) {
+				o.On("GetOrderById", ctx, "1234").Return(order.Order{}, errors.New("internal server error"))
+			},
+			expectedOutput: echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodPost, "/orders/:id/pay", strings.NewReader(test.payload))
+
+			mockOrderRepository := new(MockOrderRepository)
+			mockPaymentRepository := new(MockPaymentRepository)
+			if test.setupMock != nil {
+				test.setupMock(ctx.Request().Context(), mockOrderRepository, mockPaymentRepository)
+			}
+
+			orderService := order.NewOrderService(mockOrderRepository)
+			h := handler{
+				paymentService: payment.NewPaymentService(mockPaymentRepository, payment.NewFakeProvider("secret", ""), orderService),
+			}
+
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("1234")
+			err := h.payOrder(ctx)
+
+			mockPaymentRepository.AssertExpectations(t)
+			mockOrderRepository.AssertExpectations(t)
+
+			if err != nil {
+				assert.Equal(t, test.expectedOutput, err)
+				return
+			}
+
+			assert.Equal(t, test.expectedStatusCode, rec.Code)
+			assert.Equal(t, test.expectedOutput, strings.TrimSpace(rec.Body.String()))
+		})
+	}
+}
+
+func TestRefundOrder(t *testing.T) {
+	provider := payment.NewFakeProvider("secret", "")
+
+	// the fake provider only refunds the charges it has captured
+	charge, err := provider.Authorize(context.Background(), payment.AuthorizeRequest{
+		Reference:  "1234",
+		CardNumber: payment.CardApproved,
+		Currency:   "USD",
+		Amount:     3030,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := provider.Capture(context.Background(), charge.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	withCharge := func(status payment.Status) payment.Payment {
+		p := newTestPayment(status)
+		p.ProviderPaymentId = charge.Id
+		return p
+	}
+
+	refundedJson, err := json.Marshal(withCharge(payment.StatusRefunded))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name               string
+		setupMock          func(ctx context.Context, o *MockOrderRepository, p *MockPaymentRepository)
+		expectedOutput     any
+		expectedStatusCode int
+	}{
+		{
+			name: "Success",
+			setupMock: func(ctx context.Context, o *MockOrderRepository, p *MockPaymentRepository) {
+				o.On("GetOrderById", ctx, "1234").Return(newTestOrder(order.StatusPaid), nil).Twice()
+				o.On("UpdateOrderStatus", ctx, "1234", order.StatusPaid, order.StatusRefunded).Return(nil)
+				o.On("GetOrderById", ctx, "1234").Return(newTestOrder(order.StatusRefunded), nil).Once()
+				p.On("GetPaymentByOrderId", ctx, "1234", payment.StatusCaptured).Return(withCharge(payment.StatusCaptured), nil)
+				p.On("UpdatePaymentStatus", ctx, "3456", payment.StatusCaptured, payment.StatusRefunding).Return(withCharge(payment.StatusRefunding), nil)
+				p.On("UpdatePaymentStatus", ctx, "3456", payment.StatusRefunding, payment.StatusRefunded).Return(withCharge(payment.StatusRefunded), nil)
+			},
+			expectedOutput:     string(refundedJson),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name: "Refund in progress",
+			setupMock: func(ctx context.Context, o *MockOrderRepository, p *MockPaymentRepository) {
+				o.On("GetOrderById", ctx, "1234").Return(newTestOrder(order.StatusPaid), nil)
+				p.On("GetPaymentByOrderId", ctx, "1234", payment.StatusCaptured).Return(withCharge(payment.StatusCaptured), nil)
+				p.On("UpdatePaymentStatus", ctx, "3456", payment.StatusCaptured, payment.StatusRefunding).Return(payment.Payment{}, payment.ErrInvalidTransition)
+			},
+			expectedOutput: echo.NewHTTPError(http.StatusConflict, "order cannot be refunded"),
+		},
+		{
+			name: "No captured payment",
+			setupMock: func(ctx context.Context, o *MockOrderRepository, p *MockPaymentRepository) {
+				o.On("GetOrderById", ctx, "1234").Return(newTestOrder(order.StatusPaid), nil)
+				p.On("GetPaymentByOrderId", ctx, "1234", payment.StatusCaptured).Return(payment.Payment{}, payment.ErrNotFound)
+			},
+			expectedOutput: echo.NewHTTPError(http.StatusConflict, "order has no captured payment"),
+		},
+		{
+			name: "Pending order",
+			setupMock: func(ctx context.Context, o *MockOrderRepository, p *MockPaymentRepository) {
+				o.On("GetOrderById", ctx, "1234").Return(newTestOrder(order.StatusPending), nil)
+			},
+			expectedOutput: echo.NewHTTPError(http.StatusConflict, "order cannot be refunded"),
+		},
+		{
+			name: "Unknown charge",
+			setupMock: func(ctx context.Context, o *MockOrderRepository, p *MockPaymentRepository) {
+				o.On("GetOrderById", ctx, "1234").Return(newTestOrder(order.StatusPaid), nil)
+				p.On("GetPaymentByOrderId", ctx, "1234", payment.StatusCaptured).Return(newTestPayment(payment.StatusCaptured), nil)
+				p.On("UpdatePaymentStatus", ctx, "3456", payment.StatusCaptured, payment.StatusRefunding).Return(newTestPayment(payment.StatusRefunding), nil)
+				p.On("UpdatePaymentStatus", ctx, "3456", payment.StatusRefunding, payment.StatusCaptured).Return(newTestPayment(payment.StatusCaptured), nil)
+			},
+			expectedOutput: echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodPost, "/orders/:id/refund", nil)
+
+			mockOrderRepository := new(MockOrderRepository)
+			mockPaymentRepository := new(MockPaymentRepository)
+			test.setupMock(ctx.Request().Context(), mockOrderRepository, mockPaymentRepository)
+
+			h := handler{
+				paymentService: payment.NewPaymentService(mockPaymentRepository, provider, order.NewOrderService(mockOrderRepository)),
+			}
+
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("1234")
+			err := h.refundOrder(ctx)
+
+			mockPaymentRepository.AssertExpectations(t)
+			mockOrderRepository.AssertExpectations(t)
+
+			if err != nil {
+				assert.Equal(t, test.expectedOutput, err)
+				return
+			}
+
+			assert.Equal(t, test.expectedStatusCode, rec.Code)
+			assert.Equal(t, test.expectedOutput, strings.TrimSpace(rec.Body.String()))
+		})
+	}
+}
+
+func TestPaymentWebhook(t *testing.T) {
+	provider := payment.NewFakeProvider("secret", "")
+
+	captured := `{"type":"charge.captured","charge":{"id":"ch_1","status":"captured","currency":"USD","amount":3030}}`
+
+	tests := []struct {
+		name               string
+		payload            string
+		signature          string
+		expectedOutput     any
+		paymentStatus      payment.Status
+		orderStatus        order.Status
+		paymentErr         error
+		expectedStatusCode int
+	}{
+		{
+			name:               "Success",
+			payload:            captured,
+			signature:          provider.Sign([]byte(captured)),
+			paymentStatus:      payment.StatusAuthorized,
+			orderStatus:        order.StatusPending,
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name:               "Already applied",
+			payload:            captured,
+			signature:          provider.Sign([]byte(captured)),
+			paymentStatus:      payment.StatusCaptured,
+			orderStatus:        order.StatusPaid,
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name:               "Capture of a refunded payment",
+			payload:            captured,
+			signature:          provider.Sign([]byte(captured)),
+			paymentStatus:      payment.StatusRefunded,
+			orderStatus:        order.StatusPending,
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name:           "Invalid signature",
+			payload:        captured,
+			signature:      payment.NewFakeProvider("other secret", "").Sign([]byte(captured)),
+			expectedOutput: echo.NewHTTPError(http.StatusUnauthorized, "invalid signature"),
+		},
+		{
+			name:           "Missing signature",
+			payload:        captured,
+			expectedOutput: echo.NewHTTPError(http.StatusUnauthorized, "invalid signature"),
+		},
+		{
+			name:           "Payment not found",
+			payload:        captured,
+			signature:      provider.Sign([]byte(captured)),
+			paymentErr:     payment.ErrNotFound,
+			expectedOutput: echo.NewHTTPError(http.StatusNotFound, "payment not found"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodPost, "/payments/webhook", strings.NewReader(test.payload))
+			if test.signature != "" {
+				ctx.Request().Header.Set(payment.FakeSignatureHeader, test.signature)
+			}
+
+			mockOrderRepository := new(MockOrderRepository)
+			mockOrderRepository.On("GetOrderById", ctx.Request().Context(), "1234").Return(newTestOrder(test.orderStatus), nil).Twice()
+			mockOrderRepository.On("UpdateOrderStatus", ctx.Request().Context(), "1234", order.StatusPending, order.StatusPaid).Return(nil)
+			mockOrderRepository.On("GetOrderById", ctx.Request().Context(), "1234").Return(newTestOrder(order.StatusPaid), nil).Once()
+
+			mockPaymentRepository := new(MockPaymentRepository)
+			mockPaymentRepository.On("GetPaymentByProviderPaymentId", ctx.Request().Context(), "ch_1").Return(newTestPayment(test.paymentStatus), test.paymentErr)
+			mockPaymentRepository.On("UpdatePaymentStatus", ctx.Request().Context(), "3456", payment.StatusAuthorized, payment.StatusCaptured).Return(newTestPayment(payment.StatusCaptured), nil)
+
+			orderService := order.NewOrderService(mockOrderRepository)
+			h := handler{
+				paymentService: payment.NewPaymentService(mockPaymentRepository, provider, orderService),
+			}
+
+			err := h.paymentWebhook(ctx)
+
+			if err != nil {
+				assert.Equal(t, test.expectedOutput, err)
+				mockPaymentRepository.AssertNotCalled(t, "UpdatePaymentStatus", ctx.Request().Context(), "3456", payment.StatusAuthorized, payment.StatusCaptured)
+				return
+			}
+
+			assert.Equal(t, test.expectedStatusCode, rec.Code)
+
+			if test.paymentStatus != payment.StatusAuthorized {
+				mockPaymentRepository.AssertNotCalled(t, "UpdatePaymentStatus", ctx.Request().Context(), "3456", payment.StatusAuthorized, payment.StatusCaptured)
+				mockOrderRepository.AssertNotCalled(t, "UpdateOrderStatus", ctx.Request().Context(), "1234", order.StatusPending, order.StatusPaid)
+				return
+			}
+
+			mockPaymentRepository.AssertExpectations(t)
+			mockOrderRepository.AssertExpectations(t)
+		})
+	}
+}
+
+func TestFakeProviderWebhook(t *testing.T) {
+	received := make(chan struct{})
+
+	mockPaymentRepository := new(MockPaymentRepository)
+	mockPaymentRepository.On("GetPaymentByProviderPaymentId", mock.Anything, mock.Anything).Return(newTestPayment(payment.StatusCaptured), nil).Run(func(args mock.Arguments) {
+		close(received)
+	})
+
+	mockOrderRepository := new(MockOrderRepository)
+	mockOrderRepository.On("GetOrderById", mock.Anything, "1234").Return(newTestOrder(order.StatusPaid), nil)
+
+	webhook := echo.New()
+	srv := httptest.NewServer(webhook)
+	defer srv.Close()
+
+	provider := payment.NewFakeProvider("secret", srv.URL+"/payments/webhook")
+	h := handler{
+		paymentService: payment.NewPaymentService(mockPaymentRepository, provider, order.NewOrderService(mockOrderRepository)),
+	}
+	webhook.POST("/payments/webhook", h.paymentWebhook)
+
+	charge, err := provider.Authorize(context.Background(), payment.AuthorizeRequest{
+		Reference:  "1234",
+		CardNumber: payment.CardApproved,
+		Currency:   "USD",
+		Amount:     3030,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := provider.Capture(context.Background(), charge.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-received:
+	case <-time.After(time.Second * 5):
+		t.Fatal("webhook was not received")
+	}
+
+	mockPaymentRepository.AssertCalled(t, "GetPaymentByProviderPaymentId", mock.Anything, charge.Id)
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cativovo/bookstore/internal/book"
+	"github.com/cativovo/bookstore/internal/review"
+	"github.com/cativovo/bookstore/internal/user"
+	"github.com/labstack/echo/v4"
+)
+
+func (h *handler) getReviews(ctx echo.Context) error {
+	p, err := h.bindPagination(ctx)
+	if err != nil {
+		return err
+	}
+
+	reviews, count, err := h.reviewService.GetReviews(ctx.Request().Context(), ctx.Param("id"), p.limit(), p.offset())
+	if err != nil {
+		if errors.Is(err, book.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "book not found")
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.JSON(http.StatusOK, paginate(ctx, "reviews", reviews, p, count))
+}
+
+type payloadCreateReview struct {
+	Rating int    `json:"rating" validate:"required,gte=1,lte=5"`
+	Body   string `json:"body" validate:"required,max=5000"`
+}
+
+func (h *handler) createReview(ctx echo.Context) error {
+	u, ok := user.FromContext(ctx.Request().Context())
+	if !ok {
+		return unauthorized(ctx, "authentication required")
+	}
+
+	var payload payloadCreateReview
+	if err := ctx.Bind(&payload); err != nil {
+		return getBindErr(err)
+	}
+
+	if err := ctx.Validate(&payload); err != nil {
+		return err
+	}
+
+	r, err := h.reviewService.CreateReview(ctx.Request().Context(), u, ctx.Param("id"), payload.Rating, payload.Body)
+	if err != nil {
+		if errors.Is(err, book.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "book not found")
+		}
+		if errors.Is(err, review.ErrAlreadyExists) {
+			return echo.NewHTTPError(http.StatusConflict, "the book is already reviewed, edit the review instead")
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.JSON(http.StatusCreated, r)
+}
+
+type payloadUpdateReview struct {
+	Rating *int    `json:"rating" validate:"omitempty,gte=1,lte=5"`
+	Body   *string `json:"body" validate:"omitempty,min=1,max=5000"`
+}
+
+func (h *handler) updateReview(ctx echo.Context) error {
+	u, ok := user.FromContext(ctx.Request().Context())
+	if !ok {
+		return unauthorized(ctx, "authentication required")
+	}
+
+	var payload payloadUpdateReview
+	if err := ctx.Bind(&payload); err != nil {
+		return getBindErr(err)
+	}
+
+	if err := ctx.Validate(&payload); err != nil {
+		return err
+	}
+
+	r, err := h.reviewService.UpdateReview(ctx.Request().Context(), u, ctx.Param("id"), review.UpdateReviewOptions{
+		Rating: payload.Rating,
+		Body:   payload.Body,
+	})
+	if err != nil {
+		if errors.Is(err, review.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "review not found")
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.JSON(http.StatusOK, r)
+}
+
+func (h *handler) deleteReview(ctx echo.Context) error {
+	u, ok := user.FromContext(ctx.Request().Context())
+	if !ok {
+		return unauthorized(ctx, "authentication required")
+	}
+
+	if err := h.reviewService.DeleteReview(ctx.Request().Context(), u, ctx.Param("id")); err != nil {
+		if errors.Is(err, review.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "review not found")
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,342 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cativovo/bookstore/internal/book"
+	"github.com/cativovo/bookstore/internal/review"
+	"github.com/cativovo/bookstore/internal/user"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockReviewRepository struct {
+	mock.Mock
+}
+
+func (m *MockReviewRepository) CreateReview(ctx context.Context, r review.Review) (review.Review, error) {
+	args := m.Called(ctx, r)
+	return args.Get(0).(review.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) GetReviews(ctx context.Context, bookId string, limit int, offset int) ([]review.Review, int, error) {
+	args := m.Called(ctx, bookId, limit, offset)
+	return args.Get(0).([]review.Review), args.Int(1), args.Error(2)
+}
+
+func (m *MockReviewRepository) UpdateReview(ctx context.Context, userId string, id string, options review.UpdateReviewOptions) (review.Review, error) {
+	args := m.Called(ctx, userId, id, options)
+	return args.Get(0).(review.Review), args.Error(1)
+}
+
+func (m *MockReviewRepository) DeleteReview(ctx context.Context, userId string, id string) error {
+	args := m.Called(ctx, userId, id)
+	return args.Error(0)
+}
+
+func newTestReview() review.Review {
+	return review.Review{
+		CreatedAt: time.Date(2024, 5, 8, 10, 12, 44, 0, time.UTC),
+		UpdatedAt: time.Date(2024, 5, 8, 10, 12, 44, 0, time.UTC),
+		Id:        "4321",
+		BookId:    "1234",
+		UserId:    testUser.Id,
+		Rating:    4,
+		Body:      "A slow start but worth it",
+	}
+}
+
+func TestGetReviews(t *testing.T) {
+	reviews := []review.Review{newTestReview()}
+	reviews[0].UserName = testUser.Name
+
+	tests := []struct {
+		expectedOutput     any
+		name               string
+		query              string
+		serviceReturn      []any
+		expectedOffset     int
+		expectedStatusCode int
+	}{
+		{
+			name:          "Success",
+			serviceReturn: []any{reviews, 11, nil},
+			expectedOutput: newTestJson(t, map[string]any{
+				"reviews":   reviews,
+				"total":     11,
+				"page":      1,
+				"page_size": 10,
+				"pages":     2,
+				"next":      "/book/1234/reviews?page=2",
+				"prev":      nil,
+			}),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Book not found",
+			serviceReturn:  []any{[]review.Review(nil), 0, book.ErrNotFound},
+			expectedOutput: echo.NewHTTPError(http.StatusNotFound, "book not found"),
+		},
+		{
+			name:           "Internal server error",
+			serviceReturn:  []any{[]review.Review(nil), 0, errors.New("internal server error")},
+			expectedOutput: echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodGet, "/book/1234/reviews"+test.query, nil)
+
+			mockRepository := new(MockReviewRepository)
+			mockRepository.On("GetReviews", ctx.Request().Context(), "1234", 10, test.expectedOffset).Return(test.serviceReturn...)
+			h := handler{reviewService: review.NewReviewService(mockRepository)}
+
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("1234")
+			err := h.getReviews(ctx)
+
+			if err != nil {
+				assert.Equal(t, test.expectedOutput, err)
+				return
+			}
+
+			assert.Equal(t, test.expectedStatusCode, rec.Code)
+			assert.Equal(t, test.expectedOutput, strings.TrimSpace(rec.Body.String()))
+			mockRepository.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCreateReview(t *testing.T) {
+	created := newTestReview()
+	expected := created
+	expected.UserName = testUser.Name
+
+	tests := []struct {
+		name               string
+		user               *user.User
+		payload            string
+		serviceCalled      bool
+		serviceReturn      []any
+		expectedOutput     any
+		expectedStatusCode int
+	}{
+		{
+			name:               "Success",
+			user:               &testUser,
+			payload:            `{"rating":4,"body":"A slow start but worth it"}`,
+			serviceCalled:      true,
+			serviceReturn:      []any{created, nil},
+			expectedOutput:     newTestJson(t, expected),
+			expectedStatusCode: http.StatusCreated,
+		},
+		{
+			name:           "Anonymous",
+			payload:        `{"rating":4,"body":"A slow start but worth it"}`,
+			expectedOutput: echo.NewHTTPError(http.StatusUnauthorized, "authentication required"),
+		},
+		{
+			name:           "Rating above 5",
+			user:           &testUser,
+			payload:        `{"rating":6,"body":"A slow start but worth it"}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'rating' should be less than or equal to 5"),
+		},
+		{
+			name:           "No rating",
+			user:           &testUser,
+			payload:        `{"body":"A slow start but worth it"}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'rating' is required"),
+		},
+		{
+			name:           "No body",
+			user:           &testUser,
+			payload:        `{"rating":4}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'body' is required"),
+		},
+		{
+			name:           "Already reviewed",
+			user:           &testUser,
+			payload:        `{"rating":4,"body":"A slow start but worth it"}`,
+			serviceCalled:  true,
+			serviceReturn:  []any{review.Review{}, review.ErrAlreadyExists},
+			expectedOutput: echo.NewHTTPError(http.StatusConflict, "the book is already reviewed, edit the review instead"),
+		},
+		{
+			name:           "Book not found",
+			user:           &testUser,
+			payload:        `{"rating":4,"body":"A slow start but worth it"}`,
+			serviceCalled:  true,
+			serviceReturn:  []any{review.Review{}, book.ErrNotFound},
+			expectedOutput: echo.NewHTTPError(http.StatusNotFound, "book not found"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodPost, "/book/:id/reviews", strings.NewReader(test.payload))
+			if test.user != nil {
+				ctx.SetRequest(ctx.Request().WithContext(user.NewContext(ctx.Request().Context(), *test.user)))
+			}
+
+			mockRepository := new(MockReviewRepository)
+			expectedArg := review.Review{
+				BookId: "1234",
+				UserId: testUser.Id,
+				Rating: 4,
+				Body:   "A slow start but worth it",
+			}
+			if test.serviceCalled {
+				mockRepository.On("CreateReview", ctx.Request().Context(), expectedArg).Return(test.serviceReturn...)
+			}
+			h := handler{reviewService: review.NewReviewService(mockRepository)}
+
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("1234")
+			err := h.createReview(ctx)
+
+			mockRepository.AssertExpectations(t)
+
+			if err != nil {
+				assert.Equal(t, test.expectedOutput, err)
+				return
+			}
+
+			assert.Equal(t, test.expectedStatusCode, rec.Code)
+			assert.Equal(t, test.expectedOutput, strings.TrimSpace(rec.Body.String()))
+		})
+	}
+}
+
+func TestUpdateReview(t *testing.T) {
+	rating := 2
+	updated := newTestReview()
+	updated.Rating = rating
+	expected := updated
+	expected.UserName = testUser.Name
+
+	tests := []struct {
+		name               string
+		payload            string
+		serviceCalled      bool
+		serviceReturn      []any
+		expectedOutput     any
+		expectedStatusCode int
+	}{
+		{
+			name:               "Success",
+			payload:            `{"rating":2}`,
+			serviceCalled:      true,
+			serviceReturn:      []any{updated, nil},
+			expectedOutput:     newTestJson(t, expected),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Review of another user",
+			payload:        `{"rating":2}`,
+			serviceCalled:  true,
+			serviceReturn:  []any{review.Review{}, review.ErrNotFound},
+			expectedOutput: echo.NewHTTPError(http.StatusNotFound, "review not found"),
+		},
+		{
+			name:           "Rating below 1",
+			payload:        `{"rating":0}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'rating' should be greater than or equal to 1"),
+		},
+		{
+			name:           "Empty body",
+			payload:        `{"body":""}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'body' should have a length of at least 1"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodPatch, "/reviews/:id", strings.NewReader(test.payload))
+			ctx.SetRequest(ctx.Request().WithContext(user.NewContext(ctx.Request().Context(), testUser)))
+
+			mockRepository := new(MockReviewRepository)
+			if test.serviceCalled {
+				mockRepository.On("UpdateReview", ctx.Request().Context(), testUser.Id, "4321", review.UpdateReviewOptions{Rating: &rating}).Return(test.serviceReturn...)
+			}
+			h := handler{reviewService: review.NewReviewService(mockRepository)}
+
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("4321")
+			err := h.updateReview(ctx)
+
+			mockRepository.AssertExpectations(t)
+
+			if err != nil {
+				assert.Equal(t, test.expectedOutput, err)
+				return
+			}
+
+			assert.Equal(t, test.expectedStatusCode, rec.Code)
+			assert.Equal(t, test.expectedOutput, strings.TrimSpace(rec.Body.String()))
+		})
+	}
+}
+
+func TestDeleteReview(t *testing.T) {
+	tests := []struct {
+		name               string
+		user               *user.User
+		serviceCalled      bool
+		serviceReturn      error
+		expectedOutput     any
+		expectedStatusCode int
+	}{
+		{
+			name:               "Success",
+			user:               &testUser,
+			serviceCalled:      true,
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name:           "Review of another user",
+			user:           &testUser,
+			serviceCalled:  true,
+			serviceReturn:  review.ErrNotFound,
+			expectedOutput: echo.NewHTTPError(http.StatusNotFound, "review not found"),
+		},
+		{
+			name:           "Anonymous",
+			expectedOutput: echo.NewHTTPError(http.StatusUnauthorized, "authentication required"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodDelete, "/reviews/:id", nil)
+			if test.user != nil {
+				ctx.SetRequest(ctx.Request().WithContext(user.NewContext(ctx.Request().Context(), *test.user)))
+			}
+
+			mockRepository := new(MockReviewRepository)
+			if test.serviceCalled {
+				mockRepository.On("DeleteReview", ctx.Request().Context(), testUser.Id, "4321").Return(test.serviceReturn)
+			}
+			h := handler{reviewService: review.NewReviewService(mockRepository)}
+
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("4321")
+			err := h.deleteReview(ctx)
+
+			mockRepository.AssertExpectations(t)
+
+			if err != nil {
+				assert.Equal(t, test.expectedOutput, err)
+				return
+			}
+
+			assert.Equal(t, test.expectedStatusCode, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cativovo/bookstore/internal/book"
+	"github.com/labstack/echo/v4"
+)
+
+type payloadCreateSeries struct {
+	Name        string `json:"name" validate:"required,max=255"`
+	Description string `json:"description"`
+}
+
+func (h *handler) createSeries(ctx echo.Context) error {
+	var payload payloadCreateSeries
+	if err := ctx.Bind(&payload); err != nil {
+		return getBindErr(err)
+	}
+
+	if err := ctx.Validate(&payload); err != nil {
+		return err
+	}
+
+	s, err := h.bookService.CreateSeries(ctx.Request().Context(), book.Series{
+		Name:        payload.Name,
+		Description: payload.Description,
+	})
+	if err != nil {
+		if errors.Is(err, book.ErrAlreadyExists) {
+			return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("series '%s' already exists", payload.Name))
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.JSON(http.StatusCreated, s)
+}
+
+// getSeriesById returns the series with a page of its books in reading order
+func (h *handler) getSeriesById(ctx echo.Context) error {
+	currency := ctx.QueryParam("currency")
+
+	p, err := h.bindPagination(ctx)
+	if err != nil {
+		return err
+	}
+
+	s, err := h.bookService.GetSeriesById(ctx.Request().Context(), ctx.Param("id"))
+	if err != nil {
+		if errors.Is(err, book.ErrSeriesNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "series not found")
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	page, err := h.bookService.GetBooks(
+		ctx.Request().Context(),
+		book.GetBooksOptions{
+			Limit:    p.limit(),
+			Offset:   p.offset(),
+			Currency: strings.ToUpper(currency),
+			// the editions of a book share the position
+			Sort: []book.SortKey{
+				{Field: book.SortBySeriesPosition},
+				{Field: book.SortByTitle},
+			},
+			Filter: book.GetBooksFilter{
+				SeriesId: s.Id,
+			},
+		},
+	)
+	if err != nil {
+		if errors.Is(err, book.ErrInvalidCurrency) {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("unsupported currency '%s'", currency))
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	response := paginate(ctx, "books", page.Books, p, page.Count)
+	response["series"] = s
+
+	return ctx.JSON(http.StatusOK, response)
+}
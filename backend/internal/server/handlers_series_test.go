@@ -0,0 +1,185 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cativovo/bookstore/internal/book"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+var testSeries = book.Series{
+	CreatedAt:   time.Date(2024, 5, 14, 9, 10, 36, 0, time.UTC),
+	Id:          "5678",
+	Name:        "Discworld",
+	Description: "A flat world on the back of a turtle",
+	BookCount:   2,
+}
+
+func TestCreateSeries(t *testing.T) {
+	seriesJson, err := json.Marshal(testSeries)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name               string
+		payload            string
+		serviceCalled      bool
+		serviceReturn      []any
+		expectedOutput     any
+		expectedStatusCode int
+	}{
+		{
+			name:               "Success",
+			payload:            `{"name":" Discworld ","description":"A flat world on the back of a turtle"}`,
+			serviceCalled:      true,
+			serviceReturn:      []any{testSeries, nil},
+			expectedOutput:     string(seriesJson),
+			expectedStatusCode: http.StatusCreated,
+		},
+		{
+			name:           "No name",
+			payload:        `{"description":"A flat world on the back of a turtle"}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'name' is required"),
+		},
+		{
+			name:           "Already exists",
+			payload:        `{"name":" Discworld ","description":"A flat world on the back of a turtle"}`,
+			serviceCalled:  true,
+			serviceReturn:  []any{book.Series{}, book.ErrAlreadyExists},
+			expectedOutput: echo.NewHTTPError(http.StatusConflict, "series ' Discworld ' already exists"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodPost, "/series", strings.NewReader(test.payload))
+
+			mockRepository := new(MockBookRepository)
+			if test.serviceCalled {
+				mockRepository.On("CreateSeries", ctx.Request().Context(), book.Series{
+					Name:        "Discworld",
+					Description: "A flat world on the back of a turtle",
+				}).Return(test.serviceReturn...)
+			}
+			h := handler{bookService: book.NewBookService(mockRepository)}
+
+			err := h.createSeries(ctx)
+
+			mockRepository.AssertExpectations(t)
+
+			if err != nil {
+				assert.Equal(t, test.expectedOutput, err)
+				return
+			}
+
+			assert.Equal(t, test.expectedStatusCode, rec.Code)
+			assert.Equal(t, test.expectedOutput, strings.TrimSpace(rec.Body.String()))
+		})
+	}
+}
+
+func TestGetSeriesById(t *testing.T) {
+	firstPosition := 1.0
+	novellaPosition := 1.5
+	books := []book.Book{
+		{
+			Id:             "1234",
+			Title:          "The Colour of Magic",
+			Author:         "Terry Pratchett",
+			Genres:         []string{"Fantasy"},
+			Price:          book.NewMoney(999, book.DefaultCurrency),
+			Series:         &book.BookSeries{Id: testSeries.Id, Name: testSeries.Name},
+			SeriesPosition: &firstPosition,
+		},
+		{
+			Id:             "2345",
+			Title:          "Troll Bridge",
+			Author:         "Terry Pratchett",
+			Genres:         []string{"Fantasy"},
+			Price:          book.NewMoney(499, book.DefaultCurrency),
+			Series:         &book.BookSeries{Id: testSeries.Id, Name: testSeries.Name},
+			SeriesPosition: &novellaPosition,
+		},
+	}
+
+	tests := []struct {
+		expectedOutput     any
+		name               string
+		seriesReturn       []any
+		booksCalled        bool
+		booksReturn        []any
+		expectedStatusCode int
+	}{
+		{
+			name:         "Success",
+			seriesReturn: []any{testSeries, nil},
+			booksCalled:  true,
+			booksReturn:  []any{book.BooksPage{Books: books, Count: 2}, nil},
+			expectedOutput: newTestJson(t, map[string]any{
+				"series":    testSeries,
+				"books":     books,
+				"total":     2,
+				"page":      1,
+				"page_size": 10,
+				"pages":     1,
+				"next":      nil,
+				"prev":      nil,
+			}),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Not found",
+			seriesReturn:   []any{book.Series{}, book.ErrSeriesNotFound},
+			expectedOutput: echo.NewHTTPError(http.StatusNotFound, "series not found"),
+		},
+		{
+			name:           "Internal server error",
+			seriesReturn:   []any{testSeries, nil},
+			booksCalled:    true,
+			booksReturn:    []any{book.BooksPage{}, errors.New("internal server error")},
+			expectedOutput: echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodGet, "/series/5678", nil)
+
+			mockRepository := new(MockBookRepository)
+			mockRepository.On("GetSeriesById", ctx.Request().Context(), "5678").Return(test.seriesReturn...)
+			if test.booksCalled {
+				mockRepository.On("GetBooks", ctx.Request().Context(), book.GetBooksOptions{
+					Currency: book.DefaultCurrency,
+					Limit:    10,
+					Sort: []book.SortKey{
+						{Field: book.SortBySeriesPosition},
+						{Field: book.SortByTitle},
+					},
+					Filter: book.GetBooksFilter{SeriesId: testSeries.Id},
+				}).Return(test.booksReturn...)
+			}
+			h := handler{bookService: book.NewBookService(mockRepository)}
+
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("5678")
+			err := h.getSeriesById(ctx)
+
+			mockRepository.AssertExpectations(t)
+
+			if err != nil {
+				assert.Equal(t, test.expectedOutput, err)
+				return
+			}
+
+			assert.Equal(t, test.expectedStatusCode, rec.Code)
+			assert.Equal(t, test.expectedOutput, strings.TrimSpace(rec.Body.String()))
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/cativovo/bookstore/internal/book"
 	"github.com/labstack/echo/v4"
@@ -22,18 +23,43 @@ type MockBookRepository struct {
 	mock.Mock
 }
 
-func (m *MockBookRepository) GetBooks(ctx context.Context, options book.GetBooksOptions) (books []book.Book, count int, err error) {
+func (m *MockBookRepository) GetBooks(ctx context.Context, options book.GetBooksOptions) (book.BooksPage, error) {
 	args := m.Called(ctx, options)
-	return args.Get(0).([]book.Book), args.Int(1), args.Error(2)
+	return args.Get(0).(book.BooksPage), args.Error(1)
 }
 
-func (m *MockBookRepository) GetBookById(ctx context.Context, id string) (book.Book, error) {
+func (m *MockBookRepository) GetBookById(ctx context.Context, id string, currency string) (book.Book, error) {
 	return book.Book{}, nil
 }
 
-func (m *MockBookRepository) GetGenres(ctx context.Context) ([]string, error) {
+func (m *MockBookRepository) CreateSeries(ctx context.Context, s book.Series) (book.Series, error) {
+	args := m.Called(ctx, s)
+	return args.Get(0).(book.Series), args.Error(1)
+}
+
+func (m *MockBookRepository) GetSeriesById(ctx context.Context, id string) (book.Series, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(book.Series), args.Error(1)
+}
+
+func (m *MockBookRepository) GetBookByIsbn(ctx context.Context, isbn13 string, currency string) (book.Book, error) {
+	args := m.Called(ctx, isbn13, currency)
+	return args.Get(0).(book.Book), args.Error(1)
+}
+
+func (m *MockBookRepository) GetGenres(ctx context.Context) ([]book.Genre, error) {
 	args := m.Called(ctx)
-	return args.Get(0).([]string), args.Error(1)
+	return args.Get(0).([]book.Genre), args.Error(1)
+}
+
+func (m *MockBookRepository) GetAuthors(ctx context.Context, name string, limit int, offset int) ([]book.Author, int, error) {
+	args := m.Called(ctx, name, limit, offset)
+	return args.Get(0).([]book.Author), args.Int(1), args.Error(2)
+}
+
+func (m *MockBookRepository) GetAuthorById(ctx context.Context, id string) (book.Author, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(book.Author), args.Error(1)
 }
 
 func (m *MockBookRepository) CreateGenre(ctx context.Context, name string) error {
@@ -41,8 +67,18 @@ func (m *MockBookRepository) CreateGenre(ctx context.Context, name string) error
 	return args.Error(0)
 }
 
-func (m *MockBookRepository) DeleteGenre(ctx context.Context, name string) error {
-	args := m.Called(ctx, name)
+func (m *MockBookRepository) UpdateGenre(ctx context.Context, id string, name string) (book.Genre, error) {
+	args := m.Called(ctx, id, name)
+	return args.Get(0).(book.Genre), args.Error(1)
+}
+
+func (m *MockBookRepository) MergeGenres(ctx context.Context, sourceId string, targetId string) (book.Genre, error) {
+	args := m.Called(ctx, sourceId, targetId)
+	return args.Get(0).(book.Genre), args.Error(1)
+}
+
+func (m *MockBookRepository) DeleteGenre(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
@@ -51,6 +87,31 @@ func (m *MockBookRepository) CreateBook(ctx context.Context, b book.Book) (book.
 	return args.Get(0).(book.Book), args.Error(1)
 }
 
+func (m *MockBookRepository) CreateBooks(ctx context.Context, books []book.Book, createGenres bool) ([]book.Book, error) {
+	args := m.Called(ctx, books, createGenres)
+	return args.Get(0).([]book.Book), args.Error(1)
+}
+
+func (m *MockBookRepository) UpdateBook(ctx context.Context, id string, options book.UpdateBookOptions) (book.Book, error) {
+	args := m.Called(ctx, id, options)
+	return args.Get(0).(book.Book), args.Error(1)
+}
+
+func (m *MockBookRepository) DeleteBook(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockBookRepository) SetBookPrice(ctx context.Context, id string, price book.Money) error {
+	args := m.Called(ctx, id, price)
+	return args.Error(0)
+}
+
+func (m *MockBookRepository) DeleteBookPrice(ctx context.Context, id string, currency string) error {
+	args := m.Called(ctx, id, currency)
+	return args.Error(0)
+}
+
 var e = echo.New()
 
 func TestMain(m *testing.M) {
@@ -69,6 +130,18 @@ func newEchoContext(t *testing.T, method string, target string, body io.Reader)
 	return ctx, rec
 }
 
+// newTestJson returns the json of the expected response body
+func newTestJson(t *testing.T, v any) string {
+	t.Helper()
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(b)
+}
+
 func TestCreateGenre(t *testing.T) {
 	tests := []struct {
 		name               string
@@ -148,43 +221,43 @@ func TestDeleteGenre(t *testing.T) {
 		name               string
 		expectedOutput     any
 		serviceReturn      error
-		genre              string
+		id                 string
 		expectedServiceArg string
 		expectedStatusCode int
 	}{
 		{
 			name:               "Success",
-			genre:              "horror",
-			expectedServiceArg: "horror",
+			id:                 "1234",
+			expectedServiceArg: "1234",
 			expectedStatusCode: http.StatusNoContent,
 			expectedOutput:     "",
 		},
 		{
 			name:               "Not found",
 			serviceReturn:      book.ErrNotFound,
-			genre:              "notfound",
+			id:                 "notfound",
 			expectedServiceArg: "notfound",
 			expectedOutput:     echo.NewHTTPError(http.StatusNotFound, "genre not found"),
 		},
 		{
 			name:               "Internal server error",
 			serviceReturn:      errors.New("internal server error"),
-			genre:              "horror",
-			expectedServiceArg: "horror",
+			id:                 "1234",
+			expectedServiceArg: "1234",
 			expectedOutput:     echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr),
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			ctx, rec := newEchoContext(t, http.MethodDelete, "/genre/:name", nil)
+			ctx, rec := newEchoContext(t, http.MethodDelete, "/genre/:id", nil)
 
 			mockRepository := new(MockBookRepository)
 			mockRepository.On("DeleteGenre", ctx.Request().Context(), test.expectedServiceArg).Return(test.serviceReturn)
 			h := handler{bookService: book.NewBookService(mockRepository)}
 
-			ctx.SetParamNames("name")
-			ctx.SetParamValues(test.genre)
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(test.id)
 			err := h.deleteGenre(ctx)
 
 			if err != nil {
@@ -208,128 +281,85 @@ func TestDeleteGenre(t *testing.T) {
 	}
 }
 
-func TestCreateBook(t *testing.T) {
-	successBook := book.Book{
-		Id:          "1234",
-		Title:       "this is a title",
-		Author:      "john doe",
-		Description: "this is a description",
-		CoverImage:  "coverimage.com",
-		Genres:      []string{"horror"},
-		Price:       69.0,
-	}
-
-	successBookJson, err := json.Marshal(successBook)
-	if err != nil {
-		t.Fatal(err)
+func TestUpdateGenre(t *testing.T) {
+	successGenre := book.Genre{
+		Id:        "1234",
+		Name:      "horror",
+		BookCount: 69,
 	}
 
-	emptyGenresBook := successBook
-	emptyGenresBook.Genres = []string{}
-	emptyGenresBookJson, err := json.Marshal(emptyGenresBook)
+	successGenreJson, err := json.Marshal(successGenre)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	tests := []struct {
 		name               string
-		payload            string
 		expectedOutput     any
 		serviceReturn      []any
-		expectedServiceArg book.Book
+		payload            string
+		expectedServiceArg string
 		expectedStatusCode int
 	}{
 		{
 			name:               "Success",
-			payload:            `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":69}`,
-			serviceReturn:      []any{successBook, nil},
-			expectedServiceArg: successBook,
-			expectedStatusCode: http.StatusCreated,
-			expectedOutput:     string(successBookJson),
-		},
-		{
-			name:               "Empty genres",
-			payload:            `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":[],"price":69}`,
-			serviceReturn:      []any{emptyGenresBook, nil},
-			expectedServiceArg: emptyGenresBook,
-			expectedStatusCode: http.StatusCreated,
-			expectedOutput:     string(emptyGenresBookJson),
-		},
-		{
-			name:           "Empty title",
-			payload:        `{"title":"","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":69}`,
-			serviceReturn:  []any{},
-			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'title' is required"),
-		},
-		{
-			name:           "Empty author",
-			payload:        `{"title":"this is a title","author":"","description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":69}`,
-			serviceReturn:  []any{},
-			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'author' is required"),
-		},
-		{
-			name:           "No genres",
-			payload:        `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","price":69}`,
-			serviceReturn:  []any{},
-			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'genres' is required"),
-		},
-		{
-			name:           "No price",
-			payload:        `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"]}`,
-			serviceReturn:  []any{},
-			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'price' is required"),
+			payload:            `{"name":"horror"}`,
+			serviceReturn:      []any{successGenre, nil},
+			expectedServiceArg: "horror",
+			expectedStatusCode: http.StatusOK,
+			expectedOutput:     string(successGenreJson),
 		},
 		{
-			name:           "Zero price",
-			payload:        `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"], "price": 0}`,
-			serviceReturn:  []any{},
-			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'price' should be greater than 0"),
+			name:           "Empty name",
+			payload:        `{"name":""}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'name' is required"),
 		},
 		{
-			name:           "Invalid type",
-			payload:        `{"title":69,"author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"], "price": 69}`,
-			serviceReturn:  []any{},
-			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'title' should be string"),
+			name:               "Not found",
+			payload:            `{"name":"horror"}`,
+			serviceReturn:      []any{book.Genre{}, book.ErrNotFound},
+			expectedServiceArg: "horror",
+			expectedOutput:     echo.NewHTTPError(http.StatusNotFound, "genre not found"),
 		},
 		{
-			name:           "Invalid json",
-			payload:        "[]",
-			serviceReturn:  []any{},
-			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, msgInvalidPayload),
+			name:               "Already exists",
+			payload:            `{"name":"horror"}`,
+			serviceReturn:      []any{book.Genre{}, book.ErrAlreadyExists},
+			expectedServiceArg: "horror",
+			expectedOutput:     echo.NewHTTPError(http.StatusBadRequest, "genre 'horror' already exists"),
 		},
 		{
 			name:               "Internal server error",
-			payload:            `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":69}`,
-			serviceReturn:      []any{book.Book{}, errors.New("internal server error")},
-			expectedServiceArg: successBook,
+			payload:            `{"name":"horror"}`,
+			serviceReturn:      []any{book.Genre{}, errors.New("internal server error")},
+			expectedServiceArg: "horror",
 			expectedOutput:     echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr),
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			ctx, rec := newEchoContext(t, http.MethodPost, "/book", strings.NewReader(test.payload))
+			ctx, rec := newEchoContext(t, http.MethodPatch, "/genre/:id", strings.NewReader(test.payload))
 
-			test.expectedServiceArg.Id = ""
 			mockRepository := new(MockBookRepository)
-			mockRepository.On("CreateBook", ctx.Request().Context(), test.expectedServiceArg).Return(test.serviceReturn...)
+			mockRepository.On("UpdateGenre", ctx.Request().Context(), "1234", test.expectedServiceArg).Return(test.serviceReturn...)
 			h := handler{bookService: book.NewBookService(mockRepository)}
 
-			err := h.createBook(ctx)
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("1234")
+			err := h.updateGenre(ctx)
 
 			if err != nil {
 				if !assert.Equal(t, test.expectedOutput, err) {
 					return
 				}
 
-				switch test.expectedOutput.(*echo.HTTPError).Code {
-				case http.StatusBadRequest:
-					mockRepository.AssertNotCalled(t, "CreateBook", test.expectedServiceArg)
+				if len(test.serviceReturn) == 0 {
+					mockRepository.AssertNotCalled(t, "UpdateGenre", "1234", test.expectedServiceArg)
 					return
 				}
 			} else {
 				assert.Equal(t, test.expectedStatusCode, rec.Code)
-				// why use strings.TrimSpace - https://stackoverflow.com/questions/36319918/why-does-json-encoder-add-an-extra-line/36320146#36320146
 				assert.Equal(t, test.expectedOutput, strings.TrimSpace(rec.Body.String()))
 			}
 
@@ -338,61 +368,88 @@ func TestCreateBook(t *testing.T) {
 	}
 }
 
-func TestGetGenres(t *testing.T) {
-	data, err := os.ReadFile("../../testdata/genres.json")
-	if err != nil {
-		t.Fatal(err)
-	}
-	var testdata []string
-
-	if err := json.Unmarshal(data, &testdata); err != nil {
-		t.Fatal(err)
+func TestMergeGenres(t *testing.T) {
+	successGenre := book.Genre{
+		Id:        "5678",
+		Name:      "Science Fiction",
+		BookCount: 69,
 	}
 
-	testdataBytes, err := json.Marshal(testdata)
+	successGenreJson, err := json.Marshal(successGenre)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	testdataJson := string(testdataBytes)
-
 	tests := []struct {
 		name               string
 		expectedOutput     any
 		serviceReturn      []any
+		payload            string
+		expectedServiceArg string
 		expectedStatusCode int
 	}{
 		{
 			name:               "Success",
-			serviceReturn:      []any{testdata, nil},
-			expectedOutput:     testdataJson,
+			payload:            `{"target_id":"5678"}`,
+			serviceReturn:      []any{successGenre, nil},
+			expectedServiceArg: "5678",
 			expectedStatusCode: http.StatusOK,
+			expectedOutput:     string(successGenreJson),
 		},
 		{
-			name:           "Internal server error",
-			serviceReturn:  []any{testdata, errors.New("internal server error")},
-			expectedOutput: echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr), expectedStatusCode: http.StatusOK,
+			name:           "Empty target",
+			payload:        `{}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'target_id' is required"),
+		},
+		{
+			name:               "Same genre",
+			payload:            `{"target_id":"1234"}`,
+			serviceReturn:      []any{book.Genre{}, book.ErrMergeIntoItself},
+			expectedServiceArg: "1234",
+			expectedOutput:     echo.NewHTTPError(http.StatusBadRequest, "cannot merge a genre into itself"),
+		},
+		{
+			name:               "Source not found",
+			payload:            `{"target_id":"5678"}`,
+			serviceReturn:      []any{book.Genre{}, book.ErrNotFound},
+			expectedServiceArg: "5678",
+			expectedOutput:     echo.NewHTTPError(http.StatusNotFound, "genre not found"),
+		},
+		{
+			name:               "Target not found",
+			payload:            `{"target_id":"5678"}`,
+			serviceReturn:      []any{book.Genre{}, book.ErrInvalidGenre},
+			expectedServiceArg: "5678",
+			expectedOutput:     echo.NewHTTPError(http.StatusBadRequest, "invalid target genre"),
+		},
+		{
+			name:               "Internal server error",
+			payload:            `{"target_id":"5678"}`,
+			serviceReturn:      []any{book.Genre{}, errors.New("internal server error")},
+			expectedServiceArg: "5678",
+			expectedOutput:     echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr),
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			ctx, rec := newEchoContext(t, http.MethodGet, "/genres", nil)
+			ctx, rec := newEchoContext(t, http.MethodPost, "/genre/:id/merge", strings.NewReader(test.payload))
 
 			mockRepository := new(MockBookRepository)
-			mockRepository.On("GetGenres", ctx.Request().Context()).Return(test.serviceReturn...)
+			mockRepository.On("MergeGenres", ctx.Request().Context(), "1234", test.expectedServiceArg).Return(test.serviceReturn...)
 			h := handler{bookService: book.NewBookService(mockRepository)}
 
-			err := h.getGenres(ctx)
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("1234")
+			err := h.mergeGenres(ctx)
 
 			if err != nil {
 				if !assert.Equal(t, test.expectedOutput, err) {
 					return
 				}
 
-				switch test.expectedOutput.(*echo.HTTPError).Code {
-				case http.StatusBadRequest:
-					mockRepository.AssertNotCalled(t, "GetGenres")
+				if len(test.serviceReturn) == 0 {
+					mockRepository.AssertNotCalled(t, "MergeGenres", "1234", test.expectedServiceArg)
 					return
 				}
 			} else {
@@ -405,128 +462,1380 @@ func TestGetGenres(t *testing.T) {
 	}
 }
 
-func TestGetBooks(t *testing.T) {
-	data, err := os.ReadFile("../../testdata/books.json")
+func TestCreateBook(t *testing.T) {
+	successBook := book.Book{
+		Id:          "1234",
+		Title:       "this is a title",
+		Author:      "john doe",
+		Description: "this is a description",
+		CoverImage:  "coverimage.com",
+		Genres:      []string{"horror"},
+		Price:       book.NewMoney(6900, book.DefaultCurrency),
+	}
+
+	successBookJson, err := json.Marshal(successBook)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	var testdata []book.Book
-
-	if err := json.Unmarshal(data, &testdata); err != nil {
+	emptyGenresBook := successBook
+	emptyGenresBook.Genres = []string{}
+	emptyGenresBookJson, err := json.Marshal(emptyGenresBook)
+	if err != nil {
 		t.Fatal(err)
 	}
 
-	type response struct {
-		Books []book.Book `json:"books"`
-		Pages int         `json:"pages"`
+	// the service trims the names, sets the missing roles and removes the duplicated credits
+	authorsBookArg := successBook
+	authorsBookArg.Author = ""
+	authorsBookArg.Authors = []book.BookAuthor{
+		{Name: "john doe", Role: book.AuthorRoleAuthor},
+		{Name: "jane doe", Role: book.AuthorRoleTranslator},
 	}
 
-	success := response{
-		Books: testdata,
-		Pages: 11,
+	authorsBook := authorsBookArg
+	authorsBook.Author = "john doe"
+	authorsBook.Authors = []book.BookAuthor{
+		{Id: "1", Name: "john doe", Role: book.AuthorRoleAuthor},
+		{Id: "2", Name: "jane doe", Role: book.AuthorRoleTranslator},
 	}
-
-	successBytes, err := json.Marshal(success)
+	authorsBookJson, err := json.Marshal(authorsBook)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	successEmptyBooks := response{
-		Pages: 11,
+	// the ISBN-10 is stored as an ISBN-13
+	pageCount := 123
+	publicationDate := book.NewDate(1987, time.March, 1)
+	metadataBookArg := successBook
+	metadataBookArg.Isbn13 = "9780306406157"
+	metadataBookArg.Publisher = "Plenum Press"
+	metadataBookArg.PublicationDate = &publicationDate
+	metadataBookArg.Edition = "2nd"
+	metadataBookArg.PageCount = &pageCount
+	metadataBookArg.Language = "en-US"
+	metadataBookArg.Format = book.FormatHardcover
+
+	metadataBook := metadataBookArg
+	metadataBook.Isbn10 = "0306406152"
+	metadataBookJson, err := json.Marshal(metadataBook)
+	if err != nil {
+		t.Fatal(err)
 	}
 
-	successEmptyBooksBytes, err := json.Marshal(successEmptyBooks)
+	isbnBookArg := successBook
+	isbnBookArg.Isbn13 = "9780306406157"
+
+	seriesPosition := 2.5
+	seriesBookArg := successBook
+	seriesBookArg.Series = &book.BookSeries{Id: "5678"}
+	seriesBookArg.SeriesPosition = &seriesPosition
+
+	seriesBook := seriesBookArg
+	seriesBook.Series = &book.BookSeries{Id: "5678", Name: "Discworld"}
+	seriesBookJson, err := json.Marshal(seriesBook)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	tests := []struct {
-		expectedOutput     any
 		name               string
-		query              string
+		payload            string
+		expectedOutput     any
 		serviceReturn      []any
-		expectedServiceArg book.GetBooksOptions
+		expectedServiceArg book.Book
 		expectedStatusCode int
 	}{
 		{
-			name:          "Success without query",
-			serviceReturn: []any{success.Books, 101, nil},
-			expectedServiceArg: book.GetBooksOptions{
-				Limit: 10,
-			},
-			expectedOutput:     string(successBytes),
-			expectedStatusCode: http.StatusOK,
+			name:               "Success",
+			payload:            `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":69}`,
+			serviceReturn:      []any{successBook, nil},
+			expectedServiceArg: successBook,
+			expectedStatusCode: http.StatusCreated,
+			expectedOutput:     string(successBookJson),
 		},
 		{
-			name:          "Success page",
-			query:         "?page=1",
-			serviceReturn: []any{success.Books, 101, nil},
-			expectedServiceArg: book.GetBooksOptions{
-				Limit:  10,
-				Offset: 0,
-			},
-			expectedOutput:     string(successBytes),
-			expectedStatusCode: http.StatusOK,
+			name:               "Empty genres",
+			payload:            `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":[],"price":69}`,
+			serviceReturn:      []any{emptyGenresBook, nil},
+			expectedServiceArg: emptyGenresBook,
+			expectedStatusCode: http.StatusCreated,
+			expectedOutput:     string(emptyGenresBookJson),
 		},
 		{
-			name:          "Success exceed pages",
-			query:         "?page=6969",
-			serviceReturn: []any{successEmptyBooks.Books, 101, nil},
-			expectedServiceArg: book.GetBooksOptions{
-				Limit:  10,
-				Offset: 6968 * 10,
-			},
-			expectedOutput:     string(successEmptyBooksBytes),
-			expectedStatusCode: http.StatusOK,
+			name:           "Empty title",
+			payload:        `{"title":"","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":69}`,
+			serviceReturn:  []any{},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'title' is required"),
 		},
 		{
-			name:          "Success desc",
-			query:         "?desc=true",
-			serviceReturn: []any{successEmptyBooks.Books, 101, nil},
-			expectedServiceArg: book.GetBooksOptions{
-				Limit: 10,
-				Desc:  true,
-			},
-			expectedOutput:     string(successEmptyBooksBytes),
-			expectedStatusCode: http.StatusOK,
+			name:           "Empty author",
+			payload:        `{"title":"this is a title","author":"","description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":69}`,
+			serviceReturn:  []any{},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'author' is required without 'authors'"),
 		},
 		{
-			name:          "Success order_by",
-			query:         "?order_by=author",
-			serviceReturn: []any{successEmptyBooks.Books, 101, nil},
-			expectedServiceArg: book.GetBooksOptions{
-				Limit:   10,
-				OrderBy: "author",
-			},
-			expectedOutput:     string(successEmptyBooksBytes),
-			expectedStatusCode: http.StatusOK,
+			name:               "Success authors",
+			payload:            `{"title":"this is a title","authors":[{"name":" john doe "},{"name":"jane doe","role":"translator"},{"name":"John Doe"}],"description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":69}`,
+			serviceReturn:      []any{authorsBook, nil},
+			expectedServiceArg: authorsBookArg,
+			expectedStatusCode: http.StatusCreated,
+			expectedOutput:     string(authorsBookJson),
 		},
 		{
-			name:          "Success filter by title",
-			query:         "?title=Moby%20Dick",
-			serviceReturn: []any{successEmptyBooks.Books, 101, nil},
-			expectedServiceArg: book.GetBooksOptions{
-				Limit: 10,
-				Filter: book.GetBooksFilter{
-					Title: "Moby Dick",
-				},
-			},
-			expectedOutput:     string(successEmptyBooksBytes),
-			expectedStatusCode: http.StatusOK,
+			name:           "Author and authors",
+			payload:        `{"title":"this is a title","author":"john doe","authors":[{"name":"john doe"}],"description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":69}`,
+			serviceReturn:  []any{},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'authors' should not be set with 'author'"),
 		},
 		{
-			name:          "Success filter by author",
-			query:         "?author=doe",
-			serviceReturn: []any{successEmptyBooks.Books, 101, nil},
-			expectedServiceArg: book.GetBooksOptions{
-				Limit: 10,
-				Filter: book.GetBooksFilter{
-					Author: "doe",
-				},
-			},
-			expectedOutput:     string(successEmptyBooksBytes),
-			expectedStatusCode: http.StatusOK,
+			name:           "Authors without an author",
+			payload:        `{"title":"this is a title","authors":[{"name":"jane doe","role":"translator"}],"description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":69}`,
+			serviceReturn:  []any{},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'authors' should credit at least one author"),
+		},
+		{
+			name:           "Invalid author role",
+			payload:        `{"title":"this is a title","authors":[{"name":"john doe","role":"narrator"}],"description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":69}`,
+			serviceReturn:  []any{},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'role' should be one of [author, translator, illustrator, editor]"),
+		},
+		{
+			name:               "Success metadata",
+			payload:            `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":69,"isbn":"0-306-40615-2","publisher":"Plenum Press","publication_date":"1987-03-01","edition":"2nd","page_count":123,"language":"en-US","format":"hardcover"}`,
+			serviceReturn:      []any{metadataBook, nil},
+			expectedServiceArg: metadataBookArg,
+			expectedStatusCode: http.StatusCreated,
+			expectedOutput:     string(metadataBookJson),
+		},
+		{
+			name:               "Success series",
+			payload:            `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":69,"series_id":"5678","series_position":2.5}`,
+			serviceReturn:      []any{seriesBook, nil},
+			expectedServiceArg: seriesBookArg,
+			expectedStatusCode: http.StatusCreated,
+			expectedOutput:     string(seriesBookJson),
+		},
+		{
+			name:           "Series without a position",
+			payload:        `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":69,"series_id":"5678"}`,
+			serviceReturn:  []any{},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'series_position' is required with 'series_id'"),
+		},
+		{
+			name:           "Zero series position",
+			payload:        `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":69,"series_id":"5678","series_position":0}`,
+			serviceReturn:  []any{},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'series_position' should be between 0.01 and 9999.99 with at most 2 decimals"),
+		},
+		{
+			name:           "Series position rounded to 0",
+			payload:        `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":69,"series_id":"5678","series_position":0.004}`,
+			serviceReturn:  []any{},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'series_position' should be between 0.01 and 9999.99 with at most 2 decimals"),
+		},
+		{
+			name:               "Series not found",
+			payload:            `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":69,"series_id":"5678","series_position":2.5}`,
+			serviceReturn:      []any{book.Book{}, book.ErrSeriesNotFound},
+			expectedServiceArg: seriesBookArg,
+			expectedOutput:     echo.NewHTTPError(http.StatusBadRequest, "invalid series"),
+		},
+		{
+			name:           "Invalid isbn check digit",
+			payload:        `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":69,"isbn":"978-0-306-40615-8"}`,
+			serviceReturn:  []any{},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'isbn' should be an ISBN-10 or ISBN-13"),
+		},
+		{
+			name:               "Duplicated isbn",
+			payload:            `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":69,"isbn":"978-0-306-40615-7"}`,
+			serviceReturn:      []any{book.Book{}, book.ErrAlreadyExists},
+			expectedServiceArg: isbnBookArg,
+			expectedOutput:     echo.NewHTTPError(http.StatusConflict, "a book with the isbn already exists"),
+		},
+		{
+			name:           "Invalid publication date",
+			payload:        `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":69,"publication_date":"03/01/1987"}`,
+			serviceReturn:  []any{},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "invalid date: should be YYYY-MM-DD"),
+		},
+		{
+			name:           "Zero page count",
+			payload:        `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":69,"page_count":0}`,
+			serviceReturn:  []any{},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'page_count' should be greater than 0"),
+		},
+		{
+			name:           "Invalid language",
+			payload:        `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":69,"language":"english!"}`,
+			serviceReturn:  []any{},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'language' should be a BCP 47 language tag"),
+		},
+		{
+			name:           "Invalid format",
+			payload:        `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":69,"format":"scroll"}`,
+			serviceReturn:  []any{},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'format' should be one of [hardcover, paperback, ebook, audiobook]"),
+		},
+		{
+			name:           "No genres",
+			payload:        `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","price":69}`,
+			serviceReturn:  []any{},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'genres' is required"),
+		},
+		{
+			name:           "No price",
+			payload:        `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"]}`,
+			serviceReturn:  []any{},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'price' is required"),
+		},
+		{
+			name:           "Zero price",
+			payload:        `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"], "price": 0}`,
+			serviceReturn:  []any{},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'price' should be greater than 0"),
+		},
+		{
+			name:               "String price",
+			payload:            `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":"69.00"}`,
+			serviceReturn:      []any{successBook, nil},
+			expectedServiceArg: successBook,
+			expectedStatusCode: http.StatusCreated,
+			expectedOutput:     string(successBookJson),
+		},
+		{
+			name:               "Object price",
+			payload:            `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":{"amount":"69","currency":"USD"}}`,
+			serviceReturn:      []any{successBook, nil},
+			expectedServiceArg: successBook,
+			expectedStatusCode: http.StatusCreated,
+			expectedOutput:     string(successBookJson),
+		},
+		{
+			name:           "Price with too many decimals",
+			payload:        `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":69.001}`,
+			serviceReturn:  []any{},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "invalid money: USD has 2 digits after the decimal point"),
+		},
+		{
+			name:           "Price in other currency",
+			payload:        `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":{"amount":"69","currency":"EUR"}}`,
+			serviceReturn:  []any{},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'price' should be in USD"),
+		},
+		{
+			name:           "Invalid type",
+			payload:        `{"title":69,"author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"], "price": 69}`,
+			serviceReturn:  []any{},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'title' should be string"),
+		},
+		{
+			name:           "Invalid json",
+			payload:        "[]",
+			serviceReturn:  []any{},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, msgInvalidPayload),
+		},
+		{
+			name:               "Internal server error",
+			payload:            `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":69}`,
+			serviceReturn:      []any{book.Book{}, errors.New("internal server error")},
+			expectedServiceArg: successBook,
+			expectedOutput:     echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodPost, "/book", strings.NewReader(test.payload))
+
+			test.expectedServiceArg.Id = ""
+			mockRepository := new(MockBookRepository)
+			mockRepository.On("CreateBook", ctx.Request().Context(), test.expectedServiceArg).Return(test.serviceReturn...)
+			h := handler{bookService: book.NewBookService(mockRepository)}
+
+			err := h.createBook(ctx)
+
+			if err != nil {
+				if !assert.Equal(t, test.expectedOutput, err) {
+					return
+				}
+
+				switch test.expectedOutput.(*echo.HTTPError).Code {
+				case http.StatusBadRequest:
+					mockRepository.AssertNotCalled(t, "CreateBook", test.expectedServiceArg)
+					return
+				}
+			} else {
+				assert.Equal(t, test.expectedStatusCode, rec.Code)
+				// why use strings.TrimSpace - https://stackoverflow.com/questions/36319918/why-does-json-encoder-add-an-extra-line/36320146#36320146
+				assert.Equal(t, test.expectedOutput, strings.TrimSpace(rec.Body.String()))
+			}
+
+			mockRepository.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReplaceBook(t *testing.T) {
+	successBook := book.Book{
+		Id:          "1234",
+		Title:       "this is a title",
+		Author:      "john doe",
+		Description: "this is a description",
+		CoverImage:  "coverimage.com",
+		Genres:      []string{"horror"},
+		Price:       book.NewMoney(6900, book.DefaultCurrency),
+	}
+
+	successBookJson, err := json.Marshal(successBook)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the metadata and the series missing from the payload are cleared
+	noSeries := ""
+	noPageCount := 0
+	coverImage := string(successBook.CoverImage)
+	successArg := book.UpdateBookOptions{
+		Title:           &successBook.Title,
+		Author:          &successBook.Author,
+		Description:     &successBook.Description,
+		CoverImage:      &coverImage,
+		Price:           &successBook.Price,
+		Genres:          &successBook.Genres,
+		Isbn:            &successBook.Isbn13,
+		Publisher:       &successBook.Publisher,
+		PublicationDate: &book.Date{},
+		Edition:         &successBook.Edition,
+		PageCount:       &noPageCount,
+		Language:        &successBook.Language,
+		Format:          &successBook.Format,
+		SeriesId:        &noSeries,
+	}
+
+	publicationDate := book.NewDate(1965, time.August, 1)
+	pageCount := 412
+	metadataArg := successArg
+	metadataArg.PublicationDate = &publicationDate
+	metadataArg.PageCount = &pageCount
+
+	tests := []struct {
+		name               string
+		payload            string
+		expectedOutput     any
+		serviceReturn      []any
+		expectedServiceArg book.UpdateBookOptions
+		expectedStatusCode int
+	}{
+		{
+			name:               "Success",
+			payload:            `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":69}`,
+			serviceReturn:      []any{successBook, nil},
+			expectedServiceArg: successArg,
+			expectedStatusCode: http.StatusOK,
+			expectedOutput:     string(successBookJson),
+		},
+		{
+			name:               "Publication date and page count",
+			payload:            `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":69,"publication_date":"1965-08-01","page_count":412}`,
+			serviceReturn:      []any{successBook, nil},
+			expectedServiceArg: metadataArg,
+			expectedStatusCode: http.StatusOK,
+			expectedOutput:     string(successBookJson),
+		},
+		{
+			name:           "Empty title",
+			payload:        `{"title":"","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":69}`,
+			serviceReturn:  []any{},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'title' is required"),
+		},
+		{
+			name:           "No price",
+			payload:        `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"]}`,
+			serviceReturn:  []any{},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'price' is required"),
+		},
+		{
+			name:               "Not found",
+			payload:            `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":69}`,
+			serviceReturn:      []any{book.Book{}, book.ErrNotFound},
+			expectedServiceArg: successArg,
+			expectedOutput:     echo.NewHTTPError(http.StatusNotFound, "book not found"),
+		},
+		{
+			name:               "Invalid genre",
+			payload:            `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":69}`,
+			serviceReturn:      []any{book.Book{}, book.ErrInvalidGenre},
+			expectedServiceArg: successArg,
+			expectedOutput:     echo.NewHTTPError(http.StatusBadRequest, "invalid genre"),
+		},
+		{
+			name:               "Internal server error",
+			payload:            `{"title":"this is a title","author":"john doe","description":"this is a description","cover_image":"coverimage.com","genres":["horror"],"price":69}`,
+			serviceReturn:      []any{book.Book{}, errors.New("internal server error")},
+			expectedServiceArg: successArg,
+			expectedOutput:     echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodPut, "/book/:id", strings.NewReader(test.payload))
+
+			mockRepository := new(MockBookRepository)
+			mockRepository.On("UpdateBook", ctx.Request().Context(), "1234", test.expectedServiceArg).Return(test.serviceReturn...)
+			h := handler{bookService: book.NewBookService(mockRepository)}
+
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("1234")
+			err := h.replaceBook(ctx)
+
+			if err != nil {
+				if !assert.Equal(t, test.expectedOutput, err) {
+					return
+				}
+
+				if len(test.serviceReturn) == 0 {
+					mockRepository.AssertNotCalled(t, "UpdateBook", "1234", test.expectedServiceArg)
+					return
+				}
+			} else {
+				assert.Equal(t, test.expectedStatusCode, rec.Code)
+				assert.Equal(t, test.expectedOutput, strings.TrimSpace(rec.Body.String()))
+			}
+
+			mockRepository.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateBook(t *testing.T) {
+	successBook := book.Book{
+		Id:          "1234",
+		Title:       "this is a new title",
+		Author:      "john doe",
+		Description: "this is a description",
+		CoverImage:  "coverimage.com",
+		Genres:      []string{},
+		Price:       book.NewMoney(6900, book.DefaultCurrency),
+	}
+
+	successBookJson, err := json.Marshal(successBook)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	title := "this is a new title"
+	genres := []string{}
+	isbn := "9780306406157"
+	clearedIsbn := ""
+	clearedPageCount := 0
+	seriesId := "5678"
+	noSeries := ""
+	seriesPosition := 3.0
+
+	tests := []struct {
+		name               string
+		payload            string
+		expectedOutput     any
+		serviceReturn      []any
+		expectedServiceArg book.UpdateBookOptions
+		expectedStatusCode int
+	}{
+		{
+			name:               "Success title",
+			payload:            `{"title":"this is a new title"}`,
+			serviceReturn:      []any{successBook, nil},
+			expectedServiceArg: book.UpdateBookOptions{Title: &title},
+			expectedStatusCode: http.StatusOK,
+			expectedOutput:     string(successBookJson),
+		},
+		{
+			name:               "Success clear genres",
+			payload:            `{"genres":[]}`,
+			serviceReturn:      []any{successBook, nil},
+			expectedServiceArg: book.UpdateBookOptions{Genres: &genres},
+			expectedStatusCode: http.StatusOK,
+			expectedOutput:     string(successBookJson),
+		},
+		{
+			name:               "Empty json",
+			payload:            `{}`,
+			serviceReturn:      []any{successBook, nil},
+			expectedServiceArg: book.UpdateBookOptions{},
+			expectedStatusCode: http.StatusOK,
+			expectedOutput:     string(successBookJson),
+		},
+		{
+			name:               "Success isbn",
+			payload:            `{"isbn":"0306406152"}`,
+			serviceReturn:      []any{successBook, nil},
+			expectedServiceArg: book.UpdateBookOptions{Isbn: &isbn},
+			expectedStatusCode: http.StatusOK,
+			expectedOutput:     string(successBookJson),
+		},
+		{
+			name:               "Success clear isbn",
+			payload:            `{"isbn":""}`,
+			serviceReturn:      []any{successBook, nil},
+			expectedServiceArg: book.UpdateBookOptions{Isbn: &clearedIsbn},
+			expectedStatusCode: http.StatusOK,
+			expectedOutput:     string(successBookJson),
+		},
+		{
+			name:               "Success clear publication date and page count",
+			payload:            `{"publication_date":"","page_count":0}`,
+			serviceReturn:      []any{successBook, nil},
+			expectedServiceArg: book.UpdateBookOptions{PublicationDate: &book.Date{}, PageCount: &clearedPageCount},
+			expectedStatusCode: http.StatusOK,
+			expectedOutput:     string(successBookJson),
+		},
+		{
+			name:           "Negative page count",
+			payload:        `{"page_count":-1}`,
+			serviceReturn:  []any{},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'page_count' should be greater than or equal to 0"),
+		},
+		{
+			name:               "Success series",
+			payload:            `{"series_id":"5678","series_position":3}`,
+			serviceReturn:      []any{successBook, nil},
+			expectedServiceArg: book.UpdateBookOptions{SeriesId: &seriesId, SeriesPosition: &seriesPosition},
+			expectedStatusCode: http.StatusOK,
+			expectedOutput:     string(successBookJson),
+		},
+		{
+			name:               "Success remove from series",
+			payload:            `{"series_id":""}`,
+			serviceReturn:      []any{successBook, nil},
+			expectedServiceArg: book.UpdateBookOptions{SeriesId: &noSeries},
+			expectedStatusCode: http.StatusOK,
+			expectedOutput:     string(successBookJson),
+		},
+		{
+			name:           "Series without a position",
+			payload:        `{"series_id":"5678"}`,
+			serviceReturn:  []any{},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'series_position' is required with 'series_id'"),
+		},
+		{
+			name:           "Series position with 3 decimals",
+			payload:        `{"series_id":"5678","series_position":1.234}`,
+			serviceReturn:  []any{},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'series_position' should be between 0.01 and 9999.99 with at most 2 decimals"),
+		},
+		{
+			name:           "Series position too large",
+			payload:        `{"series_id":"5678","series_position":10000}`,
+			serviceReturn:  []any{},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'series_position' should be between 0.01 and 9999.99 with at most 2 decimals"),
+		},
+		{
+			name:           "Position without a series",
+			payload:        `{"series_position":3}`,
+			serviceReturn:  []any{},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'series_id' is required with 'series_position'"),
+		},
+		{
+			name:           "Invalid isbn",
+			payload:        `{"isbn":"030640615X"}`,
+			serviceReturn:  []any{},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'isbn' should be an ISBN-10 or ISBN-13"),
+		},
+		{
+			name:               "Duplicated isbn",
+			payload:            `{"isbn":"0306406152"}`,
+			serviceReturn:      []any{book.Book{}, book.ErrAlreadyExists},
+			expectedServiceArg: book.UpdateBookOptions{Isbn: &isbn},
+			expectedOutput:     echo.NewHTTPError(http.StatusConflict, "a book with the isbn already exists"),
+		},
+		{
+			name:           "Empty title",
+			payload:        `{"title":""}`,
+			serviceReturn:  []any{},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'title' should have a length of at least 1"),
+		},
+		{
+			name:           "Zero price",
+			payload:        `{"price":0}`,
+			serviceReturn:  []any{},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'price' should be greater than 0"),
+		},
+		{
+			name:           "Invalid type",
+			payload:        `{"genres":"horror"}`,
+			serviceReturn:  []any{},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'genres' should be []string"),
+		},
+		{
+			name:               "Not found",
+			payload:            `{"title":"this is a new title"}`,
+			serviceReturn:      []any{book.Book{}, book.ErrNotFound},
+			expectedServiceArg: book.UpdateBookOptions{Title: &title},
+			expectedOutput:     echo.NewHTTPError(http.StatusNotFound, "book not found"),
+		},
+		{
+			name:               "Internal server error",
+			payload:            `{"title":"this is a new title"}`,
+			serviceReturn:      []any{book.Book{}, errors.New("internal server error")},
+			expectedServiceArg: book.UpdateBookOptions{Title: &title},
+			expectedOutput:     echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodPatch, "/book/:id", strings.NewReader(test.payload))
+
+			mockRepository := new(MockBookRepository)
+			mockRepository.On("UpdateBook", ctx.Request().Context(), "1234", test.expectedServiceArg).Return(test.serviceReturn...)
+			h := handler{bookService: book.NewBookService(mockRepository)}
+
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("1234")
+			err := h.updateBook(ctx)
+
+			if err != nil {
+				if !assert.Equal(t, test.expectedOutput, err) {
+					return
+				}
+
+				if len(test.serviceReturn) == 0 {
+					mockRepository.AssertNotCalled(t, "UpdateBook", "1234", test.expectedServiceArg)
+					return
+				}
+			} else {
+				assert.Equal(t, test.expectedStatusCode, rec.Code)
+				assert.Equal(t, test.expectedOutput, strings.TrimSpace(rec.Body.String()))
+			}
+
+			mockRepository.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeleteBook(t *testing.T) {
+	tests := []struct {
+		name               string
+		expectedOutput     any
+		serviceReturn      error
+		id                 string
+		expectedStatusCode int
+	}{
+		{
+			name:               "Success",
+			id:                 "1234",
+			expectedStatusCode: http.StatusNoContent,
+			expectedOutput:     "",
+		},
+		{
+			name:           "Not found",
+			serviceReturn:  book.ErrNotFound,
+			id:             "notfound",
+			expectedOutput: echo.NewHTTPError(http.StatusNotFound, "book not found"),
+		},
+		{
+			name:           "Internal server error",
+			serviceReturn:  errors.New("internal server error"),
+			id:             "1234",
+			expectedOutput: echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodDelete, "/book/:id", nil)
+
+			mockRepository := new(MockBookRepository)
+			mockRepository.On("DeleteBook", ctx.Request().Context(), test.id).Return(test.serviceReturn)
+			h := handler{bookService: book.NewBookService(mockRepository)}
+
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(test.id)
+			err := h.deleteBook(ctx)
+
+			if err != nil {
+				if !assert.Equal(t, test.expectedOutput, err) {
+					return
+				}
+			} else {
+				assert.Equal(t, test.expectedStatusCode, rec.Code)
+				assert.Equal(t, test.expectedOutput, strings.TrimSpace(rec.Body.String()))
+			}
+
+			mockRepository.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetBookByIsbn(t *testing.T) {
+	b := book.Book{
+		Id:     "1234",
+		Title:  "this is a title",
+		Author: "john doe",
+		Genres: []string{"horror"},
+		Price:  book.NewMoney(6900, book.DefaultCurrency),
+		Isbn13: "9780306406157",
+		Isbn10: "0306406152",
+	}
+
+	bookJson, err := json.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name               string
+		isbn               string
+		serviceCalled      bool
+		serviceReturn      []any
+		expectedOutput     any
+		expectedStatusCode int
+	}{
+		{
+			name:               "Success ISBN-13",
+			isbn:               "978-0-306-40615-7",
+			serviceCalled:      true,
+			serviceReturn:      []any{b, nil},
+			expectedOutput:     string(bookJson),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "Success ISBN-10",
+			isbn:               "0306406152",
+			serviceCalled:      true,
+			serviceReturn:      []any{b, nil},
+			expectedOutput:     string(bookJson),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Invalid isbn",
+			isbn:           "9780306406158",
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "invalid isbn"),
+		},
+		{
+			name:           "Not found",
+			isbn:           "9780306406157",
+			serviceCalled:  true,
+			serviceReturn:  []any{book.Book{}, book.ErrNotFound},
+			expectedOutput: echo.NewHTTPError(http.StatusNotFound, "book not found"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodGet, "/book/isbn/:isbn", nil)
+
+			mockRepository := new(MockBookRepository)
+			if test.serviceCalled {
+				mockRepository.On("GetBookByIsbn", ctx.Request().Context(), "9780306406157", book.DefaultCurrency).Return(test.serviceReturn...)
+			}
+			h := handler{bookService: book.NewBookService(mockRepository)}
+
+			ctx.SetParamNames("isbn")
+			ctx.SetParamValues(test.isbn)
+			err := h.getBookByIsbn(ctx)
+
+			mockRepository.AssertExpectations(t)
+
+			if err != nil {
+				assert.Equal(t, test.expectedOutput, err)
+				return
+			}
+
+			assert.Equal(t, test.expectedStatusCode, rec.Code)
+			assert.Equal(t, test.expectedOutput, strings.TrimSpace(rec.Body.String()))
+		})
+	}
+}
+
+func TestGetGenres(t *testing.T) {
+	data, err := os.ReadFile("../../testdata/genres.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var testdata []book.Genre
+
+	if err := json.Unmarshal(data, &testdata); err != nil {
+		t.Fatal(err)
+	}
+
+	testdataBytes, err := json.Marshal(testdata)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testdataJson := string(testdataBytes)
+
+	tests := []struct {
+		name               string
+		expectedOutput     any
+		serviceReturn      []any
+		expectedStatusCode int
+	}{
+		{
+			name:               "Success",
+			serviceReturn:      []any{testdata, nil},
+			expectedOutput:     testdataJson,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Internal server error",
+			serviceReturn:  []any{testdata, errors.New("internal server error")},
+			expectedOutput: echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr), expectedStatusCode: http.StatusOK,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodGet, "/genres", nil)
+
+			mockRepository := new(MockBookRepository)
+			mockRepository.On("GetGenres", ctx.Request().Context()).Return(test.serviceReturn...)
+			h := handler{bookService: book.NewBookService(mockRepository)}
+
+			err := h.getGenres(ctx)
+
+			if err != nil {
+				if !assert.Equal(t, test.expectedOutput, err) {
+					return
+				}
+
+				switch test.expectedOutput.(*echo.HTTPError).Code {
+				case http.StatusBadRequest:
+					mockRepository.AssertNotCalled(t, "GetGenres")
+					return
+				}
+			} else {
+				assert.Equal(t, test.expectedStatusCode, rec.Code)
+				assert.Equal(t, test.expectedOutput, strings.TrimSpace(rec.Body.String()))
+			}
+
+			mockRepository.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetBooks(t *testing.T) {
+	data, err := os.ReadFile("../../testdata/books.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var testdata []book.Book
+
+	if err := json.Unmarshal(data, &testdata); err != nil {
+		t.Fatal(err)
+	}
+
+	const nextCursor = "eyJrIjoiYWJjIiwidiI6WyJ4Il19"
+
+	// newBooksPageJson returns the first page of 101 books
+	newBooksPageJson := func(books []book.Book, next string) string {
+		return newTestJson(t, map[string]any{
+			"books":     books,
+			"total":     101,
+			"page":      1,
+			"page_size": 10,
+			"pages":     11,
+			"next":      next,
+			"prev":      nil,
+		})
+	}
+
+	inStock := false
+	minPrice := book.NewMoney(500, book.DefaultCurrency)
+	maxPrice := book.NewMoney(1250, book.DefaultCurrency)
+	maxPriceJpy := book.NewMoney(2000, "JPY")
+	updatedSince := time.Date(2024, 4, 20, 10, 0, 0, 0, time.UTC)
+	minRating := 4.5
+
+	facets := book.Facets{
+		Genres:  []book.FacetCount{{Value: "Thriller", Count: 42}, {Value: "Horror", Count: 7}},
+		Authors: []book.FacetCount{{Value: "Stephen King", Count: 12}},
+		Prices: []book.PriceBucket{
+			{Max: &book.PriceFacetBoundaries[0], Count: 30},
+			{Min: &book.PriceFacetBoundaries[0], Max: &book.PriceFacetBoundaries[1], Count: 19},
+			{Min: &book.PriceFacetBoundaries[1], Max: &book.PriceFacetBoundaries[2]},
+			{Min: &book.PriceFacetBoundaries[2], Max: &book.PriceFacetBoundaries[3]},
+			{Min: &book.PriceFacetBoundaries[3]},
+		},
+	}
+
+	// the genres left out of the query are empty rather than nil
+	noGenreFilter := book.GetBooksFilter{Genres: []string{}, ExcludeGenres: []string{}}
+
+	tests := []struct {
+		expectedOutput     any
+		name               string
+		query              string
+		serviceReturn      []any
+		expectedServiceArg book.GetBooksOptions
+		expectedStatusCode int
+	}{
+		{
+			name:          "Success without query",
+			serviceReturn: []any{book.BooksPage{Books: testdata, Count: 101}, nil},
+			expectedServiceArg: book.GetBooksOptions{
+				Currency: book.DefaultCurrency,
+				Limit:    10,
+				Filter:   noGenreFilter,
+			},
+			expectedOutput:     newBooksPageJson(testdata, "/books?page=2"),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:          "Success page",
+			query:         "?page=1",
+			serviceReturn: []any{book.BooksPage{Books: testdata, Count: 101}, nil},
+			expectedServiceArg: book.GetBooksOptions{
+				Currency: book.DefaultCurrency,
+				Limit:    10,
+				Offset:   0,
+				Filter:   noGenreFilter,
+			},
+			expectedOutput:     newBooksPageJson(testdata, "/books?page=2"),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:          "Success exceed pages",
+			query:         "?page=6969",
+			serviceReturn: []any{book.BooksPage{Books: []book.Book(nil), Count: 101}, nil},
+			expectedServiceArg: book.GetBooksOptions{
+				Currency: book.DefaultCurrency,
+				Limit:    10,
+				Offset:   6968 * 10,
+				Filter:   noGenreFilter,
+			},
+			expectedOutput: newTestJson(t, map[string]any{
+				"books":     []book.Book(nil),
+				"total":     101,
+				"page":      6969,
+				"page_size": 10,
+				"pages":     11,
+				"next":      nil,
+				"prev":      "/books?page=6968",
+			}),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:          "Success page size",
+			query:         "?page=2&page_size=50",
+			serviceReturn: []any{book.BooksPage{Books: testdata, Count: 101}, nil},
+			expectedServiceArg: book.GetBooksOptions{
+				Currency: book.DefaultCurrency,
+				Limit:    50,
+				Offset:   50,
+				Filter:   noGenreFilter,
+			},
+			expectedOutput: newTestJson(t, map[string]any{
+				"books":     testdata,
+				"total":     101,
+				"page":      2,
+				"page_size": 50,
+				"pages":     3,
+				"next":      "/books?page=3&page_size=50",
+				"prev":      "/books?page=1&page_size=50",
+			}),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Exceed max page size",
+			query:          "?page_size=1000",
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'page_size' should be between 1 and 100"),
+		},
+		{
+			name:          "Success desc",
+			query:         "?desc=true",
+			serviceReturn: []any{book.BooksPage{Books: []book.Book(nil), Count: 101}, nil},
+			expectedServiceArg: book.GetBooksOptions{
+				Currency: book.DefaultCurrency,
+				Limit:    10,
+				Desc:     true,
+				Filter:   noGenreFilter,
+			},
+			expectedOutput:     newBooksPageJson([]book.Book(nil), "/books?desc=true&page=2"),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:          "Success order_by",
+			query:         "?order_by=author",
+			serviceReturn: []any{book.BooksPage{Books: []book.Book(nil), Count: 101}, nil},
+			expectedServiceArg: book.GetBooksOptions{
+				Currency: book.DefaultCurrency,
+				Limit:    10,
+				OrderBy:  "author",
+				Filter:   noGenreFilter,
+			},
+			expectedOutput:     newBooksPageJson([]book.Book(nil), "/books?order_by=author&page=2"),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:          "Success filter by title",
+			query:         "?title=Moby%20Dick",
+			serviceReturn: []any{book.BooksPage{Books: []book.Book(nil), Count: 101}, nil},
+			expectedServiceArg: book.GetBooksOptions{
+				Currency: book.DefaultCurrency,
+				Limit:    10,
+				Filter: book.GetBooksFilter{
+					Title:         "Moby Dick",
+					Genres:        []string{},
+					ExcludeGenres: []string{},
+				},
+			},
+			expectedOutput:     newBooksPageJson([]book.Book(nil), "/books?page=2&title=Moby+Dick"),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:          "Success filter by author",
+			query:         "?author=doe",
+			serviceReturn: []any{book.BooksPage{Books: []book.Book(nil), Count: 101}, nil},
+			expectedServiceArg: book.GetBooksOptions{
+				Currency: book.DefaultCurrency,
+				Limit:    10,
+				Filter: book.GetBooksFilter{
+					Author:        "doe",
+					Genres:        []string{},
+					ExcludeGenres: []string{},
+				},
+			},
+			expectedOutput:     newBooksPageJson([]book.Book(nil), "/books?author=doe&page=2"),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:          "Success filter by in stock",
+			query:         "?in_stock=false",
+			serviceReturn: []any{book.BooksPage{Books: []book.Book(nil), Count: 101}, nil},
+			expectedServiceArg: book.GetBooksOptions{
+				Currency: book.DefaultCurrency,
+				Limit:    10,
+				Filter: book.GetBooksFilter{
+					InStock:       &inStock,
+					Genres:        []string{},
+					ExcludeGenres: []string{},
+				},
+			},
+			expectedOutput:     newBooksPageJson([]book.Book(nil), "/books?in_stock=false&page=2"),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:          "Success sort",
+			query:         "?sort=price,-created_at",
+			serviceReturn: []any{book.BooksPage{Books: []book.Book(nil), Count: 101}, nil},
+			expectedServiceArg: book.GetBooksOptions{
+				Currency: book.DefaultCurrency,
+				Limit:    10,
+				Sort: []book.SortKey{
+					{Field: book.SortByPrice},
+					{Field: book.SortByCreatedAt, Desc: true},
+				},
+				Filter: noGenreFilter,
+			},
+			expectedOutput:     newBooksPageJson([]book.Book(nil), "/books?page=2&sort=price%2C-created_at"),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Invalid sort",
+			query:          "?sort=price,isbn",
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "invalid sort: unknown field 'isbn'"),
+		},
+		{
+			name:           "Duplicate sort",
+			query:          "?sort=price,-price",
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "invalid sort: 'price' is used more than once"),
+		},
+		{
+			name:          "Success filter by price",
+			query:         "?min_price=5&max_price=12.50",
+			serviceReturn: []any{book.BooksPage{Books: []book.Book(nil), Count: 101}, nil},
+			expectedServiceArg: book.GetBooksOptions{
+				Currency: book.DefaultCurrency,
+				Limit:    10,
+				Filter: book.GetBooksFilter{
+					MinPrice:      &minPrice,
+					MaxPrice:      &maxPrice,
+					Genres:        []string{},
+					ExcludeGenres: []string{},
+				},
+			},
+			expectedOutput:     newBooksPageJson([]book.Book(nil), "/books?max_price=12.50&min_price=5&page=2"),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:          "Success filter by price in currency",
+			query:         "?currency=jpy&max_price=2000",
+			serviceReturn: []any{book.BooksPage{Books: []book.Book(nil), Count: 101}, nil},
+			expectedServiceArg: book.GetBooksOptions{
+				Currency: "JPY",
+				Limit:    10,
+				Filter: book.GetBooksFilter{
+					MaxPrice:      &maxPriceJpy,
+					Genres:        []string{},
+					ExcludeGenres: []string{},
+				},
+			},
+			expectedOutput:     newBooksPageJson([]book.Book(nil), "/books?currency=jpy&max_price=2000&page=2"),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Invalid min_price",
+			query:          "?min_price=abc",
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "invalid value for 'min_price'"),
+		},
+		{
+			name:           "Invalid max_price",
+			query:          "?currency=jpy&max_price=10.5",
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "invalid value for 'max_price'"),
+		},
+		{
+			name:          "Success filter by exact author and excluded genres",
+			query:         "?author=Albert%20Camus&exact_author=true&exclude_genres=horror,%20romance",
+			serviceReturn: []any{book.BooksPage{Books: []book.Book(nil), Count: 101}, nil},
+			expectedServiceArg: book.GetBooksOptions{
+				Currency: book.DefaultCurrency,
+				Limit:    10,
+				Filter: book.GetBooksFilter{
+					Author:        "Albert Camus",
+					ExactAuthor:   true,
+					ExcludeGenres: []string{"horror", "romance"},
+					Genres:        []string{},
+				},
+			},
+			expectedOutput:     newBooksPageJson([]book.Book(nil), "/books?author=Albert+Camus&exact_author=true&exclude_genres=horror%2C+romance&page=2"),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:          "Success filter by all genres",
+			query:         "?genres=thriller,horror&genre_match=all",
+			serviceReturn: []any{book.BooksPage{Books: []book.Book(nil), Count: 101}, nil},
+			expectedServiceArg: book.GetBooksOptions{
+				Currency: book.DefaultCurrency,
+				Limit:    10,
+				Filter: book.GetBooksFilter{
+					Genres:        []string{"thriller", "horror"},
+					GenreMatch:    book.GenreMatchAll,
+					ExcludeGenres: []string{},
+				},
+			},
+			expectedOutput:     newBooksPageJson([]book.Book(nil), "/books?genre_match=all&genres=thriller%2Chorror&page=2"),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Invalid genre_match",
+			query:          "?genres=thriller,horror&genre_match=both",
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'genre_match' should be all or any"),
+		},
+		{
+			name:          "Success facets",
+			query:         "?facets=true&genres=thriller",
+			serviceReturn: []any{book.BooksPage{Books: testdata, Count: 5, Facets: &facets}, nil},
+			expectedServiceArg: book.GetBooksOptions{
+				Currency: book.DefaultCurrency,
+				Limit:    10,
+				Facets:   true,
+				Filter: book.GetBooksFilter{
+					Genres:        []string{"thriller"},
+					ExcludeGenres: []string{},
+				},
+			},
+			expectedOutput: newTestJson(t, map[string]any{
+				"books":     testdata,
+				"facets":    facets,
+				"total":     5,
+				"page":      1,
+				"page_size": 10,
+				"pages":     1,
+				"next":      nil,
+				"prev":      nil,
+			}),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Invalid facets",
+			query:          "?facets=j",
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "invalid value for 'facets'"),
+		},
+		{
+			name:          "Success filter by updated since",
+			query:         "?updated_since=2024-04-20T10:00:00Z&sort=updated_at",
+			serviceReturn: []any{book.BooksPage{Books: []book.Book(nil), Count: 101}, nil},
+			expectedServiceArg: book.GetBooksOptions{
+				Currency: book.DefaultCurrency,
+				Limit:    10,
+				Sort:     []book.SortKey{{Field: book.SortByUpdatedAt}},
+				Filter: book.GetBooksFilter{
+					UpdatedSince:  &updatedSince,
+					Genres:        []string{},
+					ExcludeGenres: []string{},
+				},
+			},
+			expectedOutput:     newBooksPageJson([]book.Book(nil), "/books?page=2&sort=updated_at&updated_since=2024-04-20T10%3A00%3A00Z"),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Invalid updated_since",
+			query:          "?updated_since=2024-04-20",
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "invalid value for 'updated_since'"),
+		},
+		{
+			name:          "Success filter by min rating",
+			query:         "?min_rating=4.5&sort=-rating",
+			serviceReturn: []any{book.BooksPage{Books: []book.Book(nil), Count: 101}, nil},
+			expectedServiceArg: book.GetBooksOptions{
+				Currency: book.DefaultCurrency,
+				Limit:    10,
+				Sort:     []book.SortKey{{Field: book.SortByRating, Desc: true}},
+				Filter: book.GetBooksFilter{
+					MinRating:     &minRating,
+					Genres:        []string{},
+					ExcludeGenres: []string{},
+				},
+			},
+			expectedOutput:     newBooksPageJson([]book.Book(nil), "/books?min_rating=4.5&page=2&sort=-rating"),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Invalid min_rating",
+			query:          "?min_rating=high",
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "invalid value for 'min_rating'"),
+		},
+		{
+			name:           "Out of range min_rating",
+			query:          "?min_rating=6",
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'min_rating' should be between 1 and 5"),
+		},
+		{
+			name:          "Success search",
+			query:         "?q=camus%20stranger",
+			serviceReturn: []any{book.BooksPage{Books: []book.Book(nil), Count: 101}, nil},
+			expectedServiceArg: book.GetBooksOptions{
+				Currency: book.DefaultCurrency,
+				Limit:    10,
+				OrderBy:  book.OrderByRelevance,
+				Filter: book.GetBooksFilter{
+					Query:         "camus stranger",
+					Genres:        []string{},
+					ExcludeGenres: []string{},
+				},
+			},
+			expectedOutput:     newBooksPageJson([]book.Book(nil), "/books?page=2&q=camus+stranger"),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:          "Success search sort",
+			query:         "?q=camus&sort=-price",
+			serviceReturn: []any{book.BooksPage{Books: []book.Book(nil), Count: 101}, nil},
+			expectedServiceArg: book.GetBooksOptions{
+				Currency: book.DefaultCurrency,
+				Limit:    10,
+				Sort:     []book.SortKey{{Field: book.SortByPrice, Desc: true}},
+				Filter: book.GetBooksFilter{
+					Query:         "camus",
+					Genres:        []string{},
+					ExcludeGenres: []string{},
+				},
+			},
+			expectedOutput:     newBooksPageJson([]book.Book(nil), "/books?page=2&q=camus&sort=-price"),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:          "Success search order_by",
+			query:         "?q=camus&order_by=title",
+			serviceReturn: []any{book.BooksPage{Books: []book.Book(nil), Count: 101}, nil},
+			expectedServiceArg: book.GetBooksOptions{
+				Currency: book.DefaultCurrency,
+				Limit:    10,
+				OrderBy:  "title",
+				Filter: book.GetBooksFilter{
+					Query:         "camus",
+					Genres:        []string{},
+					ExcludeGenres: []string{},
+				},
+			},
+			expectedOutput:     newBooksPageJson([]book.Book(nil), "/books?order_by=title&page=2&q=camus"),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:          "Success currency",
+			query:         "?currency=eur",
+			serviceReturn: []any{book.BooksPage{Books: []book.Book(nil), Count: 101}, nil},
+			expectedServiceArg: book.GetBooksOptions{
+				Currency: "EUR",
+				Limit:    10,
+				Filter:   noGenreFilter,
+			},
+			expectedOutput:     newBooksPageJson([]book.Book(nil), "/books?currency=eur&page=2"),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:          "Unsupported currency",
+			query:         "?currency=xyz",
+			serviceReturn: []any{book.BooksPage{Books: []book.Book(nil), Count: 0}, book.ErrInvalidCurrency},
+			expectedServiceArg: book.GetBooksOptions{
+				Currency: "XYZ",
+				Limit:    10,
+				Filter:   noGenreFilter,
+			},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "unsupported currency 'xyz'"),
+		},
+		{
+			name:          "Success cursor",
+			query:         "?cursor=" + nextCursor,
+			serviceReturn: []any{book.BooksPage{Books: testdata, Count: 101, NextCursor: nextCursor}, nil},
+			expectedServiceArg: book.GetBooksOptions{
+				Currency: book.DefaultCurrency,
+				Limit:    10,
+				Cursor:   nextCursor,
+				Filter:   noGenreFilter,
+			},
+			expectedOutput: newTestJson(t, map[string]any{
+				"books":       testdata,
+				"total":       101,
+				"page_size":   10,
+				"pages":       11,
+				"next":        "/books?cursor=" + nextCursor,
+				"next_cursor": nextCursor,
+				"prev":        nil,
+			}),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:          "Success without count",
+			query:         "?include_count=false",
+			serviceReturn: []any{book.BooksPage{Books: testdata, Count: -1, NextCursor: nextCursor}, nil},
+			expectedServiceArg: book.GetBooksOptions{
+				Currency:  book.DefaultCurrency,
+				Limit:     10,
+				SkipCount: true,
+				Filter:    noGenreFilter,
+			},
+			expectedOutput: newTestJson(t, map[string]any{
+				"books":       testdata,
+				"page":        1,
+				"page_size":   10,
+				"next":        "/books?include_count=false&page=2",
+				"next_cursor": nextCursor,
+				"prev":        nil,
+			}),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:          "Invalid cursor",
+			query:         "?cursor=j",
+			serviceReturn: []any{book.BooksPage{}, book.ErrInvalidCursor},
+			expectedServiceArg: book.GetBooksOptions{
+				Currency: book.DefaultCurrency,
+				Limit:    10,
+				Cursor:   "j",
+				Filter:   noGenreFilter,
+			},
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "invalid value for 'cursor'"),
+		},
+		{
+			name:           "Invalid include_count",
+			query:          "?include_count=j",
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "invalid value for 'include_count'"),
 		},
 		{
 			name:           "Invalid page",
@@ -540,9 +1849,11 @@ func TestGetBooks(t *testing.T) {
 		},
 		{
 			name:          "Internal server error",
-			serviceReturn: []any{success.Books, 101, errors.New("internal server error")},
+			serviceReturn: []any{book.BooksPage{Books: testdata, Count: 101}, errors.New("internal server error")},
 			expectedServiceArg: book.GetBooksOptions{
-				Limit: 10,
+				Currency: book.DefaultCurrency,
+				Limit:    10,
+				Filter:   noGenreFilter,
 			},
 			expectedOutput: echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr),
 		},
@@ -578,6 +1889,112 @@ func TestGetBooks(t *testing.T) {
 	}
 }
 
+func TestGetNewBooks(t *testing.T) {
+	books := []book.Book{
+		{
+			Id:        "1234",
+			Title:     "New",
+			Author:    "John Doe",
+			Price:     book.NewMoney(1000, book.DefaultCurrency),
+			Genres:    []string{"Fantasy"},
+			CreatedAt: time.Date(2024, 4, 25, 0, 0, 0, 0, time.UTC),
+			UpdatedAt: time.Date(2024, 4, 25, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	tests := []struct {
+		expectedOutput     any
+		name               string
+		query              string
+		serviceReturn      []any
+		expectedDays       int
+		expectedCurrency   string
+		expectedStatusCode int
+	}{
+		{
+			name:             "Success",
+			serviceReturn:    []any{book.BooksPage{Books: books, Count: 1}, nil},
+			expectedDays:     30,
+			expectedCurrency: book.DefaultCurrency,
+			expectedOutput: newTestJson(t, map[string]any{
+				"books":     books,
+				"total":     1,
+				"page":      1,
+				"page_size": 10,
+				"pages":     1,
+				"next":      nil,
+				"prev":      nil,
+			}),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:             "Success days",
+			query:            "?days=7&currency=eur",
+			serviceReturn:    []any{book.BooksPage{Books: books, Count: 11}, nil},
+			expectedDays:     7,
+			expectedCurrency: "EUR",
+			expectedOutput: newTestJson(t, map[string]any{
+				"books":     books,
+				"total":     11,
+				"page":      1,
+				"page_size": 10,
+				"pages":     2,
+				"next":      "/books/new?currency=eur&days=7&page=2",
+				"prev":      nil,
+			}),
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:           "Invalid days",
+			query:          "?days=j",
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "invalid value for 'days'"),
+		},
+		{
+			name:           "Exceed max days",
+			query:          "?days=366",
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'days' should be between 1 and 365"),
+		},
+		{
+			name:             "Internal server error",
+			serviceReturn:    []any{book.BooksPage{}, errors.New("internal server error")},
+			expectedDays:     30,
+			expectedCurrency: book.DefaultCurrency,
+			expectedOutput:   echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodGet, "/books/new"+test.query, nil)
+
+			// the books are created since now minus the days, give the test some leeway
+			expectedSince := time.Now().AddDate(0, 0, -test.expectedDays)
+			matchOptions := mock.MatchedBy(func(o book.GetBooksOptions) bool {
+				return o.Currency == test.expectedCurrency &&
+					o.Limit == 10 &&
+					assert.ObjectsAreEqual([]book.SortKey{{Field: book.SortByCreatedAt, Desc: true}}, o.Sort) &&
+					o.Filter.CreatedSince != nil &&
+					o.Filter.CreatedSince.Sub(expectedSince).Abs() < time.Minute
+			})
+
+			mockRepository := new(MockBookRepository)
+			mockRepository.On("GetBooks", ctx.Request().Context(), matchOptions).Return(test.serviceReturn...)
+			h := handler{bookService: book.NewBookService(mockRepository)}
+
+			err := h.getNewBooks(ctx)
+
+			if err != nil {
+				assert.Equal(t, test.expectedOutput, err)
+				return
+			}
+
+			assert.Equal(t, test.expectedStatusCode, rec.Code)
+			assert.Equal(t, test.expectedOutput, strings.TrimSpace(rec.Body.String()))
+			mockRepository.AssertExpectations(t)
+		})
+	}
+}
+
 //
 // func TestGetBookById(t *testing.T) {
 // 	memoryRepository.Seed()
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cativovo/bookstore/internal/user"
+	"github.com/labstack/echo/v4"
+)
+
+type payloadRegister struct {
+	Email string `json:"email" validate:"required,email"`
+	// max counts the characters, a shorter password can still be longer than the 72 bytes bcrypt hashes
+	Password string `json:"password" validate:"required,min=8,max=72"`
+	Name     string `json:"name" validate:"required"`
+}
+
+func (h *handler) register(ctx echo.Context) error {
+	var payload payloadRegister
+	if err := ctx.Bind(&payload); err != nil {
+		return getBindErr(err)
+	}
+
+	if err := ctx.Validate(&payload); err != nil {
+		return err
+	}
+
+	u, err := h.userService.Register(ctx.Request().Context(), payload.Email, payload.Password, payload.Name)
+	if err != nil {
+		if errors.Is(err, user.ErrAlreadyExists) {
+			return echo.NewHTTPError(http.StatusConflict, "email is already used")
+		}
+		if errors.Is(err, user.ErrPasswordTooLong) {
+			return echo.NewHTTPError(http.StatusBadRequest, "'password' should have a length of at most 72 bytes")
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.JSON(http.StatusCreated, u)
+}
+
+type payloadLogin struct {
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+func (h *handler) login(ctx echo.Context) error {
+	var payload payloadLogin
+	if err := ctx.Bind(&payload); err != nil {
+		return getBindErr(err)
+	}
+
+	if err := ctx.Validate(&payload); err != nil {
+		return err
+	}
+
+	tokens, err := h.userService.Login(ctx.Request().Context(), payload.Email, payload.Password)
+	if err != nil {
+		if errors.Is(err, user.ErrInvalidCredentials) {
+			return echo.NewHTTPError(http.StatusUnauthorized, "invalid email or password")
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.JSON(http.StatusOK, tokens)
+}
+
+type payloadRefreshToken struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+func (h *handler) refreshToken(ctx echo.Context) error {
+	var payload payloadRefreshToken
+	if err := ctx.Bind(&payload); err != nil {
+		return getBindErr(err)
+	}
+
+	if err := ctx.Validate(&payload); err != nil {
+		return err
+	}
+
+	tokens, err := h.userService.Refresh(ctx.Request().Context(), payload.RefreshToken)
+	if err != nil {
+		if errors.Is(err, user.ErrInvalidToken) {
+			return echo.NewHTTPError(http.StatusUnauthorized, "invalid refresh token")
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.JSON(http.StatusOK, tokens)
+}
+
+func (h *handler) logout(ctx echo.Context) error {
+	var payload payloadRefreshToken
+	if err := ctx.Bind(&payload); err != nil {
+		return getBindErr(err)
+	}
+
+	if err := ctx.Validate(&payload); err != nil {
+		return err
+	}
+
+	if err := h.userService.Logout(ctx.Request().Context(), payload.RefreshToken); err != nil {
+		if errors.Is(err, user.ErrInvalidToken) {
+			return echo.NewHTTPError(http.StatusUnauthorized, "invalid refresh token")
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// getCurrentUser returns the user of the access token
+func (h *handler) getCurrentUser(ctx echo.Context) error {
+	u, ok := user.FromContext(ctx.Request().Context())
+	if !ok {
+		return unauthorized(ctx, "authentication required")
+	}
+
+	return ctx.JSON(http.StatusOK, u)
+}
+
+type payloadSetUserRole struct {
+	Role user.Role `json:"role" validate:"required,oneof=customer staff admin"`
+}
+
+func (h *handler) setUserRole(ctx echo.Context) error {
+	var payload payloadSetUserRole
+	if err := ctx.Bind(&payload); err != nil {
+		return getBindErr(err)
+	}
+
+	if err := ctx.Validate(&payload); err != nil {
+		return err
+	}
+
+	u, err := h.userService.SetRole(ctx.Request().Context(), ctx.Param("id"), payload.Role)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "user not found")
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.JSON(http.StatusOK, u)
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cativovo/bookstore/internal/webhook"
+	"github.com/labstack/echo/v4"
+)
+
+type payloadCreateWebhook struct {
+	Url        string              `json:"url" validate:"required,http_url"`
+	EventTypes []webhook.EventType `json:"event_types" validate:"required,min=1,unique,dive,oneof=book.created book.updated book.price_changed book.deleted genre.created genre.updated genre.deleted"`
+	// the secret is chosen by the receiver so it can verify the signature of the deliveries
+	Secret string `json:"secret" validate:"required,min=16"`
+}
+
+func (h *handler) createWebhook(ctx echo.Context) error {
+	var payload payloadCreateWebhook
+	if err := ctx.Bind(&payload); err != nil {
+		return getBindErr(err)
+	}
+
+	if err := ctx.Validate(&payload); err != nil {
+		return err
+	}
+
+	w, err := h.webhookService.CreateWebhook(ctx.Request().Context(), payload.Url, payload.EventTypes, payload.Secret)
+	if err != nil {
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.JSON(http.StatusCreated, w)
+}
+
+func (h *handler) deleteWebhook(ctx echo.Context) error {
+	if err := h.webhookService.DeleteWebhook(ctx.Request().Context(), ctx.Param("id")); err != nil {
+		if errors.Is(err, webhook.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "webhook not found")
+		}
+
+		ctx.Logger().Error(err)
+		return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
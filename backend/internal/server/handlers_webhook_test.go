@@ -0,0 +1,158 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cativovo/bookstore/internal/webhook"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) CreateWebhook(ctx context.Context, url string, eventTypes []webhook.EventType, secret string) (webhook.Webhook, error) {
+	args := m.Called(ctx, url, eventTypes, secret)
+	return args.Get(0).(webhook.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) DeleteWebhook(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestCreateWebhook(t *testing.T) {
+	created := webhook.Webhook{
+		CreatedAt:  time.Date(2024, time.April, 30, 10, 22, 15, 0, time.UTC),
+		Id:         "1234",
+		Url:        "https://example.com/hooks/catalog",
+		EventTypes: []webhook.EventType{webhook.EventBookCreated, webhook.EventBookPriceChanged},
+	}
+
+	tests := []struct {
+		name               string
+		payload            string
+		expectedServiceArg []webhook.EventType
+		serviceReturn      error
+		expectedOutput     any
+		expectedStatusCode int
+	}{
+		{
+			name:               "Success",
+			payload:            `{"url":"https://example.com/hooks/catalog","event_types":["book.created","book.price_changed"],"secret":"whsec_0123456789abcdef"}`,
+			expectedServiceArg: created.EventTypes,
+			expectedOutput:     created,
+			expectedStatusCode: http.StatusCreated,
+		},
+		{
+			name:           "Invalid url",
+			payload:        `{"url":"ftp://example.com","event_types":["book.created"],"secret":"whsec_0123456789abcdef"}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'url' should be an http or https url"),
+		},
+		{
+			name:           "No event types",
+			payload:        `{"url":"https://example.com/hooks/catalog","event_types":[],"secret":"whsec_0123456789abcdef"}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'event_types' should have a length of at least 1"),
+		},
+		{
+			name:           "Unknown event type",
+			payload:        `{"url":"https://example.com/hooks/catalog","event_types":["book.sold"],"secret":"whsec_0123456789abcdef"}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'event_types[0]' should be one of [book.created, book.updated, book.price_changed, book.deleted, genre.created, genre.updated, genre.deleted]"),
+		},
+		{
+			name:           "Duplicate event types",
+			payload:        `{"url":"https://example.com/hooks/catalog","event_types":["book.created","book.created"],"secret":"whsec_0123456789abcdef"}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'event_types' should not have duplicates"),
+		},
+		{
+			name:           "Short secret",
+			payload:        `{"url":"https://example.com/hooks/catalog","event_types":["book.created"],"secret":"1234"}`,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'secret' should have a length of at least 16"),
+		},
+		{
+			name:               "Internal server error",
+			payload:            `{"url":"https://example.com/hooks/catalog","event_types":["book.created","book.price_changed"],"secret":"whsec_0123456789abcdef"}`,
+			expectedServiceArg: created.EventTypes,
+			serviceReturn:      errors.New("internal server error"),
+			expectedOutput:     echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodPost, "/webhooks", strings.NewReader(test.payload))
+
+			mockRepository := new(MockWebhookRepository)
+			mockRepository.On("CreateWebhook", ctx.Request().Context(), created.Url, test.expectedServiceArg, "whsec_0123456789abcdef").Return(created, test.serviceReturn)
+			h := handler{webhookService: webhook.NewWebhookService(mockRepository)}
+
+			err := h.createWebhook(ctx)
+
+			if err != nil {
+				assert.Equal(t, test.expectedOutput, err)
+				if test.expectedServiceArg == nil {
+					mockRepository.AssertNotCalled(t, "CreateWebhook", ctx.Request().Context(), mock.Anything, mock.Anything, mock.Anything)
+				}
+				return
+			}
+
+			expected, err := json.Marshal(test.expectedOutput)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, test.expectedStatusCode, rec.Code)
+			assert.Equal(t, string(expected), strings.TrimSpace(rec.Body.String()))
+			mockRepository.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDeleteWebhook(t *testing.T) {
+	tests := []struct {
+		name               string
+		serviceReturn      error
+		expectedOutput     any
+		expectedStatusCode int
+	}{
+		{
+			name:               "Success",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name:           "Not found",
+			serviceReturn:  webhook.ErrNotFound,
+			expectedOutput: echo.NewHTTPError(http.StatusNotFound, "webhook not found"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, rec := newEchoContext(t, http.MethodDelete, "/webhooks/:id", nil)
+
+			mockRepository := new(MockWebhookRepository)
+			mockRepository.On("DeleteWebhook", ctx.Request().Context(), "1234").Return(test.serviceReturn)
+			h := handler{webhookService: webhook.NewWebhookService(mockRepository)}
+
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("1234")
+			err := h.deleteWebhook(ctx)
+
+			if err != nil {
+				assert.Equal(t, test.expectedOutput, err)
+				return
+			}
+
+			assert.Equal(t, test.expectedStatusCode, rec.Code)
+			mockRepository.AssertExpectations(t)
+		})
+	}
+}
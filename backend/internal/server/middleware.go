@@ -0,0 +1,75 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cativovo/bookstore/internal/user"
+	"github.com/labstack/echo/v4"
+)
+
+// authenticate puts the user of the bearer token or of the api key on the request context,
+// a request without an Authorization header stays anonymous
+func (h *handler) authenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		header := ctx.Request().Header.Get(echo.HeaderAuthorization)
+		if header == "" {
+			return next(ctx)
+		}
+
+		scheme, token, _ := strings.Cut(header, " ")
+
+		var u user.User
+		var err error
+
+		switch {
+		case strings.EqualFold(scheme, "Bearer"):
+			u, err = h.userService.Authenticate(token)
+			if err != nil {
+				return unauthorized(ctx, "invalid or expired token")
+			}
+		case strings.EqualFold(scheme, "ApiKey"):
+			u, err = h.userService.AuthenticateApiKey(ctx.Request().Context(), token)
+			if err != nil {
+				if errors.Is(err, user.ErrInvalidToken) {
+					return unauthorized(ctx, "invalid or revoked api key")
+				}
+
+				ctx.Logger().Error(err)
+				return echo.NewHTTPError(http.StatusInternalServerError, msgInternalServerErr)
+			}
+		default:
+			return unauthorized(ctx, "invalid authorization header")
+		}
+
+		ctx.SetRequest(ctx.Request().WithContext(user.NewContext(ctx.Request().Context(), u)))
+
+		return next(ctx)
+	}
+}
+
+// requirePermission rejects the anonymous requests with 401 and the requests of users without the permission with 403.
+// It runs after authenticate so the user is already on the request context.
+func requirePermission(p user.Permission) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			u, ok := user.FromContext(ctx.Request().Context())
+			if !ok {
+				return unauthorized(ctx, "authentication required")
+			}
+
+			if !u.HasPermission(p) {
+				return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("'%s' permission required", p))
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+func unauthorized(ctx echo.Context, message string) error {
+	ctx.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer, ApiKey`)
+	return echo.NewHTTPError(http.StatusUnauthorized, message)
+}
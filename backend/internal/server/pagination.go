@@ -0,0 +1,110 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultPageSize = 10
+	// DefaultMaxPageSize is the largest page_size a client can request when the server isn't configured with another one
+	DefaultMaxPageSize = 100
+)
+
+// pagination is the page requested with the page and page_size query params
+type pagination struct {
+	page     int
+	pageSize int
+}
+
+func (p pagination) limit() int {
+	return p.pageSize
+}
+
+func (p pagination) offset() int {
+	return (p.page - 1) * p.pageSize
+}
+
+// bindPagination reads the page and page_size query params, page defaults to 1 and page_size to defaultPageSize
+func (h *handler) bindPagination(ctx echo.Context) (pagination, error) {
+	p := pagination{
+		page:     1,
+		pageSize: defaultPageSize,
+	}
+
+	err := echo.QueryParamsBinder(ctx).
+		Int("page", &p.page).
+		Int("page_size", &p.pageSize).
+		BindError()
+	if err != nil {
+		bindingErr := err.(*echo.BindingError)
+		return pagination{}, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid value for '%s'", bindingErr.Field))
+	}
+
+	if p.page <= 0 {
+		p.page = 1
+	}
+
+	maxPageSize := h.maxPageSize
+	if maxPageSize <= 0 {
+		maxPageSize = DefaultMaxPageSize
+	}
+
+	if p.pageSize < 1 || p.pageSize > maxPageSize {
+		return pagination{}, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("'page_size' should be between 1 and %d", maxPageSize))
+	}
+
+	return p, nil
+}
+
+// paginate returns the envelope shared by the list endpoints, the items are under the key, e.g.
+//
+//	{"books": [...], "total": 101, "page": 2, "page_size": 10, "pages": 11, "next": "/books?page=3&q=camus", "prev": "/books?page=1&q=camus"}
+//
+// total is -1 if the items were not counted, total and pages are then left out and there is a next page if this one is full.
+func paginate[T any](ctx echo.Context, key string, items []T, p pagination, total int) map[string]any {
+	response := map[string]any{
+		key:         items,
+		"page":      p.page,
+		"page_size": p.pageSize,
+		"next":      nil,
+		"prev":      nil,
+	}
+
+	hasNext := len(items) == p.pageSize
+
+	if total >= 0 {
+		pages := (total + p.pageSize - 1) / p.pageSize
+		hasNext = p.page < pages
+
+		response["total"] = total
+		response["pages"] = pages
+	}
+
+	if hasNext {
+		response["next"] = pageLink(ctx, "page", strconv.Itoa(p.page+1))
+	}
+
+	if p.page > 1 {
+		response["prev"] = pageLink(ctx, "page", strconv.Itoa(p.page-1))
+	}
+
+	return response
+}
+
+// pageLink returns the url of the request with the query param set to the value, the other query params are kept as is
+func pageLink(ctx echo.Context, param string, value string) string {
+	u := *ctx.Request().URL
+	query := u.Query()
+	query.Set(param, value)
+
+	// a cursor link continues after the cursor whatever the page is
+	if param == "cursor" {
+		query.Del("page")
+	}
+
+	return u.Path + "?" + query.Encode()
+}
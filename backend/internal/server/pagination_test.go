@@ -0,0 +1,167 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBindPagination(t *testing.T) {
+	tests := []struct {
+		expectedOutput any
+		name           string
+		query          string
+		maxPageSize    int
+	}{
+		{
+			name:           "Default",
+			expectedOutput: pagination{page: 1, pageSize: 10},
+		},
+		{
+			name:           "Page and page size",
+			query:          "?page=3&page_size=25",
+			expectedOutput: pagination{page: 3, pageSize: 25},
+		},
+		{
+			name:           "Page below 1",
+			query:          "?page=-1",
+			expectedOutput: pagination{page: 1, pageSize: 10},
+		},
+		{
+			name:           "Default max page size",
+			query:          "?page_size=100",
+			expectedOutput: pagination{page: 1, pageSize: 100},
+		},
+		{
+			name:           "Exceed default max page size",
+			query:          "?page_size=101",
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'page_size' should be between 1 and 100"),
+		},
+		{
+			name:           "Exceed configured max page size",
+			query:          "?page_size=21",
+			maxPageSize:    20,
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'page_size' should be between 1 and 20"),
+		},
+		{
+			name:           "Zero page size",
+			query:          "?page_size=0",
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "'page_size' should be between 1 and 100"),
+		},
+		{
+			name:           "Invalid page size",
+			query:          "?page_size=j",
+			expectedOutput: echo.NewHTTPError(http.StatusBadRequest, "invalid value for 'page_size'"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, _ := newEchoContext(t, http.MethodGet, "/books"+test.query, nil)
+			h := handler{maxPageSize: test.maxPageSize}
+
+			p, err := h.bindPagination(ctx)
+			if err != nil {
+				assert.Equal(t, test.expectedOutput, err)
+				return
+			}
+
+			assert.Equal(t, test.expectedOutput, p)
+		})
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	tests := []struct {
+		expectedOutput map[string]any
+		name           string
+		query          string
+		items          []int
+		p              pagination
+		total          int
+	}{
+		{
+			name:  "First page",
+			query: "?q=camus",
+			items: []int{1, 2},
+			p:     pagination{page: 1, pageSize: 2},
+			total: 5,
+			expectedOutput: map[string]any{
+				"items":     []int{1, 2},
+				"total":     5,
+				"page":      1,
+				"page_size": 2,
+				"pages":     3,
+				"next":      "/books?page=2&q=camus",
+				"prev":      nil,
+			},
+		},
+		{
+			name:  "Last page",
+			query: "?page=3&page_size=2&q=camus",
+			items: []int{5},
+			p:     pagination{page: 3, pageSize: 2},
+			total: 5,
+			expectedOutput: map[string]any{
+				"items":     []int{5},
+				"total":     5,
+				"page":      3,
+				"page_size": 2,
+				"pages":     3,
+				"next":      nil,
+				"prev":      "/books?page=2&page_size=2&q=camus",
+			},
+		},
+		{
+			name:  "Empty",
+			items: []int{},
+			p:     pagination{page: 1, pageSize: 10},
+			expectedOutput: map[string]any{
+				"items":     []int{},
+				"total":     0,
+				"page":      1,
+				"page_size": 10,
+				"pages":     0,
+				"next":      nil,
+				"prev":      nil,
+			},
+		},
+		{
+			name:  "Not counted full page",
+			query: "?page=2&page_size=2",
+			items: []int{3, 4},
+			p:     pagination{page: 2, pageSize: 2},
+			total: -1,
+			expectedOutput: map[string]any{
+				"items":     []int{3, 4},
+				"page":      2,
+				"page_size": 2,
+				"next":      "/books?page=3&page_size=2",
+				"prev":      "/books?page=1&page_size=2",
+			},
+		},
+		{
+			name:  "Not counted last page",
+			items: []int{1},
+			p:     pagination{page: 1, pageSize: 2},
+			total: -1,
+			expectedOutput: map[string]any{
+				"items":     []int{1},
+				"page":      1,
+				"page_size": 2,
+				"next":      nil,
+				"prev":      nil,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx, _ := newEchoContext(t, http.MethodGet, "/books"+test.query, nil)
+
+			assert.Equal(t, test.expectedOutput, paginate(ctx, "items", test.items, test.p, test.total))
+		})
+	}
+}
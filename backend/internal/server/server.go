@@ -2,24 +2,60 @@ package server
 
 import (
 	"github.com/cativovo/bookstore/internal/book"
+	"github.com/cativovo/bookstore/internal/cart"
+	"github.com/cativovo/bookstore/internal/catalog"
+	"github.com/cativovo/bookstore/internal/change"
+	"github.com/cativovo/bookstore/internal/cover"
+	"github.com/cativovo/bookstore/internal/exchange"
+	"github.com/cativovo/bookstore/internal/inventory"
+	"github.com/cativovo/bookstore/internal/order"
+	"github.com/cativovo/bookstore/internal/payment"
+	"github.com/cativovo/bookstore/internal/review"
+	"github.com/cativovo/bookstore/internal/user"
+	"github.com/cativovo/bookstore/internal/webhook"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
 
 type Server struct {
-	echo        *echo.Echo
-	bookService *book.BookService
+	echo             *echo.Echo
+	bookService      *book.BookService
+	inventoryService *inventory.InventoryService
+	cartService      *cart.CartService
+	orderService     *order.OrderService
+	paymentService   *payment.PaymentService
+	exchangeService  *exchange.ExchangeService
+	changeService    *change.ChangeService
+	webhookService   *webhook.WebhookService
+	userService      *user.UserService
+	reviewService    *review.ReviewService
+	coverService     *cover.CoverService
+	catalogService   *catalog.CatalogService
+	// maxPageSize is the largest page_size of the list endpoints
+	maxPageSize int
 }
 
-func NewServer(bs *book.BookService) *Server {
+func NewServer(bs *book.BookService, is *inventory.InventoryService, cs *cart.CartService, os *order.OrderService, ps *payment.PaymentService, es *exchange.ExchangeService, chs *change.ChangeService, ws *webhook.WebhookService, us *user.UserService, rs *review.ReviewService, cvs *cover.CoverService, cts *catalog.CatalogService, maxPageSize int) *Server {
 	e := echo.New()
 	e.Validator = NewValidator()
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
 
 	s := &Server{
-		echo:        e,
-		bookService: bs,
+		echo:             e,
+		bookService:      bs,
+		inventoryService: is,
+		cartService:      cs,
+		orderService:     os,
+		paymentService:   ps,
+		exchangeService:  es,
+		changeService:    chs,
+		webhookService:   ws,
+		userService:      us,
+		reviewService:    rs,
+		coverService:     cvs,
+		catalogService:   cts,
+		maxPageSize:      maxPageSize,
 	}
 
 	s.registerHandlers()
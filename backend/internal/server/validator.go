@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"strings"
 
+	"github.com/cativovo/bookstore/internal/book"
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 )
@@ -31,6 +32,14 @@ type Validator struct {
 }
 
 func (v *Validator) Validate(s any) error {
+	if err := v.ValidateStruct(s); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return nil
+}
+
+// ValidateStruct is Validate without the http error, it validates the structs read outside of a request such as the rows of an import.
+func (v *Validator) ValidateStruct(s any) error {
 	if err := v.validator.Struct(s); err != nil {
 		var vErrs validationErrors
 
@@ -45,19 +54,57 @@ func (v *Validator) Validate(s any) error {
 						e = fmt.Errorf("'%s' is required with '%s'", err.Field(), jsonTag)
 					}
 				}
+			case "required_without":
+				if field, ok := reflect.TypeOf(s).Elem().FieldByName(err.Param()); ok {
+					if jsonTag, ok := field.Tag.Lookup("json"); ok {
+						e = fmt.Errorf("'%s' is required without '%s'", err.Field(), jsonTag)
+					}
+				}
+			case "excluded_with":
+				if field, ok := reflect.TypeOf(s).Elem().FieldByName(err.Param()); ok {
+					if jsonTag, ok := field.Tag.Lookup("json"); ok {
+						e = fmt.Errorf("'%s' should not be set with '%s'", err.Field(), jsonTag)
+					}
+				}
 			case "number":
 				e = fmt.Errorf("'%s' should have numeric value", err.Field())
 			case "gte":
 				e = fmt.Errorf("'%s' should be greater than or equal to %s", err.Field(), err.Param())
+			case "lte":
+				e = fmt.Errorf("'%s' should be less than or equal to %s", err.Field(), err.Param())
+			case "lt":
+				e = fmt.Errorf("'%s' should be less than %s", err.Field(), err.Param())
 			case "gt":
 				e = fmt.Errorf("'%s' should be greater than %s", err.Field(), err.Param())
+			case "ne":
+				e = fmt.Errorf("'%s' should not be equal to %s", err.Field(), err.Param())
+			case "oneof":
+				e = fmt.Errorf("'%s' should be one of [%s]", err.Field(), strings.ReplaceAll(err.Param(), " ", ", "))
+			case "iso4217":
+				e = fmt.Errorf("'%s' should be an ISO 4217 currency code", err.Field())
+			case "email":
+				e = fmt.Errorf("'%s' should be an email address", err.Field())
+			case "http_url":
+				e = fmt.Errorf("'%s' should be an http or https url", err.Field())
+			case "unique":
+				e = fmt.Errorf("'%s' should not have duplicates", err.Field())
+			case "isbn":
+				e = fmt.Errorf("'%s' should be an ISBN-10 or ISBN-13", err.Field())
+			case "series_position":
+				e = fmt.Errorf("'%s' should be between 0.01 and 9999.99 with at most 2 decimals", err.Field())
+			case "bcp47_language_tag":
+				e = fmt.Errorf("'%s' should be a BCP 47 language tag", err.Field())
+			case "min":
+				e = fmt.Errorf("'%s' should have a length of at least %s", err.Field(), err.Param())
+			case "max":
+				e = fmt.Errorf("'%s' should have a length of at most %s", err.Field(), err.Param())
 			default:
 				e = fmt.Errorf("'%s': '%v' must satisfy '%s' '%v' criteria", err.Field(), err.Value(), err.Tag(), err.Param())
 			}
 			vErrs = append(vErrs, e)
 		}
 
-		return echo.NewHTTPError(http.StatusBadRequest, vErrs.Error())
+		return vErrs
 	}
 	return nil
 }
@@ -65,6 +112,26 @@ func (v *Validator) Validate(s any) error {
 func NewValidator() *Validator {
 	v := validator.New()
 
+	// money is validated by its amount so rules like gt=0 work on prices
+	v.RegisterCustomTypeFunc(func(field reflect.Value) any {
+		if m, ok := field.Interface().(book.Money); ok {
+			return m.Amount
+		}
+		return nil
+	}, book.Money{})
+
+	// replaces the isbn tag of the validator, which doesn't ignore the hyphens the same way book.ParseISBN does,
+	// an empty isbn is valid so it can clear the isbn of a book
+	v.RegisterValidation("isbn", func(fl validator.FieldLevel) bool {
+		isbn := fl.Field().String()
+		return isbn == "" || book.ValidISBN(isbn)
+	})
+
+	// the position is stored as NUMERIC(6, 2), it would be rounded otherwise
+	v.RegisterValidation("series_position", func(fl validator.FieldLevel) bool {
+		return book.ValidSeriesPosition(fl.Field().Float())
+	})
+
 	// https://github.com/go-playground/validator/issues/861
 	v.RegisterTagNameFunc(func(fld reflect.StructField) string {
 		name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
@@ -0,0 +1,183 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/cativovo/bookstore/internal/book"
+	query "github.com/cativovo/bookstore/internal/storage/postgres/generated"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (pr *PostgresRepository) GetAuthors(ctx context.Context, name string, limit int, offset int) ([]book.Author, int, error) {
+	type result struct {
+		rows  []query.GetAuthorsRow
+		count int64
+	}
+
+	pattern := appendPatternWildcard(name)
+
+	r, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (result, error) {
+		count, err := pr.queries.CountAuthors(ctxWithTimeout, pattern)
+		if err != nil {
+			return result{}, err
+		}
+
+		rows, err := pr.queries.GetAuthors(ctxWithTimeout, query.GetAuthorsParams{
+			Name:        pattern,
+			LimitCount:  int32(limit),
+			OffsetCount: int32(offset),
+		})
+		if err != nil {
+			return result{}, err
+		}
+
+		return result{rows: rows, count: count}, nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	authors := make([]book.Author, len(r.rows))
+
+	for i, v := range r.rows {
+		a, err := toAuthor(query.GetAuthorByIdRow(v))
+		if err != nil {
+			return nil, 0, err
+		}
+
+		authors[i] = a
+	}
+
+	return authors, int(r.count), nil
+}
+
+func (pr *PostgresRepository) GetAuthorById(ctx context.Context, id string) (book.Author, error) {
+	var uuid pgtype.UUID
+	if err := uuid.Scan(id); err != nil {
+		return book.Author{}, book.ErrAuthorNotFound
+	}
+
+	row, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (query.GetAuthorByIdRow, error) {
+		return pr.queries.GetAuthorById(ctxWithTimeout, uuid)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return book.Author{}, book.ErrAuthorNotFound
+		}
+
+		return book.Author{}, err
+	}
+
+	return toAuthor(row)
+}
+
+func toAuthor(row query.GetAuthorByIdRow) (book.Author, error) {
+	id, err := row.ID.Value()
+	if err != nil {
+		return book.Author{}, err
+	}
+
+	return book.Author{
+		CreatedAt: row.CreatedAt.Time,
+		Id:        id.(string),
+		Name:      row.Name,
+		Bio:       row.Bio.String,
+		PhotoUrl:  row.PhotoUrl.String,
+		BookCount: int(row.BookCount),
+	}, nil
+}
+
+// upsertAuthors returns the credits with the id and the name of their author, the authors that don't exist are created
+func upsertAuthors(ctx context.Context, qtx *query.Queries, authors []book.BookAuthor) ([]book.BookAuthor, error) {
+	upserted := make([]book.BookAuthor, len(authors))
+
+	for i, v := range authors {
+		row, err := qtx.UpsertAuthor(ctx, v.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		id, err := row.ID.Value()
+		if err != nil {
+			return nil, err
+		}
+
+		upserted[i] = book.BookAuthor{
+			Id:   id.(string),
+			Name: row.Name,
+			Role: v.Role,
+		}
+	}
+
+	return upserted, nil
+}
+
+// replaceBookAuthors replaces the credits of the book, the credits have to be upserted first
+func replaceBookAuthors(ctx context.Context, qtx *query.Queries, bookUuid pgtype.UUID, authors []book.BookAuthor) error {
+	if err := qtx.DeleteBookAuthors(ctx, bookUuid); err != nil {
+		return err
+	}
+
+	for i, v := range authors {
+		var authorUuid pgtype.UUID
+		if err := authorUuid.Scan(v.Id); err != nil {
+			return err
+		}
+
+		err := qtx.CreateBookAuthor(ctx, query.CreateBookAuthorParams{
+			BookID:   bookUuid,
+			AuthorID: authorUuid,
+			Role:     string(v.Role),
+			Position: int16(i),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// bookAuthorsOptions returns the credits the options of UpdateBook set on the book, nil if the credits are not changed
+func bookAuthorsOptions(ctx context.Context, qtx *query.Queries, bookUuid pgtype.UUID, opts book.UpdateBookOptions) ([]book.BookAuthor, error) {
+	switch {
+	case opts.Authors != nil:
+		return *opts.Authors, nil
+	case opts.Author != nil:
+		// the author replaces the authors, the translators, illustrators and editors are kept
+		authors := []book.BookAuthor{{Name: *opts.Author, Role: book.AuthorRoleAuthor}}
+
+		rows, err := qtx.GetBookAuthors(ctx, bookUuid)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range rows {
+			if book.AuthorRole(v.Role) != book.AuthorRoleAuthor {
+				authors = append(authors, book.BookAuthor{Name: v.Name, Role: book.AuthorRole(v.Role)})
+			}
+		}
+
+		return authors, nil
+	default:
+		return nil, nil
+	}
+}
+
+// toBookAuthors decodes the credits aggregated by the queries of the books
+func toBookAuthors(b []byte) ([]book.BookAuthor, error) {
+	authors := make([]book.BookAuthor, 0)
+
+	if len(b) == 0 {
+		return authors, nil
+	}
+
+	if err := json.Unmarshal(b, &authors); err != nil {
+		return nil, err
+	}
+
+	return authors, nil
+}
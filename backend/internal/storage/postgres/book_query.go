@@ -0,0 +1,824 @@
+package postgres
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cativovo/bookstore/internal/book"
+	query "github.com/cativovo/bookstore/internal/storage/postgres/generated"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// sortKey is a column of filtered_books the books are ordered by
+type sortKey struct {
+	column string
+	// the postgres type the value of the cursor is cast to
+	cast string
+	desc bool
+}
+
+func (k sortKey) String() string {
+	if k.desc {
+		return k.column + " DESC"
+	}
+
+	return k.column + " ASC"
+}
+
+// sortColumns maps the sort fields of book.SortKey to the columns of filtered_books, only these can be sorted by
+var sortColumns = map[string]sortKey{
+	book.SortByTitle:          {column: "title", cast: "text"},
+	book.SortByAuthor:         {column: "author", cast: "text"},
+	book.SortByPrice:          {column: "price", cast: "numeric"},
+	book.SortByCreatedAt:      {column: "created_at", cast: "timestamptz"},
+	book.SortByUpdatedAt:      {column: "updated_at", cast: "timestamptz"},
+	book.SortByRating:         {column: "rating", cast: "numeric"},
+	book.SortBySeriesPosition: {column: "series_order", cast: "numeric"},
+	book.OrderByRelevance:     {column: "rank", cast: "real"},
+}
+
+// the id makes the order unique, a cursor can't point between two books with the same sort values
+var sortKeyId = sortKey{column: "id", cast: "uuid"}
+
+// orderBySort returns the sort of the OrderBy and Desc options, the title and the author break the ties
+func orderBySort(orderBy string, desc bool) []book.SortKey {
+	var fields []string
+
+	switch orderBy {
+	case book.OrderByRelevance:
+		// the most relevant books come first whatever the direction
+		return []book.SortKey{
+			{Field: book.OrderByRelevance},
+			{Field: book.SortByTitle, Desc: desc},
+			{Field: book.SortByAuthor, Desc: desc},
+		}
+	case book.SortByPrice:
+		fields = []string{book.SortByPrice, book.SortByTitle, book.SortByAuthor}
+	case book.SortByAuthor:
+		fields = []string{book.SortByAuthor, book.SortByTitle}
+	case book.SortByRating:
+		fields = []string{book.SortByRating, book.SortByTitle, book.SortByAuthor}
+	default:
+		fields = []string{book.SortByTitle, book.SortByAuthor}
+	}
+
+	sort := make([]book.SortKey, len(fields))
+	for i, field := range fields {
+		sort[i] = book.SortKey{Field: field, Desc: desc}
+	}
+
+	return sort
+}
+
+// bookSortKeys returns the keys of the order, e.g. price ASC, title DESC, id ASC
+func bookSortKeys(opts book.GetBooksOptions) ([]sortKey, error) {
+	sort := opts.Sort
+	if len(sort) == 0 {
+		sort = orderBySort(opts.OrderBy, opts.Desc)
+	}
+
+	keys := make([]sortKey, 0, len(sort)+1)
+
+	for _, v := range sort {
+		k, ok := sortColumns[v.Field]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown field '%s'", book.ErrInvalidSort, v.Field)
+		}
+
+		k.desc = v.Desc
+		// a higher rank is a better match
+		if v.Field == book.OrderByRelevance {
+			k.desc = !v.Desc
+		}
+
+		keys = append(keys, k)
+	}
+
+	return append(keys, sortKeyId), nil
+}
+
+// bookCursor is the position of the last book of a page, it is sent to the client as an opaque string
+type bookCursor struct {
+	// Key is a hash of the options the cursor was made with
+	Key string `json:"k"`
+	// Values are the values of the sort keys of the book
+	Values []string `json:"v"`
+}
+
+// cursorKey identifies the order and the filter of the books, a cursor is only valid for the books it was made with
+func cursorKey(opts book.GetBooksOptions, keys []sortKey) (string, error) {
+	orderBy := make([]string, len(keys))
+	for i, k := range keys {
+		orderBy[i] = k.String()
+	}
+
+	filter, err := json.Marshal(cursorFilter(opts.Filter))
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join([]string{strings.Join(orderBy, ","), opts.Currency, string(filter)}, "\x00")))
+
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// cursorFilter returns the filter the way it is matched, filters that match the same books make the same key
+func cursorFilter(f book.GetBooksFilter) book.GetBooksFilter {
+	// the names are matched case insensitive
+	f.Author = strings.ToLower(f.Author)
+	f.Title = strings.ToLower(f.Title)
+	f.Genres = cursorGenres(f.Genres)
+	f.ExcludeGenres = cursorGenres(f.ExcludeGenres)
+
+	if f.GenreMatch == "" {
+		f.GenreMatch = book.GenreMatchAny
+	}
+
+	if f.CreatedSince != nil {
+		t := f.CreatedSince.UTC()
+		f.CreatedSince = &t
+	}
+
+	if f.UpdatedSince != nil {
+		t := f.UpdatedSince.UTC()
+		f.UpdatedSince = &t
+	}
+
+	return f
+}
+
+// cursorGenres returns the genres lowercased, sorted and without duplicates, the order of the genres doesn't change the books
+func cursorGenres(genres []string) []string {
+	normalized := make([]string, len(genres))
+	for i, v := range genres {
+		normalized[i] = strings.ToLower(v)
+	}
+
+	slices.Sort(normalized)
+
+	return slices.Compact(normalized)
+}
+
+func encodeBookCursor(c bookCursor) (string, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeBookCursor(s string, key string, keys []sortKey) (bookCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return bookCursor{}, book.ErrInvalidCursor
+	}
+
+	var c bookCursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return bookCursor{}, book.ErrInvalidCursor
+	}
+
+	if c.Key != key || len(c.Values) != len(keys) {
+		return bookCursor{}, book.ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+// the price in the requested currency, the override of the book takes precedence over the conversion
+const bookPriceInCurrency = "COALESCE(book_price.price, convert_price(book.price, exchange_rate.rate, exchange_rate.rounding), book.price)::numeric"
+
+// booksQuery builds the query of GetBooks, sqlc can't be used since the filters and the order are only known at runtime
+type booksQuery struct {
+	args []any
+}
+
+// arg adds the value to the arguments of the query and returns its placeholder
+func (bq *booksQuery) arg(v any) string {
+	bq.args = append(bq.args, v)
+	return fmt.Sprintf("$%d", len(bq.args))
+}
+
+// filteredBooks returns the filtered_books CTE, the books matching the filter with the price in the requested currency
+func (bq *booksQuery) filteredBooks(opts book.GetBooksOptions) string {
+	currency := bq.arg(opts.Currency)
+
+	rank := "0::real"
+	var where []string
+
+	if opts.Filter.Query != "" {
+		tsquery := fmt.Sprintf("websearch_to_tsquery('english', %s::text)", bq.arg(opts.Filter.Query))
+		rank = fmt.Sprintf("ts_rank(book.search_vector, %s)", tsquery)
+		where = append(where, fmt.Sprintf("book.search_vector @@ %s", tsquery))
+	}
+
+	switch {
+	case opts.Filter.Author != "" && opts.Filter.ExactAuthor:
+		where = append(where, fmt.Sprintf("book.id IN (%s)", bq.booksWithAuthor(fmt.Sprintf("LOWER(author.name) = LOWER(%s::text)", bq.arg(opts.Filter.Author)))))
+	case opts.Filter.Author != "":
+		where = append(where, fmt.Sprintf("book.id IN (%s)", bq.booksWithAuthor(fmt.Sprintf("author.name ILIKE %s", bq.arg(appendPatternWildcard(opts.Filter.Author))))))
+	}
+
+	if opts.Filter.AuthorId != "" {
+		where = append(where, fmt.Sprintf("book.id IN (SELECT book_author.book_id FROM book_author WHERE book_author.author_id = %s::uuid)", bq.arg(opts.Filter.AuthorId)))
+	}
+
+	if opts.Filter.Title != "" {
+		where = append(where, fmt.Sprintf("book.title ILIKE %s", bq.arg(appendPatternWildcard(opts.Filter.Title))))
+	}
+
+	if opts.Filter.InStock != nil {
+		where = append(where, fmt.Sprintf("(COALESCE(stock.quantity_on_hand, 0) - COALESCE(stock.reserved, 0) > 0) = %s::boolean", bq.arg(*opts.Filter.InStock)))
+	}
+
+	switch {
+	case len(opts.Filter.Genres) > 0 && opts.Filter.GenreMatch == book.GenreMatchAll:
+		where = append(where, fmt.Sprintf("book.id IN (%s)", bq.booksWithAllGenres(opts.Filter.Genres)))
+	case len(opts.Filter.Genres) > 0:
+		where = append(where, fmt.Sprintf("book.id IN (%s)", bq.booksWithGenres(opts.Filter.Genres)))
+	}
+
+	if len(opts.Filter.ExcludeGenres) > 0 {
+		where = append(where, fmt.Sprintf("book.id NOT IN (%s)", bq.booksWithGenres(opts.Filter.ExcludeGenres)))
+	}
+
+	if opts.Filter.MinPrice != nil {
+		where = append(where, fmt.Sprintf("%s >= %s::numeric", bookPriceInCurrency, bq.arg(toNumeric(*opts.Filter.MinPrice))))
+	}
+
+	if opts.Filter.MaxPrice != nil {
+		where = append(where, fmt.Sprintf("%s <= %s::numeric", bookPriceInCurrency, bq.arg(toNumeric(*opts.Filter.MaxPrice))))
+	}
+
+	if opts.Filter.CreatedSince != nil {
+		where = append(where, fmt.Sprintf("book.created_at >= %s::timestamptz", bq.arg(*opts.Filter.CreatedSince)))
+	}
+
+	if opts.Filter.UpdatedSince != nil {
+		where = append(where, fmt.Sprintf("book.updated_at >= %s::timestamptz", bq.arg(*opts.Filter.UpdatedSince)))
+	}
+
+	if opts.Filter.MinRating != nil {
+		where = append(where, fmt.Sprintf("book.average_rating >= %s::numeric", bq.arg(*opts.Filter.MinRating)))
+	}
+
+	if opts.Filter.SeriesId != "" {
+		where = append(where, fmt.Sprintf("book.series_id = %s::uuid", bq.arg(opts.Filter.SeriesId)))
+	}
+
+	whereClause := ""
+	if len(where) > 0 {
+		whereClause = "WHERE\n      " + strings.Join(where, "\n    AND\n      ")
+	}
+
+	return fmt.Sprintf(`WITH
+filtered_books AS (
+    SELECT
+      book.id AS id,
+      book.title AS title,
+      book.description AS description,
+      book.author AS author,
+      %[4]s AS price,
+      book.cover_image AS cover_image,
+      book.created_at AS created_at,
+      book.updated_at AS updated_at,
+      book.average_rating AS average_rating,
+      book.review_count AS review_count,
+      (
+        SELECT
+          COALESCE(JSON_AGG(JSON_BUILD_OBJECT('id', author.id, 'name', author.name, 'role', book_author.role) ORDER BY book_author.position), '[]')
+        FROM
+          book_author
+        INNER JOIN
+          author ON author.id = book_author.author_id
+        WHERE
+          book_author.book_id = book.id
+      ) AS authors,
+      book.isbn13 AS isbn13,
+      book.publisher AS publisher,
+      book.publication_date AS publication_date,
+      book.edition AS edition,
+      book.page_count AS page_count,
+      book.language AS language,
+      book.format AS format,
+      (
+        SELECT JSON_BUILD_OBJECT('id', series.id, 'name', series.name) FROM series WHERE series.id = book.series_id
+      ) AS series,
+      book.series_position AS series_position,
+      -- a null can't be compared with the value of a cursor
+      COALESCE(book.average_rating, 0)::numeric AS rating,
+      COALESCE(book.series_position, 0)::numeric AS series_order,
+      COALESCE(stock.quantity_on_hand, 0)::integer AS quantity_on_hand,
+      COALESCE(stock.reserved, 0)::integer AS reserved,
+      COALESCE(ARRAY_AGG(genre.name::text) FILTER (WHERE genre.name IS NOT NULL), '{}') AS genres,
+      COALESCE(%[1]s, 0)::real AS rank
+    FROM
+      book
+    LEFT JOIN
+      stock ON stock.book_id = book.id
+    LEFT JOIN
+      exchange_rate ON exchange_rate.currency = %[2]s::text
+    LEFT JOIN
+      book_price ON book_price.book_id = book.id AND book_price.currency = %[2]s::text
+    LEFT JOIN
+      book_genre ON book_genre.book_id = book.id
+    LEFT JOIN
+      genre ON genre.id = book_genre.genre_id
+    %[3]s
+    GROUP BY
+      book.id, stock.book_id, exchange_rate.currency, book_price.book_id, book_price.currency
+)
+`, rank, currency, whereClause, bookPriceInCurrency)
+}
+
+// booksWithAuthor returns the subquery of the ids of the books crediting an author matching the condition as an author
+func (bq *booksQuery) booksWithAuthor(condition string) string {
+	return fmt.Sprintf(`
+        SELECT book_author.book_id FROM author
+        INNER JOIN book_author ON book_author.author_id = author.id AND book_author.role = 'author'
+        WHERE %s
+      `, condition)
+}
+
+// booksWithGenres returns the subquery of the ids of the books that have any of the genres
+func (bq *booksQuery) booksWithGenres(genres []string) string {
+	return fmt.Sprintf(`
+        SELECT book_genre.book_id FROM genre
+        INNER JOIN book_genre ON book_genre.genre_id = genre.id AND genre.name ILIKE ANY(%s::text[])
+      `, bq.arg(genres))
+}
+
+// booksWithAllGenres returns the subquery of the ids of the books that have every genre,
+// a book with a genre matching more than one pattern still needs a genre for each of the other patterns
+func (bq *booksQuery) booksWithAllGenres(genres []string) string {
+	return fmt.Sprintf(`
+        SELECT book_genre.book_id FROM book_genre
+        INNER JOIN genre ON genre.id = book_genre.genre_id
+        INNER JOIN UNNEST(%[1]s::text[]) AS patterns(pattern) ON genre.name ILIKE patterns.pattern
+        GROUP BY book_genre.book_id
+        HAVING COUNT(DISTINCT patterns.pattern) = (SELECT COUNT(DISTINCT pattern) FROM UNNEST(%[1]s::text[]) AS pattern)
+      `, bq.arg(genres))
+}
+
+// after returns the condition of the books after the values of the cursor,
+// a row comparison like (title, id) > ($1, $2) can't be used since the keys don't always have the same direction
+func (bq *booksQuery) after(keys []sortKey, values []string) string {
+	placeholders := make([]string, len(keys))
+	for i, k := range keys {
+		placeholders[i] = fmt.Sprintf("%s::%s", bq.arg(values[i]), k.cast)
+	}
+
+	conditions := make([]string, len(keys))
+
+	for i, k := range keys {
+		var and []string
+
+		for j := 0; j < i; j++ {
+			and = append(and, fmt.Sprintf("%s = %s", keys[j].column, placeholders[j]))
+		}
+
+		op := ">"
+		if k.desc {
+			op = "<"
+		}
+
+		and = append(and, fmt.Sprintf("%s %s %s", k.column, op, placeholders[i]))
+		conditions[i] = "(" + strings.Join(and, " AND ") + ")"
+	}
+
+	return strings.Join(conditions, " OR ")
+}
+
+func (pr *PostgresRepository) GetBooks(ctx context.Context, opts book.GetBooksOptions) (book.BooksPage, error) {
+	keys, err := bookSortKeys(opts)
+	if err != nil {
+		return book.BooksPage{}, err
+	}
+	key, err := cursorKey(opts, keys)
+	if err != nil {
+		return book.BooksPage{}, err
+	}
+
+	var bq booksQuery
+	cte := bq.filteredBooks(opts)
+	countArgs := len(bq.args)
+
+	sortValues := make([]string, len(keys))
+	orderBy := make([]string, len(keys))
+	for i, k := range keys {
+		sortValues[i] = k.column + "::text"
+		orderBy[i] = k.String()
+	}
+
+	var pagination string
+	if opts.Cursor != "" {
+		c, err := decodeBookCursor(opts.Cursor, key, keys)
+		if err != nil {
+			return book.BooksPage{}, err
+		}
+
+		pagination = fmt.Sprintf("WHERE\n  %s\n", bq.after(keys, c.Values))
+	}
+
+	// one more book tells if there is a page after this one
+	pagination += fmt.Sprintf("ORDER BY\n  %s\nLIMIT\n  %s\n", strings.Join(orderBy, ", "), bq.arg(opts.Limit+1))
+	if opts.Cursor == "" {
+		pagination += fmt.Sprintf("OFFSET\n  %s\n", bq.arg(opts.Offset))
+	}
+
+	sql := cte + fmt.Sprintf(`SELECT
+  id,
+  title,
+  description,
+  author,
+  authors,
+  price,
+  cover_image,
+  quantity_on_hand,
+  reserved,
+  genres,
+  created_at,
+  updated_at,
+  average_rating,
+  review_count,
+  isbn13,
+  publisher,
+  publication_date,
+  edition,
+  page_count,
+  language,
+  format,
+  series,
+  series_position,
+  ARRAY[%s] AS sort_values
+FROM
+  filtered_books
+%s`, strings.Join(sortValues, ", "), pagination)
+
+	type row struct {
+		sortValues []string
+		book       book.Book
+	}
+
+	type result struct {
+		facets *book.Facets
+		rows   []row
+		count  int64
+	}
+
+	r, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (result, error) {
+		if err := checkCurrency(ctxWithTimeout, pr.queries, opts.Currency); err != nil {
+			return result{}, err
+		}
+
+		count := int64(-1)
+		if !opts.SkipCount {
+			if err := pr.pool.QueryRow(ctxWithTimeout, cte+"SELECT COUNT(*) FROM filtered_books", bq.args[:countArgs]...).Scan(&count); err != nil {
+				return result{}, err
+			}
+		}
+
+		rows, err := pr.pool.Query(ctxWithTimeout, sql, bq.args...)
+		if err != nil {
+			return result{}, err
+		}
+		defer rows.Close()
+
+		var r result
+
+		for rows.Next() {
+			var (
+				id          pgtype.UUID
+				authors     []byte
+				description pgtype.Text
+				price       pgtype.Numeric
+				coverImage  pgtype.Text
+				quantity    int32
+				reserved    int32
+				rating      pgtype.Numeric
+				reviewCount int32
+				isbn13      pgtype.Text
+				publisher   pgtype.Text
+				published   pgtype.Date
+				edition     pgtype.Text
+				pageCount   pgtype.Int4
+				language    pgtype.Text
+				format      pgtype.Text
+				series      []byte
+				position    pgtype.Numeric
+				v           row
+			)
+
+			if err := rows.Scan(
+				&id,
+				&v.book.Title,
+				&description,
+				&v.book.Author,
+				&authors,
+				&price,
+				&coverImage,
+				&quantity,
+				&reserved,
+				&v.book.Genres,
+				&v.book.CreatedAt,
+				&v.book.UpdatedAt,
+				&rating,
+				&reviewCount,
+				&isbn13,
+				&publisher,
+				&published,
+				&edition,
+				&pageCount,
+				&language,
+				&format,
+				&series,
+				&position,
+				&v.sortValues,
+			); err != nil {
+				return result{}, err
+			}
+
+			bookId, err := id.Value()
+			if err != nil {
+				return result{}, err
+			}
+
+			v.book.Price, err = toMoney(price, opts.Currency)
+			if err != nil {
+				return result{}, err
+			}
+
+			v.book.Authors, err = toBookAuthors(authors)
+			if err != nil {
+				return result{}, err
+			}
+
+			v.book.Id = bookId.(string)
+			v.book.Description = description.String
+			v.book.CoverImage = book.CoverImage(coverImage.String)
+			v.book.QuantityOnHand = int(quantity)
+			v.book.Reserved = int(reserved)
+			v.book.ReviewCount = int(reviewCount)
+			v.book.Isbn13 = isbn13.String
+			v.book.Isbn10, _ = book.ISBN10(isbn13.String)
+			v.book.Publisher = publisher.String
+			v.book.PublicationDate = toDate(published)
+			v.book.Edition = edition.String
+			v.book.PageCount = toPageCount(pageCount)
+			v.book.Language = language.String
+			v.book.Format = book.Format(format.String)
+
+			v.book.AverageRating, err = toRating(rating)
+			if err != nil {
+				return result{}, err
+			}
+
+			v.book.Series, err = toBookSeries(series)
+			if err != nil {
+				return result{}, err
+			}
+
+			v.book.SeriesPosition, err = toSeriesPosition(position)
+			if err != nil {
+				return result{}, err
+			}
+
+			r.rows = append(r.rows, v)
+		}
+
+		if err := rows.Err(); err != nil {
+			return result{}, err
+		}
+
+		r.count = count
+
+		if opts.Facets {
+			facets, err := pr.getBookFacets(ctxWithTimeout, opts)
+			if err != nil {
+				return result{}, err
+			}
+
+			r.facets = &facets
+		}
+
+		return r, nil
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if opts.Cursor != "" && errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			// the values of the cursor have been tampered with
+			case pgerrcode.InvalidTextRepresentation, pgerrcode.NumericValueOutOfRange:
+				return book.BooksPage{}, book.ErrInvalidCursor
+			}
+		}
+
+		return book.BooksPage{}, err
+	}
+
+	page := book.BooksPage{
+		Books:  make([]book.Book, 0, len(r.rows)),
+		Count:  int(r.count),
+		Facets: r.facets,
+	}
+
+	if opts.Limit > 0 && len(r.rows) > opts.Limit {
+		r.rows = r.rows[:opts.Limit]
+
+		page.NextCursor, err = encodeBookCursor(bookCursor{
+			Key:    key,
+			Values: r.rows[len(r.rows)-1].sortValues,
+		})
+		if err != nil {
+			return book.BooksPage{}, err
+		}
+	}
+
+	for _, v := range r.rows {
+		page.Books = append(page.Books, v.book)
+	}
+
+	return page, nil
+}
+
+// the number of authors in the facets, the catalog has too many authors to count them all
+const authorFacetLimit = 20
+
+// getBookFacets counts the books matching the options by genre, author and price bucket,
+// every facet ignores its own filter so the counts of the values that are not picked are still useful
+func (pr *PostgresRepository) getBookFacets(ctx context.Context, opts book.GetBooksOptions) (book.Facets, error) {
+	var (
+		facets book.Facets
+		err    error
+	)
+
+	sql, args := genreFacetQuery(opts)
+	facets.Genres, err = pr.queryFacetCounts(ctx, sql, args)
+	if err != nil {
+		return book.Facets{}, err
+	}
+
+	sql, args = authorFacetQuery(opts)
+	facets.Authors, err = pr.queryFacetCounts(ctx, sql, args)
+	if err != nil {
+		return book.Facets{}, err
+	}
+
+	facets.Prices, err = pr.getPriceFacet(ctx, opts)
+	if err != nil {
+		return book.Facets{}, err
+	}
+
+	return facets, nil
+}
+
+// genreFacetQuery returns the query counting the books by genre and its arguments, the genres of the filter are ignored
+func genreFacetQuery(opts book.GetBooksOptions) (string, []any) {
+	opts.Filter.Genres = nil
+	opts.Filter.GenreMatch = ""
+
+	var bq booksQuery
+	sql := bq.filteredBooks(opts) + `SELECT
+  genre,
+  COUNT(*)
+FROM
+  filtered_books, UNNEST(filtered_books.genres) AS genre
+GROUP BY
+  genre
+ORDER BY
+  COUNT(*) DESC, genre
+`
+
+	return sql, bq.args
+}
+
+// authorFacetQuery returns the query counting the books by author and its arguments, the author of the filter is ignored.
+// The books are counted by each of their authors, a value filters the books with the exact author.
+func authorFacetQuery(opts book.GetBooksOptions) (string, []any) {
+	opts.Filter.Author = ""
+	opts.Filter.ExactAuthor = false
+
+	var bq booksQuery
+	cte := bq.filteredBooks(opts)
+	sql := cte + fmt.Sprintf(`SELECT
+  author.name,
+  COUNT(*)
+FROM
+  filtered_books
+INNER JOIN
+  book_author ON book_author.book_id = filtered_books.id AND book_author.role = 'author'
+INNER JOIN
+  author ON author.id = book_author.author_id
+GROUP BY
+  author.name
+ORDER BY
+  COUNT(*) DESC, author.name
+LIMIT
+  %s
+`, bq.arg(authorFacetLimit))
+
+	return sql, bq.args
+}
+
+// priceFacetQuery returns the query counting the books in the buckets between the boundaries and its arguments, the price range of the filter is ignored.
+// width_bucket returns 0 below the first boundary and the number of boundaries above the last one.
+func priceFacetQuery(opts book.GetBooksOptions, boundaries []pgtype.Numeric) (string, []any) {
+	opts.Filter.MinPrice = nil
+	opts.Filter.MaxPrice = nil
+
+	var bq booksQuery
+	cte := bq.filteredBooks(opts)
+	sql := cte + fmt.Sprintf(`SELECT
+  width_bucket(price, %s::numeric[]) AS bucket,
+  COUNT(*)
+FROM
+  filtered_books
+GROUP BY
+  bucket
+`, bq.arg(boundaries))
+
+	return sql, bq.args
+}
+
+func (pr *PostgresRepository) queryFacetCounts(ctx context.Context, sql string, args []any) ([]book.FacetCount, error) {
+	rows, err := pr.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make([]book.FacetCount, 0)
+
+	for rows.Next() {
+		var (
+			value string
+			count int64
+		)
+
+		if err := rows.Scan(&value, &count); err != nil {
+			return nil, err
+		}
+
+		counts = append(counts, book.FacetCount{Value: value, Count: int(count)})
+	}
+
+	return counts, rows.Err()
+}
+
+// getPriceFacet counts the books in each bucket of book.PriceFacetBoundaries converted to the currency of the options
+func (pr *PostgresRepository) getPriceFacet(ctx context.Context, opts book.GetBooksOptions) ([]book.PriceBucket, error) {
+	prices := make([]pgtype.Numeric, len(book.PriceFacetBoundaries))
+	for i, v := range book.PriceFacetBoundaries {
+		prices[i] = toNumeric(v)
+	}
+
+	converted, err := pr.queries.ConvertPrices(ctx, query.ConvertPricesParams{
+		Prices:   prices,
+		Currency: opts.Currency,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// bucket i holds the prices from boundary i-1 up to boundary i
+	buckets := make([]book.PriceBucket, len(converted)+1)
+
+	for i, v := range converted {
+		boundary, err := toMoney(v, opts.Currency)
+		if err != nil {
+			return nil, err
+		}
+
+		buckets[i].Max = &boundary
+		buckets[i+1].Min = &boundary
+	}
+
+	sql, args := priceFacetQuery(opts, converted)
+	rows, err := pr.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bucket, count int64
+
+		if err := rows.Scan(&bucket, &count); err != nil {
+			return nil, err
+		}
+
+		buckets[bucket].Count = int(count)
+	}
+
+	return buckets, rows.Err()
+}
@@ -0,0 +1,317 @@
+package postgres
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cativovo/bookstore/internal/book"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBookSortKeys(t *testing.T) {
+	tests := []struct {
+		name           string
+		opts           book.GetBooksOptions
+		expectedOutput []string
+		expectedErr    error
+	}{
+		{
+			name:           "Default",
+			opts:           book.GetBooksOptions{},
+			expectedOutput: []string{"title ASC", "author ASC", "id ASC"},
+		},
+		{
+			name:           "Order by price desc",
+			opts:           book.GetBooksOptions{OrderBy: book.SortByPrice, Desc: true},
+			expectedOutput: []string{"price DESC", "title DESC", "author DESC", "id ASC"},
+		},
+		{
+			name:           "Order by relevance",
+			opts:           book.GetBooksOptions{OrderBy: book.OrderByRelevance, Desc: true},
+			expectedOutput: []string{"rank DESC", "title DESC", "author DESC", "id ASC"},
+		},
+		{
+			name: "Sort",
+			opts: book.GetBooksOptions{
+				OrderBy: book.SortByAuthor,
+				Sort: []book.SortKey{
+					{Field: book.SortByCreatedAt, Desc: true},
+					{Field: book.SortBySeriesPosition},
+				},
+			},
+			expectedOutput: []string{"created_at DESC", "series_order ASC", "id ASC"},
+		},
+		{
+			name:        "Unknown field",
+			opts:        book.GetBooksOptions{Sort: []book.SortKey{{Field: "isbn"}}},
+			expectedErr: book.ErrInvalidSort,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			keys, err := bookSortKeys(test.opts)
+			if test.expectedErr != nil {
+				assert.ErrorIs(t, err, test.expectedErr)
+				return
+			}
+
+			orderBy := make([]string, len(keys))
+			for i, k := range keys {
+				orderBy[i] = k.String()
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedOutput, orderBy)
+		})
+	}
+}
+
+func TestAfter(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []any
+		keys           []sortKey
+		values         []string
+		expectedOutput string
+		expectedArgs   []any
+	}{
+		{
+			name:           "Single key",
+			keys:           []sortKey{sortKeyId},
+			values:         []string{"7e1f9a2b-3c4d-4e5f-a6b7-c8d9e0f1a2b3"},
+			expectedOutput: "(id > $1::uuid)",
+			expectedArgs:   []any{"7e1f9a2b-3c4d-4e5f-a6b7-c8d9e0f1a2b3"},
+		},
+		{
+			name: "Mixed directions",
+			keys: []sortKey{
+				{column: "price", cast: "numeric"},
+				{column: "title", cast: "text", desc: true},
+				sortKeyId,
+			},
+			values: []string{"10.10", "Dune", "7e1f9a2b-3c4d-4e5f-a6b7-c8d9e0f1a2b3"},
+			expectedOutput: "(price > $1::numeric)" +
+				" OR (price = $1::numeric AND title < $2::text)" +
+				" OR (price = $1::numeric AND title = $2::text AND id > $3::uuid)",
+			expectedArgs: []any{"10.10", "Dune", "7e1f9a2b-3c4d-4e5f-a6b7-c8d9e0f1a2b3"},
+		},
+		{
+			name:           "After the arguments of the filter",
+			args:           []any{"USD", "%camus%"},
+			keys:           []sortKey{{column: "rank", cast: "real", desc: true}, sortKeyId},
+			values:         []string{"0.5", "7e1f9a2b-3c4d-4e5f-a6b7-c8d9e0f1a2b3"},
+			expectedOutput: "(rank < $3::real) OR (rank = $3::real AND id > $4::uuid)",
+			expectedArgs:   []any{"USD", "%camus%", "0.5", "7e1f9a2b-3c4d-4e5f-a6b7-c8d9e0f1a2b3"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bq := booksQuery{args: test.args}
+
+			assert.Equal(t, test.expectedOutput, bq.after(test.keys, test.values))
+			assert.Equal(t, test.expectedArgs, bq.args)
+		})
+	}
+}
+
+func TestCursorKey(t *testing.T) {
+	minPrice := book.NewMoney(1000, "USD")
+	otherMinPrice := book.NewMoney(2000, "USD")
+	since := time.Date(2024, time.May, 1, 10, 0, 0, 0, time.UTC)
+
+	base := book.GetBooksOptions{
+		Currency: "USD",
+		OrderBy:  book.SortByPrice,
+		Filter: book.GetBooksFilter{
+			Query:        "stranger",
+			Genres:       []string{"horror", "science"},
+			MinPrice:     &minPrice,
+			CreatedSince: &since,
+		},
+	}
+
+	tests := []struct {
+		name          string
+		change        func(opts *book.GetBooksOptions)
+		expectedEqual bool
+	}{
+		{
+			name:          "Same options",
+			change:        func(opts *book.GetBooksOptions) {},
+			expectedEqual: true,
+		},
+		{
+			name: "Same genres in another order and case",
+			change: func(opts *book.GetBooksOptions) {
+				opts.Filter.Genres = []string{"Science", "horror", "HORROR"}
+			},
+			expectedEqual: true,
+		},
+		{
+			name: "Same time in another zone",
+			change: func(opts *book.GetBooksOptions) {
+				t := since.In(time.FixedZone("UTC+8", 8*60*60))
+				opts.Filter.CreatedSince = &t
+			},
+			expectedEqual: true,
+		},
+		{
+			name: "Explicit genre match",
+			change: func(opts *book.GetBooksOptions) {
+				opts.Filter.GenreMatch = book.GenreMatchAny
+			},
+			expectedEqual: true,
+		},
+		{
+			name: "Other genres",
+			change: func(opts *book.GetBooksOptions) {
+				opts.Filter.Genres = []string{"horror"}
+			},
+		},
+		{
+			name: "Other min price",
+			change: func(opts *book.GetBooksOptions) {
+				opts.Filter.MinPrice = &otherMinPrice
+			},
+		},
+		{
+			name: "Excluded genres",
+			change: func(opts *book.GetBooksOptions) {
+				opts.Filter.ExcludeGenres = []string{"romance"}
+			},
+		},
+		{
+			name: "In stock",
+			change: func(opts *book.GetBooksOptions) {
+				inStock := true
+				opts.Filter.InStock = &inStock
+			},
+		},
+		{
+			name: "Other currency",
+			change: func(opts *book.GetBooksOptions) {
+				opts.Currency = "EUR"
+			},
+		},
+		{
+			name: "Other order",
+			change: func(opts *book.GetBooksOptions) {
+				opts.Desc = true
+			},
+		},
+	}
+
+	keys, err := bookSortKeys(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	baseKey, err := cursorKey(base, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := base
+			test.change(&opts)
+
+			keys, err := bookSortKeys(opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			key, err := cursorKey(opts, keys)
+
+			assert.NoError(t, err)
+			if test.expectedEqual {
+				assert.Equal(t, baseKey, key)
+			} else {
+				assert.NotEqual(t, baseKey, key)
+			}
+		})
+	}
+}
+
+func TestFacetQueries(t *testing.T) {
+	minPrice := book.NewMoney(1000, "USD")
+	opts := book.GetBooksOptions{
+		Currency: "USD",
+		Filter: book.GetBooksFilter{
+			Author:     "camus",
+			Genres:     []string{"horror"},
+			GenreMatch: book.GenreMatchAll,
+			MinPrice:   &minPrice,
+		},
+	}
+
+	boundaries := []pgtype.Numeric{toNumeric(book.NewMoney(1000, "USD")), toNumeric(book.NewMoney(2500, "USD"))}
+
+	genreSql, genreArgs := genreFacetQuery(opts)
+	authorSql, authorArgs := authorFacetQuery(opts)
+	priceSql, priceArgs := priceFacetQuery(opts, boundaries)
+
+	tests := []struct {
+		name                string
+		sql                 string
+		args                []any
+		expectedArgs        []any
+		expectedContains    []string
+		expectedNotContains []string
+	}{
+		{
+			name:         "Genres",
+			sql:          genreSql,
+			args:         genreArgs,
+			expectedArgs: []any{"USD", "%camus%", toNumeric(minPrice)},
+			expectedContains: []string{
+				"author.name ILIKE $2",
+				"::numeric >= $3::numeric",
+				"UNNEST(filtered_books.genres) AS genre",
+			},
+			expectedNotContains: []string{"genre.name ILIKE"},
+		},
+		{
+			name:         "Authors",
+			sql:          authorSql,
+			args:         authorArgs,
+			expectedArgs: []any{"USD", []string{"horror"}, toNumeric(minPrice), authorFacetLimit},
+			expectedContains: []string{
+				"INNER JOIN UNNEST($2::text[]) AS patterns(pattern)",
+				"::numeric >= $3::numeric",
+				"LIMIT\n  $4",
+			},
+			expectedNotContains: []string{"author.name ILIKE", "LOWER(author.name)"},
+		},
+		{
+			name:         "Prices",
+			sql:          priceSql,
+			args:         priceArgs,
+			expectedArgs: []any{"USD", "%camus%", []string{"horror"}, boundaries},
+			expectedContains: []string{
+				"author.name ILIKE $2",
+				"INNER JOIN UNNEST($3::text[]) AS patterns(pattern)",
+				"width_bucket(price, $4::numeric[])",
+			},
+			expectedNotContains: []string{"::numeric >= $", "::numeric <= $"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expectedArgs, test.args)
+
+			for _, v := range test.expectedContains {
+				assert.True(t, strings.Contains(test.sql, v), "the query should contain %q", v)
+			}
+
+			for _, v := range test.expectedNotContains {
+				assert.False(t, strings.Contains(test.sql, v), "the query should not contain %q", v)
+			}
+		})
+	}
+}
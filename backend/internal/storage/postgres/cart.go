@@ -0,0 +1,199 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cativovo/bookstore/internal/book"
+	"github.com/cativovo/bookstore/internal/cart"
+	query "github.com/cativovo/bookstore/internal/storage/postgres/generated"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (pr *PostgresRepository) CreateCart(ctx context.Context) (cart.Cart, error) {
+	cartUuid, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (pgtype.UUID, error) {
+		return pr.queries.CreateCart(ctxWithTimeout)
+	})
+	if err != nil {
+		return cart.Cart{}, err
+	}
+
+	id, err := cartUuid.Value()
+	if err != nil {
+		return cart.Cart{}, err
+	}
+
+	return cart.Cart{
+		Id:    id.(string),
+		Items: make([]cart.Item, 0),
+	}, nil
+}
+
+func (pr *PostgresRepository) GetCartById(ctx context.Context, id string) (cart.Cart, error) {
+	var cartUuid pgtype.UUID
+	if err := cartUuid.Scan(id); err != nil {
+		return cart.Cart{}, cart.ErrNotFound
+	}
+
+	rows, err := withTimeout(ctx, func(ctxWithTimeout context.Context) ([]query.GetCartItemsRow, error) {
+		if err := pr.checkCartExists(ctxWithTimeout, cartUuid); err != nil {
+			return nil, err
+		}
+
+		return pr.queries.GetCartItems(ctxWithTimeout, cartUuid)
+	})
+	if err != nil {
+		return cart.Cart{}, err
+	}
+
+	items := make([]cart.Item, len(rows))
+
+	for i, v := range rows {
+		bookId, err := v.BookID.Value()
+		if err != nil {
+			return cart.Cart{}, err
+		}
+
+		price, err := toMoney(v.Price, book.DefaultCurrency)
+		if err != nil {
+			return cart.Cart{}, err
+		}
+
+		items[i] = cart.Item{
+			BookId:   bookId.(string),
+			Title:    v.Title,
+			Author:   v.Author,
+			Price:    price,
+			Quantity: int(v.Quantity),
+		}
+	}
+
+	return cart.Cart{
+		Id:    id,
+		Items: items,
+	}, nil
+}
+
+func (pr *PostgresRepository) AddCartItem(ctx context.Context, cartId string, bookId string, quantity int) error {
+	var cartUuid pgtype.UUID
+	if err := cartUuid.Scan(cartId); err != nil {
+		return cart.ErrNotFound
+	}
+
+	var bookUuid pgtype.UUID
+	if err := bookUuid.Scan(bookId); err != nil {
+		return book.ErrNotFound
+	}
+
+	_, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (struct{}, error) {
+		if err := pr.checkCartExists(ctxWithTimeout, cartUuid); err != nil {
+			return struct{}{}, err
+		}
+
+		err := pr.queries.AddCartItem(ctxWithTimeout, query.AddCartItemParams{
+			CartID:   cartUuid,
+			BookID:   bookUuid,
+			Quantity: int32(quantity),
+		})
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) {
+				switch pgErr.Code {
+				case pgerrcode.ForeignKeyViolation:
+					return struct{}{}, book.ErrNotFound
+				}
+			}
+
+			return struct{}{}, err
+		}
+
+		return struct{}{}, nil
+	})
+
+	return err
+}
+
+func (pr *PostgresRepository) UpdateCartItem(ctx context.Context, cartId string, bookId string, quantity int) error {
+	var cartUuid pgtype.UUID
+	if err := cartUuid.Scan(cartId); err != nil {
+		return cart.ErrNotFound
+	}
+
+	var bookUuid pgtype.UUID
+	if err := bookUuid.Scan(bookId); err != nil {
+		return cart.ErrItemNotFound
+	}
+
+	_, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (struct{}, error) {
+		if err := pr.checkCartExists(ctxWithTimeout, cartUuid); err != nil {
+			return struct{}{}, err
+		}
+
+		rows, err := pr.queries.UpdateCartItem(ctxWithTimeout, query.UpdateCartItemParams{
+			CartID:   cartUuid,
+			BookID:   bookUuid,
+			Quantity: int32(quantity),
+		})
+		if err != nil {
+			return struct{}{}, err
+		}
+
+		if rows == 0 {
+			return struct{}{}, cart.ErrItemNotFound
+		}
+
+		return struct{}{}, nil
+	})
+
+	return err
+}
+
+func (pr *PostgresRepository) RemoveCartItem(ctx context.Context, cartId string, bookId string) error {
+	var cartUuid pgtype.UUID
+	if err := cartUuid.Scan(cartId); err != nil {
+		return cart.ErrNotFound
+	}
+
+	var bookUuid pgtype.UUID
+	if err := bookUuid.Scan(bookId); err != nil {
+		return cart.ErrItemNotFound
+	}
+
+	_, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (struct{}, error) {
+		if err := pr.checkCartExists(ctxWithTimeout, cartUuid); err != nil {
+			return struct{}{}, err
+		}
+
+		rows, err := pr.queries.DeleteCartItem(ctxWithTimeout, query.DeleteCartItemParams{
+			CartID: cartUuid,
+			BookID: bookUuid,
+		})
+		if err != nil {
+			return struct{}{}, err
+		}
+
+		if rows == 0 {
+			return struct{}{}, cart.ErrItemNotFound
+		}
+
+		return struct{}{}, nil
+	})
+
+	return err
+}
+
+func (pr *PostgresRepository) checkCartExists(ctx context.Context, cartUuid pgtype.UUID) error {
+	if _, err := pr.queries.GetCart(ctx, cartUuid); err != nil {
+		switch err {
+		case pgx.ErrNoRows:
+			return cart.ErrNotFound
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/cativovo/bookstore/internal/book"
+	"github.com/cativovo/bookstore/internal/catalog"
+)
+
+// CreateBooks doesn't use withTimeout, a chunk of an import can take longer than the timeout of the other queries
+func (pr *PostgresRepository) CreateBooks(ctx context.Context, books []book.Book, createGenres bool) ([]book.Book, error) {
+	tx, err := pr.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := pr.queries.WithTx(tx)
+
+	created := make([]book.Book, len(books))
+	for i, b := range books {
+		created[i], err = createBook(ctx, qtx, b, createGenres)
+		if err != nil {
+			return nil, &catalog.BookError{Index: i, Err: err}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
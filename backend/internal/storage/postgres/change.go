@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/cativovo/bookstore/internal/change"
+	query "github.com/cativovo/bookstore/internal/storage/postgres/generated"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (pr *PostgresRepository) GetChanges(ctx context.Context, since string, limit int) ([]change.Change, error) {
+	var transactionId, id int64
+
+	if since != "" {
+		var err error
+		transactionId, id, err = decodeChangeToken(since)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := withTimeout(ctx, func(ctxWithTimeout context.Context) ([]query.ChangeLog, error) {
+		return pr.queries.GetChanges(ctxWithTimeout, query.GetChangesParams{
+			TransactionID: transactionId,
+			ID:            id,
+			MaxChanges:    int32(limit),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]change.Change, len(rows))
+
+	for i, v := range rows {
+		entityId, err := v.EntityID.Value()
+		if err != nil {
+			return nil, err
+		}
+
+		changes[i] = change.Change{
+			Token:     encodeChangeToken(v.TransactionID, v.ID),
+			Entity:    change.Entity(v.Entity),
+			EntityId:  entityId.(string),
+			Action:    change.Action(v.Action),
+			Data:      v.Data,
+			CreatedAt: v.CreatedAt.Time,
+		}
+	}
+
+	return changes, nil
+}
+
+// recordChange adds the change to the change log, it has to use the transaction of the change.
+// data is encoded to json, nil is stored as NULL.
+func recordChange(ctx context.Context, qtx *query.Queries, entity change.Entity, action change.Action, id pgtype.UUID, data any) error {
+	var b []byte
+
+	if data != nil {
+		var err error
+		b, err = json.Marshal(data)
+		if err != nil {
+			return err
+		}
+	}
+
+	return qtx.CreateChange(ctx, query.CreateChangeParams{
+		Entity:   string(entity),
+		EntityID: id,
+		Action:   string(action),
+		Data:     b,
+	})
+}
+
+// the token is the position of the change in the log, its format is not part of the api
+func encodeChangeToken(transactionId int64, id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d.%d", transactionId, id)))
+}
+
+func decodeChangeToken(token string) (int64, int64, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, 0, change.ErrInvalidToken
+	}
+
+	var transactionId, id int64
+	if _, err := fmt.Sscanf(string(b), "%d.%d", &transactionId, &id); err != nil || encodeChangeToken(transactionId, id) != token {
+		return 0, 0, change.ErrInvalidToken
+	}
+
+	return transactionId, id, nil
+}
@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/cativovo/bookstore/internal/book"
+	"github.com/cativovo/bookstore/internal/exchange"
+	query "github.com/cativovo/bookstore/internal/storage/postgres/generated"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (pr *PostgresRepository) UpsertExchangeRates(ctx context.Context, rates []exchange.Rate) error {
+	_, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (struct{}, error) {
+		tx, err := pr.pool.Begin(ctxWithTimeout)
+		if err != nil {
+			return struct{}{}, err
+		}
+		defer tx.Rollback(ctxWithTimeout)
+		qtx := pr.queries.WithTx(tx)
+
+		for _, v := range rates {
+			var rate pgtype.Numeric
+			if err := rate.Scan(v.Rate.String()); err != nil {
+				return struct{}{}, err
+			}
+
+			err := qtx.UpsertExchangeRate(ctxWithTimeout, query.UpsertExchangeRateParams{
+				Currency: v.Currency,
+				Rate:     rate,
+				Rounding: toNumeric(book.NewMoney(v.Rounding, v.Currency)),
+			})
+			if err != nil {
+				return struct{}{}, err
+			}
+		}
+
+		if err := tx.Commit(ctxWithTimeout); err != nil {
+			return struct{}{}, err
+		}
+
+		return struct{}{}, nil
+	})
+
+	return err
+}
+
+func (pr *PostgresRepository) GetExchangeRates(ctx context.Context) ([]exchange.Rate, error) {
+	rows, err := withTimeout(ctx, func(ctxWithTimeout context.Context) ([]query.ExchangeRate, error) {
+		return pr.queries.GetExchangeRates(ctxWithTimeout)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make([]exchange.Rate, len(rows))
+
+	for i, v := range rows {
+		rate, err := v.Rate.Value()
+		if err != nil {
+			return nil, err
+		}
+
+		rounding, err := toMoney(v.Rounding, v.Currency)
+		if err != nil {
+			return nil, err
+		}
+
+		rates[i] = exchange.Rate{
+			Currency:  v.Currency,
+			Rate:      json.Number(trimDecimalZeros(rate.(string))),
+			Rounding:  rounding.Amount,
+			UpdatedAt: v.UpdatedAt.Time,
+		}
+	}
+
+	return rates, nil
+}
+
+// checkCurrency returns book.ErrInvalidCurrency if the prices can't be converted to the currency
+func checkCurrency(ctx context.Context, q *query.Queries, currency string) error {
+	if currency == book.DefaultCurrency {
+		return nil
+	}
+
+	if _, err := q.GetExchangeRate(ctx, currency); err != nil {
+		switch err {
+		case pgx.ErrNoRows:
+			return book.ErrInvalidCurrency
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// trimDecimalZeros removes the zeros added by the scale of the column, "0.9300000000" becomes "0.93"
+func trimDecimalZeros(s string) string {
+	if !strings.Contains(s, ".") {
+		return s
+	}
+
+	return strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+}
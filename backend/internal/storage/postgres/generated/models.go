@@ -8,13 +8,55 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type ApiKey struct {
+	ID         pgtype.UUID
+	UserID     pgtype.UUID
+	Name       string
+	Prefix     string
+	KeyHash    string
+	Scopes     []string
+	LastUsedAt pgtype.Timestamptz
+	RevokedAt  pgtype.Timestamptz
+	CreatedAt  pgtype.Timestamptz
+}
+
+type Author struct {
+	ID        pgtype.UUID
+	Name      string
+	Bio       pgtype.Text
+	PhotoUrl  pgtype.Text
+	CreatedAt pgtype.Timestamptz
+}
+
 type Book struct {
-	ID          pgtype.UUID
-	Title       string
-	Author      string
-	Description pgtype.Text
-	CoverImage  pgtype.Text
-	Price       pgtype.Numeric
+	ID              pgtype.UUID
+	Title           string
+	Author          string
+	Description     pgtype.Text
+	CoverImage      pgtype.Text
+	Price           pgtype.Numeric
+	SearchVector    interface{}
+	CreatedAt       pgtype.Timestamptz
+	UpdatedAt       pgtype.Timestamptz
+	RatingSum       int32
+	ReviewCount     int32
+	AverageRating   pgtype.Numeric
+	Isbn13          pgtype.Text
+	Publisher       pgtype.Text
+	PublicationDate pgtype.Date
+	Edition         pgtype.Text
+	PageCount       pgtype.Int4
+	Language        pgtype.Text
+	Format          pgtype.Text
+	SeriesID        pgtype.UUID
+	SeriesPosition  pgtype.Numeric
+}
+
+type BookAuthor struct {
+	BookID   pgtype.UUID
+	AuthorID pgtype.UUID
+	Role     string
+	Position int16
 }
 
 type BookGenre struct {
@@ -23,7 +65,141 @@ type BookGenre struct {
 	GenreID pgtype.UUID
 }
 
+type BookPrice struct {
+	BookID   pgtype.UUID
+	Currency string
+	Price    pgtype.Numeric
+}
+
+type Cart struct {
+	ID        pgtype.UUID
+	CreatedAt pgtype.Timestamptz
+}
+
+type CartItem struct {
+	CartID   pgtype.UUID
+	BookID   pgtype.UUID
+	Quantity int32
+}
+
+type ChangeLog struct {
+	ID            int64
+	TransactionID int64
+	Entity        string
+	EntityID      pgtype.UUID
+	Action        string
+	Data          []byte
+	CreatedAt     pgtype.Timestamptz
+}
+
+type ExchangeRate struct {
+	Currency  string
+	Rate      pgtype.Numeric
+	Rounding  pgtype.Numeric
+	UpdatedAt pgtype.Timestamptz
+}
+
 type Genre struct {
 	ID   pgtype.UUID
 	Name pgtype.Text
 }
+
+type Order struct {
+	ID        pgtype.UUID
+	Status    string
+	CreatedAt pgtype.Timestamptz
+	UpdatedAt pgtype.Timestamptz
+}
+
+type OrderItem struct {
+	ID       pgtype.UUID
+	OrderID  pgtype.UUID
+	BookID   pgtype.UUID
+	Title    string
+	Author   string
+	Price    pgtype.Numeric
+	Quantity int32
+}
+
+type Outbox struct {
+	ID            int64
+	WebhookID     pgtype.UUID
+	EventID       pgtype.UUID
+	EventType     string
+	Payload       []byte
+	Status        string
+	Attempts      int32
+	NextAttemptAt pgtype.Timestamptz
+	LastError     pgtype.Text
+	CreatedAt     pgtype.Timestamptz
+	DeliveredAt   pgtype.Timestamptz
+}
+
+type Payment struct {
+	ID                pgtype.UUID
+	OrderID           pgtype.UUID
+	ProviderPaymentID pgtype.Text
+	Status            string
+	Amount            int64
+	Currency          string
+	CreatedAt         pgtype.Timestamptz
+	UpdatedAt         pgtype.Timestamptz
+}
+
+type RefreshToken struct {
+	TokenHash string
+	UserID    pgtype.UUID
+	ExpiresAt pgtype.Timestamptz
+	RevokedAt pgtype.Timestamptz
+	CreatedAt pgtype.Timestamptz
+}
+
+type Review struct {
+	ID        pgtype.UUID
+	BookID    pgtype.UUID
+	UserID    pgtype.UUID
+	Rating    int16
+	Body      string
+	CreatedAt pgtype.Timestamptz
+	UpdatedAt pgtype.Timestamptz
+}
+
+type Series struct {
+	ID          pgtype.UUID
+	Name        string
+	Description pgtype.Text
+	CreatedAt   pgtype.Timestamptz
+}
+
+type Stock struct {
+	BookID         pgtype.UUID
+	QuantityOnHand int32
+	Reserved       int32
+}
+
+type StockAdjustment struct {
+	ID             pgtype.UUID
+	BookID         pgtype.UUID
+	Quantity       int32
+	Reason         string
+	Note           pgtype.Text
+	QuantityOnHand int32
+	CreatedAt      pgtype.Timestamptz
+}
+
+type User struct {
+	ID           pgtype.UUID
+	Email        string
+	Name         string
+	PasswordHash string
+	CreatedAt    pgtype.Timestamptz
+	Role         string
+}
+
+type Webhook struct {
+	ID         pgtype.UUID
+	Url        string
+	EventTypes []string
+	Secret     string
+	CreatedAt  pgtype.Timestamptz
+}
@@ -11,34 +11,347 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const addCartItem = `-- name: AddCartItem :exec
+INSERT INTO cart_item (
+  cart_id, book_id, quantity
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (cart_id, book_id) DO UPDATE SET quantity = cart_item.quantity + EXCLUDED.quantity
+`
+
+type AddCartItemParams struct {
+	CartID   pgtype.UUID
+	BookID   pgtype.UUID
+	Quantity int32
+}
+
+func (q *Queries) AddCartItem(ctx context.Context, arg AddCartItemParams) error {
+	_, err := q.db.Exec(ctx, addCartItem, arg.CartID, arg.BookID, arg.Quantity)
+	return err
+}
+
+const adjustStock = `-- name: AdjustStock :one
+UPDATE stock SET quantity_on_hand = quantity_on_hand + $1::integer
+WHERE
+  book_id = $2
+RETURNING quantity_on_hand, reserved
+`
+
+type AdjustStockParams struct {
+	Quantity int32
+	BookID   pgtype.UUID
+}
+
+type AdjustStockRow struct {
+	QuantityOnHand int32
+	Reserved       int32
+}
+
+// the check constraints on stock reject the update if it drives the quantity on hand below zero or below the reserved quantity
+func (q *Queries) AdjustStock(ctx context.Context, arg AdjustStockParams) (AdjustStockRow, error) {
+	row := q.db.QueryRow(ctx, adjustStock, arg.Quantity, arg.BookID)
+	var i AdjustStockRow
+	err := row.Scan(&i.QuantityOnHand, &i.Reserved)
+	return i, err
+}
+
+const authorizePayment = `-- name: AuthorizePayment :one
+UPDATE payment SET provider_payment_id = $1, status = 'authorized', updated_at = NOW()
+WHERE id = $2 AND status = 'pending'
+RETURNING id, order_id, provider_payment_id, status, amount, currency, created_at, updated_at
+`
+
+type AuthorizePaymentParams struct {
+	ProviderPaymentID pgtype.Text
+	ID                pgtype.UUID
+}
+
+func (q *Queries) AuthorizePayment(ctx context.Context, arg AuthorizePaymentParams) (Payment, error) {
+	row := q.db.QueryRow(ctx, authorizePayment, arg.ProviderPaymentID, arg.ID)
+	var i Payment
+	err := row.Scan(
+		&i.ID,
+		&i.OrderID,
+		&i.ProviderPaymentID,
+		&i.Status,
+		&i.Amount,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const claimOutboxEvents = `-- name: ClaimOutboxEvents :many
+UPDATE outbox
+SET
+  next_attempt_at = NOW() + $1::int * INTERVAL '1 second'
+FROM
+  webhook
+WHERE
+  outbox.webhook_id = webhook.id
+AND
+  outbox.id IN (
+    SELECT id FROM outbox
+    WHERE status = 'pending' AND next_attempt_at <= NOW()
+    ORDER BY next_attempt_at, id
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+  )
+RETURNING
+  outbox.id, outbox.event_id, outbox.event_type, outbox.payload, outbox.attempts, outbox.created_at, webhook.url, webhook.secret
+`
+
+type ClaimOutboxEventsParams struct {
+	LeaseSeconds int32
+	MaxEvents    int32
+}
+
+type ClaimOutboxEventsRow struct {
+	ID        int64
+	EventID   pgtype.UUID
+	EventType string
+	Payload   []byte
+	Attempts  int32
+	CreatedAt pgtype.Timestamptz
+	Url       string
+	Secret    string
+}
+
+// claims the due events by moving their next attempt after the lease, the other dispatchers skip them until the lease ends
+func (q *Queries) ClaimOutboxEvents(ctx context.Context, arg ClaimOutboxEventsParams) ([]ClaimOutboxEventsRow, error) {
+	rows, err := q.db.Query(ctx, claimOutboxEvents, arg.LeaseSeconds, arg.MaxEvents)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClaimOutboxEventsRow
+	for rows.Next() {
+		var i ClaimOutboxEventsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.EventID,
+			&i.EventType,
+			&i.Payload,
+			&i.Attempts,
+			&i.CreatedAt,
+			&i.Url,
+			&i.Secret,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const consumeStock = `-- name: ConsumeStock :one
+UPDATE stock SET
+  quantity_on_hand = quantity_on_hand - $1::integer,
+  reserved = reserved - $1::integer
+WHERE
+  book_id = $2
+RETURNING quantity_on_hand
+`
+
+type ConsumeStockParams struct {
+	Quantity int32
+	BookID   pgtype.UUID
+}
+
+func (q *Queries) ConsumeStock(ctx context.Context, arg ConsumeStockParams) (int32, error) {
+	row := q.db.QueryRow(ctx, consumeStock, arg.Quantity, arg.BookID)
+	var quantity_on_hand int32
+	err := row.Scan(&quantity_on_hand)
+	return quantity_on_hand, err
+}
+
+const convertPrices = `-- name: ConvertPrices :many
+SELECT
+  COALESCE(convert_price(prices.price, exchange_rate.rate, exchange_rate.rounding), prices.price)::numeric AS price
+FROM
+  UNNEST($1::numeric[]) WITH ORDINALITY AS prices(price, position)
+LEFT JOIN
+  exchange_rate ON exchange_rate.currency = $2::text
+ORDER BY
+  prices.position
+`
+
+type ConvertPricesParams struct {
+	Prices   []pgtype.Numeric
+	Currency string
+}
+
+// converts the prices in USD to the currency in the same order, with the same rounding as the prices of the books
+func (q *Queries) ConvertPrices(ctx context.Context, arg ConvertPricesParams) ([]pgtype.Numeric, error) {
+	rows, err := q.db.Query(ctx, convertPrices, arg.Prices, arg.Currency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.Numeric
+	for rows.Next() {
+		var price pgtype.Numeric
+		if err := rows.Scan(&price); err != nil {
+			return nil, err
+		}
+		items = append(items, price)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countAuthors = `-- name: CountAuthors :one
+SELECT COUNT(*) FROM author WHERE name ILIKE $1
+`
+
+func (q *Queries) CountAuthors(ctx context.Context, name string) (int64, error) {
+	row := q.db.QueryRow(ctx, countAuthors, name)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countOrders = `-- name: CountOrders :one
+SELECT COUNT(*) FROM orders
+`
+
+func (q *Queries) CountOrders(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, countOrders)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countStockAdjustments = `-- name: CountStockAdjustments :one
+SELECT COUNT(*) FROM stock_adjustment WHERE book_id = $1
+`
+
+func (q *Queries) CountStockAdjustments(ctx context.Context, bookID pgtype.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countStockAdjustments, bookID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createApiKey = `-- name: CreateApiKey :one
+INSERT INTO api_key (
+  user_id, name, prefix, key_hash, scopes
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING id, created_at
+`
+
+type CreateApiKeyParams struct {
+	UserID  pgtype.UUID
+	Name    string
+	Prefix  string
+	KeyHash string
+	Scopes  []string
+}
+
+type CreateApiKeyRow struct {
+	ID        pgtype.UUID
+	CreatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) CreateApiKey(ctx context.Context, arg CreateApiKeyParams) (CreateApiKeyRow, error) {
+	row := q.db.QueryRow(ctx, createApiKey,
+		arg.UserID,
+		arg.Name,
+		arg.Prefix,
+		arg.KeyHash,
+		arg.Scopes,
+	)
+	var i CreateApiKeyRow
+	err := row.Scan(&i.ID, &i.CreatedAt)
+	return i, err
+}
+
 const createBook = `-- name: CreateBook :one
 INSERT INTO book (
-  title, author, description, price, cover_image
+  title, author, description, price, cover_image, isbn13, publisher, publication_date, edition, page_count, language, format, series_id, series_position
 ) VALUES (
-  $1, $2, $3, $4, $5
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
 )
-RETURNING id
+RETURNING id, created_at, updated_at
 `
 
 type CreateBookParams struct {
-	Title       string
-	Author      string
-	Description pgtype.Text
-	Price       pgtype.Numeric
-	CoverImage  pgtype.Text
+	Title           string
+	Author          string
+	Description     pgtype.Text
+	Price           pgtype.Numeric
+	CoverImage      pgtype.Text
+	Isbn13          pgtype.Text
+	Publisher       pgtype.Text
+	PublicationDate pgtype.Date
+	Edition         pgtype.Text
+	PageCount       pgtype.Int4
+	Language        pgtype.Text
+	Format          pgtype.Text
+	SeriesID        pgtype.UUID
+	SeriesPosition  pgtype.Numeric
+}
+
+type CreateBookRow struct {
+	ID        pgtype.UUID
+	CreatedAt pgtype.Timestamptz
+	UpdatedAt pgtype.Timestamptz
 }
 
-func (q *Queries) CreateBook(ctx context.Context, arg CreateBookParams) (pgtype.UUID, error) {
+func (q *Queries) CreateBook(ctx context.Context, arg CreateBookParams) (CreateBookRow, error) {
 	row := q.db.QueryRow(ctx, createBook,
 		arg.Title,
 		arg.Author,
 		arg.Description,
 		arg.Price,
 		arg.CoverImage,
+		arg.Isbn13,
+		arg.Publisher,
+		arg.PublicationDate,
+		arg.Edition,
+		arg.PageCount,
+		arg.Language,
+		arg.Format,
+		arg.SeriesID,
+		arg.SeriesPosition,
 	)
-	var id pgtype.UUID
-	err := row.Scan(&id)
-	return id, err
+	var i CreateBookRow
+	err := row.Scan(&i.ID, &i.CreatedAt, &i.UpdatedAt)
+	return i, err
+}
+
+const createBookAuthor = `-- name: CreateBookAuthor :exec
+INSERT INTO book_author (
+  book_id, author_id, role, position
+) VALUES (
+  $1, $2, $3, $4
+)
+`
+
+type CreateBookAuthorParams struct {
+	BookID   pgtype.UUID
+	AuthorID pgtype.UUID
+	Role     string
+	Position int16
+}
+
+func (q *Queries) CreateBookAuthor(ctx context.Context, arg CreateBookAuthorParams) error {
+	_, err := q.db.Exec(ctx, createBookAuthor,
+		arg.BookID,
+		arg.AuthorID,
+		arg.Role,
+		arg.Position,
+	)
+	return err
 }
 
 const createBookGenre = `-- name: CreateBookGenre :exec
@@ -59,6 +372,42 @@ func (q *Queries) CreateBookGenre(ctx context.Context, arg CreateBookGenreParams
 	return err
 }
 
+const createCart = `-- name: CreateCart :one
+INSERT INTO cart DEFAULT VALUES RETURNING id
+`
+
+func (q *Queries) CreateCart(ctx context.Context) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, createCart)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const createChange = `-- name: CreateChange :exec
+INSERT INTO change_log (
+  entity, entity_id, action, data
+) VALUES (
+  $1, $2, $3, $4
+)
+`
+
+type CreateChangeParams struct {
+	Entity   string
+	EntityID pgtype.UUID
+	Action   string
+	Data     []byte
+}
+
+func (q *Queries) CreateChange(ctx context.Context, arg CreateChangeParams) error {
+	_, err := q.db.Exec(ctx, createChange,
+		arg.Entity,
+		arg.EntityID,
+		arg.Action,
+		arg.Data,
+	)
+	return err
+}
+
 const createGenre = `-- name: CreateGenre :one
 INSERT INTO genre (
   name
@@ -75,225 +424,1476 @@ func (q *Queries) CreateGenre(ctx context.Context, name pgtype.Text) (pgtype.UUI
 	return id, err
 }
 
-const deleteGenre = `-- name: DeleteGenre :execrows
-DELETE FROM genre WHERE id = $1
+const createOrder = `-- name: CreateOrder :one
+INSERT INTO orders DEFAULT VALUES RETURNING id, status, created_at, updated_at
 `
 
-func (q *Queries) DeleteGenre(ctx context.Context, id pgtype.UUID) (int64, error) {
-	result, err := q.db.Exec(ctx, deleteGenre, id)
+func (q *Queries) CreateOrder(ctx context.Context) (Order, error) {
+	row := q.db.QueryRow(ctx, createOrder)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createOrderItem = `-- name: CreateOrderItem :execrows
+INSERT INTO order_item (
+  order_id, book_id, title, author, price, quantity
+)
+SELECT
+  $1, book.id, book.title, book.author, book.price, $2::integer
+FROM
+  book
+WHERE
+  book.id = $3
+`
+
+type CreateOrderItemParams struct {
+	OrderID  pgtype.UUID
+	Quantity int32
+	BookID   pgtype.UUID
+}
+
+func (q *Queries) CreateOrderItem(ctx context.Context, arg CreateOrderItemParams) (int64, error) {
+	result, err := q.db.Exec(ctx, createOrderItem, arg.OrderID, arg.Quantity, arg.BookID)
 	if err != nil {
 		return 0, err
 	}
 	return result.RowsAffected(), nil
 }
 
-const getBookById = `-- name: GetBookById :one
+const createOutboxEvent = `-- name: CreateOutboxEvent :exec
+WITH event AS (
+  SELECT uuid_generate_v4() AS id
+)
+INSERT INTO outbox (
+  webhook_id, event_id, event_type, payload
+)
 SELECT
-  book.id,
-  book.title,
-  book.description,
-  book.author,
-  book.price,
-  book.cover_image,
-  COALESCE(ARRAY_AGG(genre.name) FILTER (WHERE genre.name IS NOT NULL), '{}') AS genres
+  webhook.id, event.id, $1::text, $2::jsonb
 FROM
-  book
-LEFT JOIN
-  book_genre ON book_genre.book_id = book.id
-LEFT JOIN
-  genre ON genre.id = book_genre.genre_id
+  webhook, event
 WHERE
-  book.id = $1
-GROUP BY
-  book.id
+  $1::text = ANY(webhook.event_types)
 `
 
-type GetBookByIdRow struct {
-	ID          pgtype.UUID
-	Title       string
-	Description pgtype.Text
-	Author      string
-	Price       pgtype.Numeric
-	CoverImage  pgtype.Text
-	Genres      interface{}
+type CreateOutboxEventParams struct {
+	EventType string
+	Payload   []byte
 }
 
-func (q *Queries) GetBookById(ctx context.Context, id pgtype.UUID) (GetBookByIdRow, error) {
-	row := q.db.QueryRow(ctx, getBookById, id)
-	var i GetBookByIdRow
+// adds the event to the outbox of every webhook subscribed to its type
+func (q *Queries) CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) error {
+	_, err := q.db.Exec(ctx, createOutboxEvent, arg.EventType, arg.Payload)
+	return err
+}
+
+const createPayment = `-- name: CreatePayment :one
+INSERT INTO payment (
+  order_id, status, amount, currency
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING id, order_id, provider_payment_id, status, amount, currency, created_at, updated_at
+`
+
+type CreatePaymentParams struct {
+	OrderID  pgtype.UUID
+	Status   string
+	Amount   int64
+	Currency string
+}
+
+func (q *Queries) CreatePayment(ctx context.Context, arg CreatePaymentParams) (Payment, error) {
+	row := q.db.QueryRow(ctx, createPayment,
+		arg.OrderID,
+		arg.Status,
+		arg.Amount,
+		arg.Currency,
+	)
+	var i Payment
 	err := row.Scan(
 		&i.ID,
-		&i.Title,
-		&i.Description,
-		&i.Author,
-		&i.Price,
-		&i.CoverImage,
-		&i.Genres,
+		&i.OrderID,
+		&i.ProviderPaymentID,
+		&i.Status,
+		&i.Amount,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const getBooks = `-- name: GetBooks :one
-WITH
-filtered_books as (
-    SELECT
-      book.id AS id,
-      book.title AS title,
-      book.description AS description,
-      book.author AS author,
-      book.price AS price,
-      book.cover_image AS cover_image,
-      COALESCE(ARRAY_AGG(genre.name) FILTER (WHERE genre.name IS NOT NULL), '{}') AS genres
-    FROM
-      book
-    LEFT JOIN
-      book_genre ON book_genre.book_id = book.id
-    LEFT JOIN
-      genre ON genre.id = book_genre.genre_id
-    WHERE 
-      book.author ILIKE $5
-    AND
-      book.title ILIKE $6
-    AND
-      book.id
-    IN
-      (
-        SELECT
-          book_genre.book_id
-        FROM
-          genre
-        INNER JOIN
-          book_genre
-        ON
-          book_genre.genre_id = genre.id 
-        AND
-          genre.name ILIKE ANY($7::text[])
-        GROUP BY 1
-      )
-    GROUP BY
-      book.id
+const createRefreshToken = `-- name: CreateRefreshToken :exec
+INSERT INTO refresh_token (
+  token_hash, user_id, expires_at
+) VALUES (
+  $1, $2, $3
+)
+`
+
+type CreateRefreshTokenParams struct {
+	TokenHash string
+	UserID    pgtype.UUID
+	ExpiresAt pgtype.Timestamptz
+}
+
+func (q *Queries) CreateRefreshToken(ctx context.Context, arg CreateRefreshTokenParams) error {
+	_, err := q.db.Exec(ctx, createRefreshToken, arg.TokenHash, arg.UserID, arg.ExpiresAt)
+	return err
+}
+
+const createReview = `-- name: CreateReview :one
+INSERT INTO review (
+  book_id, user_id, rating, body
+) VALUES (
+  $1, $2, $3, $4
 )
-SELECT (
-  SELECT
-    COUNT(filtered_books.id)
-  FROM
-  filtered_books
-) AS count,
-(
-  SELECT 
-    JSON_AGG(rows.*)
-  FROM
-    (
-      select 
-        id,
-        title,
-        description,
-        author,
-        price,
-        cover_image,
-        genres
-      from 
-        filtered_books
-      ORDER BY 
-        -- will produce title ASC/DESC, author ASC/DESC OR author ASC/DESC, title ASC/DESC
-        CASE
-          WHEN $3::boolean AND $4::text = 'title' THEN title
-          WHEN $3::boolean AND $4::text = 'author' THEN author
-          WHEN $3::boolean THEN title
-        END DESC,
-        CASE
-          WHEN $3::boolean AND $4::text = 'author' THEN title
-          WHEN $3::boolean THEN author
-        END DESC,
-        CASE
-          WHEN NOT $3::boolean AND $4::text = 'title' THEN title
-          WHEN NOT $3::boolean AND $4::text = 'author' THEN author
-          WHEN NOT $3::boolean THEN title
-        END ASC,
-        CASE
-          WHEN NOT $3::boolean AND $4::text = 'author' THEN title
-          WHEN NOT $3::boolean THEN author
-        END ASC
-      LIMIT 
-        $1
-      OFFSET 
-        $2
-    ) AS rows
-) AS books
-`
-
-type GetBooksParams struct {
-	Limit         int32
-	Offset        int32
-	Descending    bool
-	OrderBy       string
-	KeywordAuthor string
-	KeywordTitle  string
-	Genres        []string
-}
-
-type GetBooksRow struct {
-	Count int64
-	Books []byte
-}
-
-func (q *Queries) GetBooks(ctx context.Context, arg GetBooksParams) (GetBooksRow, error) {
-	row := q.db.QueryRow(ctx, getBooks,
-		arg.Limit,
-		arg.Offset,
-		arg.Descending,
-		arg.OrderBy,
-		arg.KeywordAuthor,
-		arg.KeywordTitle,
-		arg.Genres,
+RETURNING id, created_at, updated_at
+`
+
+type CreateReviewParams struct {
+	BookID pgtype.UUID
+	UserID pgtype.UUID
+	Rating int16
+	Body   string
+}
+
+type CreateReviewRow struct {
+	ID        pgtype.UUID
+	CreatedAt pgtype.Timestamptz
+	UpdatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) CreateReview(ctx context.Context, arg CreateReviewParams) (CreateReviewRow, error) {
+	row := q.db.QueryRow(ctx, createReview,
+		arg.BookID,
+		arg.UserID,
+		arg.Rating,
+		arg.Body,
 	)
-	var i GetBooksRow
-	err := row.Scan(&i.Count, &i.Books)
+	var i CreateReviewRow
+	err := row.Scan(&i.ID, &i.CreatedAt, &i.UpdatedAt)
 	return i, err
 }
 
-const getGenreByName = `-- name: GetGenreByName :one
-SELECT id, name FROM genre WHERE name = $1
+const createSeries = `-- name: CreateSeries :one
+INSERT INTO series (
+  name, description
+) VALUES (
+  $1, $2
+)
+RETURNING id, created_at
 `
 
-func (q *Queries) GetGenreByName(ctx context.Context, name pgtype.Text) (Genre, error) {
-	row := q.db.QueryRow(ctx, getGenreByName, name)
-	var i Genre
-	err := row.Scan(&i.ID, &i.Name)
+type CreateSeriesParams struct {
+	Name        string
+	Description pgtype.Text
+}
+
+type CreateSeriesRow struct {
+	ID        pgtype.UUID
+	CreatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) CreateSeries(ctx context.Context, arg CreateSeriesParams) (CreateSeriesRow, error) {
+	row := q.db.QueryRow(ctx, createSeries, arg.Name, arg.Description)
+	var i CreateSeriesRow
+	err := row.Scan(&i.ID, &i.CreatedAt)
 	return i, err
 }
 
-const getGenres = `-- name: GetGenres :many
-SELECT name FROM genre
+const createStock = `-- name: CreateStock :exec
+INSERT INTO stock (
+  book_id
+) VALUES (
+  $1
+)
 `
 
-func (q *Queries) GetGenres(ctx context.Context) ([]pgtype.Text, error) {
-	rows, err := q.db.Query(ctx, getGenres)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []pgtype.Text
-	for rows.Next() {
-		var name pgtype.Text
-		if err := rows.Scan(&name); err != nil {
-			return nil, err
-		}
-		items = append(items, name)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+func (q *Queries) CreateStock(ctx context.Context, bookID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, createStock, bookID)
+	return err
 }
 
-const test = `-- name: test :many
-SELECT name FROM genre where name ilike $1::text[]
+const createStockAdjustment = `-- name: CreateStockAdjustment :one
+INSERT INTO stock_adjustment (
+  book_id, quantity, reason, note, quantity_on_hand
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING id, created_at
 `
 
-func (q *Queries) test(ctx context.Context, genres []string) ([]pgtype.Text, error) {
-	rows, err := q.db.Query(ctx, test, genres)
-	if err != nil {
+type CreateStockAdjustmentParams struct {
+	BookID         pgtype.UUID
+	Quantity       int32
+	Reason         string
+	Note           pgtype.Text
+	QuantityOnHand int32
+}
+
+type CreateStockAdjustmentRow struct {
+	ID        pgtype.UUID
+	CreatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) CreateStockAdjustment(ctx context.Context, arg CreateStockAdjustmentParams) (CreateStockAdjustmentRow, error) {
+	row := q.db.QueryRow(ctx, createStockAdjustment,
+		arg.BookID,
+		arg.Quantity,
+		arg.Reason,
+		arg.Note,
+		arg.QuantityOnHand,
+	)
+	var i CreateStockAdjustmentRow
+	err := row.Scan(&i.ID, &i.CreatedAt)
+	return i, err
+}
+
+const createUser = `-- name: CreateUser :one
+INSERT INTO users (
+  email, name, password_hash, role
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING id, created_at
+`
+
+type CreateUserParams struct {
+	Email        string
+	Name         string
+	PasswordHash string
+	Role         string
+}
+
+type CreateUserRow struct {
+	ID        pgtype.UUID
+	CreatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (CreateUserRow, error) {
+	row := q.db.QueryRow(ctx, createUser, arg.Email, arg.Name, arg.PasswordHash, arg.Role)
+	var i CreateUserRow
+	err := row.Scan(&i.ID, &i.CreatedAt)
+	return i, err
+}
+
+const createWebhook = `-- name: CreateWebhook :one
+INSERT INTO webhook (
+  url, event_types, secret
+) VALUES (
+  $1, $2, $3
+)
+RETURNING id, created_at
+`
+
+type CreateWebhookParams struct {
+	Url        string
+	EventTypes []string
+	Secret     string
+}
+
+type CreateWebhookRow struct {
+	ID        pgtype.UUID
+	CreatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) CreateWebhook(ctx context.Context, arg CreateWebhookParams) (CreateWebhookRow, error) {
+	row := q.db.QueryRow(ctx, createWebhook, arg.Url, arg.EventTypes, arg.Secret)
+	var i CreateWebhookRow
+	err := row.Scan(&i.ID, &i.CreatedAt)
+	return i, err
+}
+
+const deadLetterOutboxEvent = `-- name: DeadLetterOutboxEvent :exec
+UPDATE outbox SET status = 'dead', attempts = attempts + 1, last_error = $1 WHERE id = $2
+`
+
+type DeadLetterOutboxEventParams struct {
+	LastError pgtype.Text
+	ID        int64
+}
+
+func (q *Queries) DeadLetterOutboxEvent(ctx context.Context, arg DeadLetterOutboxEventParams) error {
+	_, err := q.db.Exec(ctx, deadLetterOutboxEvent, arg.LastError, arg.ID)
+	return err
+}
+
+const deleteBook = `-- name: DeleteBook :execrows
+DELETE FROM book WHERE id = $1
+`
+
+func (q *Queries) DeleteBook(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteBook, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteBookAuthors = `-- name: DeleteBookAuthors :exec
+DELETE FROM book_author WHERE book_id = $1
+`
+
+func (q *Queries) DeleteBookAuthors(ctx context.Context, bookID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteBookAuthors, bookID)
+	return err
+}
+
+const deleteBookGenres = `-- name: DeleteBookGenres :exec
+DELETE FROM book_genre WHERE book_id = $1
+`
+
+func (q *Queries) DeleteBookGenres(ctx context.Context, bookID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteBookGenres, bookID)
+	return err
+}
+
+const deleteBookPrice = `-- name: DeleteBookPrice :execrows
+DELETE FROM book_price WHERE book_id = $1 AND currency = $2
+`
+
+type DeleteBookPriceParams struct {
+	BookID   pgtype.UUID
+	Currency string
+}
+
+func (q *Queries) DeleteBookPrice(ctx context.Context, arg DeleteBookPriceParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteBookPrice, arg.BookID, arg.Currency)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteCart = `-- name: DeleteCart :exec
+DELETE FROM cart WHERE id = $1
+`
+
+func (q *Queries) DeleteCart(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteCart, id)
+	return err
+}
+
+const deleteCartItem = `-- name: DeleteCartItem :execrows
+DELETE FROM cart_item WHERE cart_id = $1 AND book_id = $2
+`
+
+type DeleteCartItemParams struct {
+	CartID pgtype.UUID
+	BookID pgtype.UUID
+}
+
+func (q *Queries) DeleteCartItem(ctx context.Context, arg DeleteCartItemParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteCartItem, arg.CartID, arg.BookID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteGenre = `-- name: DeleteGenre :execrows
+DELETE FROM genre WHERE id = $1
+`
+
+func (q *Queries) DeleteGenre(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteGenre, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteReview = `-- name: DeleteReview :one
+DELETE FROM review WHERE id = $1 AND user_id = $2 RETURNING book_id, rating
+`
+
+type DeleteReviewParams struct {
+	ID     pgtype.UUID
+	UserID pgtype.UUID
+}
+
+type DeleteReviewRow struct {
+	BookID pgtype.UUID
+	Rating int16
+}
+
+func (q *Queries) DeleteReview(ctx context.Context, arg DeleteReviewParams) (DeleteReviewRow, error) {
+	row := q.db.QueryRow(ctx, deleteReview, arg.ID, arg.UserID)
+	var i DeleteReviewRow
+	err := row.Scan(&i.BookID, &i.Rating)
+	return i, err
+}
+
+const deleteWebhook = `-- name: DeleteWebhook :execrows
+DELETE FROM webhook WHERE id = $1
+`
+
+func (q *Queries) DeleteWebhook(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteWebhook, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deliverOutboxEvent = `-- name: DeliverOutboxEvent :exec
+UPDATE outbox SET status = 'delivered', attempts = attempts + 1, last_error = NULL, delivered_at = NOW() WHERE id = $1
+`
+
+func (q *Queries) DeliverOutboxEvent(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, deliverOutboxEvent, id)
+	return err
+}
+
+const getApiKeys = `-- name: GetApiKeys :many
+SELECT id, user_id, name, prefix, key_hash, scopes, last_used_at, revoked_at, created_at FROM api_key WHERE user_id = $1 AND revoked_at IS NULL ORDER BY created_at DESC
+`
+
+func (q *Queries) GetApiKeys(ctx context.Context, userID pgtype.UUID) ([]ApiKey, error) {
+	rows, err := q.db.Query(ctx, getApiKeys, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ApiKey
+	for rows.Next() {
+		var i ApiKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Prefix,
+			&i.KeyHash,
+			&i.Scopes,
+			&i.LastUsedAt,
+			&i.RevokedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAuthorById = `-- name: GetAuthorById :one
+SELECT
+  author.id,
+  author.name,
+  author.bio,
+  author.photo_url,
+  author.created_at,
+  COUNT(DISTINCT book_author.book_id) AS book_count
+FROM
+  author
+LEFT JOIN
+  book_author ON book_author.author_id = author.id
+WHERE
+  author.id = $1
+GROUP BY
+  author.id
+`
+
+type GetAuthorByIdRow struct {
+	ID        pgtype.UUID
+	Name      string
+	Bio       pgtype.Text
+	PhotoUrl  pgtype.Text
+	CreatedAt pgtype.Timestamptz
+	BookCount int64
+}
+
+func (q *Queries) GetAuthorById(ctx context.Context, id pgtype.UUID) (GetAuthorByIdRow, error) {
+	row := q.db.QueryRow(ctx, getAuthorById, id)
+	var i GetAuthorByIdRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Bio,
+		&i.PhotoUrl,
+		&i.CreatedAt,
+		&i.BookCount,
+	)
+	return i, err
+}
+
+const getAuthors = `-- name: GetAuthors :many
+SELECT
+  author.id,
+  author.name,
+  author.bio,
+  author.photo_url,
+  author.created_at,
+  COUNT(DISTINCT book_author.book_id) AS book_count
+FROM
+  author
+LEFT JOIN
+  book_author ON book_author.author_id = author.id
+WHERE
+  author.name ILIKE $1::text
+GROUP BY
+  author.id
+ORDER BY
+  author.name, author.id
+LIMIT $2 OFFSET $3
+`
+
+type GetAuthorsParams struct {
+	Name        string
+	LimitCount  int32
+	OffsetCount int32
+}
+
+type GetAuthorsRow struct {
+	ID        pgtype.UUID
+	Name      string
+	Bio       pgtype.Text
+	PhotoUrl  pgtype.Text
+	CreatedAt pgtype.Timestamptz
+	BookCount int64
+}
+
+func (q *Queries) GetAuthors(ctx context.Context, arg GetAuthorsParams) ([]GetAuthorsRow, error) {
+	rows, err := q.db.Query(ctx, getAuthors, arg.Name, arg.LimitCount, arg.OffsetCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAuthorsRow
+	for rows.Next() {
+		var i GetAuthorsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Bio,
+			&i.PhotoUrl,
+			&i.CreatedAt,
+			&i.BookCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getBookAuthors = `-- name: GetBookAuthors :many
+SELECT
+  author.id,
+  author.name,
+  book_author.role
+FROM
+  book_author
+INNER JOIN
+  author ON author.id = book_author.author_id
+WHERE
+  book_author.book_id = $1
+ORDER BY
+  book_author.position
+`
+
+type GetBookAuthorsRow struct {
+	ID   pgtype.UUID
+	Name string
+	Role string
+}
+
+func (q *Queries) GetBookAuthors(ctx context.Context, bookID pgtype.UUID) ([]GetBookAuthorsRow, error) {
+	rows, err := q.db.Query(ctx, getBookAuthors, bookID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetBookAuthorsRow
+	for rows.Next() {
+		var i GetBookAuthorsRow
+		if err := rows.Scan(&i.ID, &i.Name, &i.Role); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getBookById = `-- name: GetBookById :one
+SELECT
+  book.id,
+  book.title,
+  book.description,
+  book.author,
+  COALESCE(book_price.price, convert_price(book.price, exchange_rate.rate, exchange_rate.rounding), book.price)::numeric AS price,
+  book.cover_image,
+  COALESCE(stock.quantity_on_hand, 0)::integer AS quantity_on_hand,
+  COALESCE(stock.reserved, 0)::integer AS reserved,
+  COALESCE(ARRAY_AGG(genre.name) FILTER (WHERE genre.name IS NOT NULL), '{}') AS genres,
+  book.created_at,
+  book.updated_at,
+  book.average_rating,
+  book.review_count,
+  (
+    SELECT
+      COALESCE(JSON_AGG(JSON_BUILD_OBJECT('id', author.id, 'name', author.name, 'role', book_author.role) ORDER BY book_author.position), '[]')
+    FROM
+      book_author
+    INNER JOIN
+      author ON author.id = book_author.author_id
+    WHERE
+      book_author.book_id = book.id
+  ) AS authors,
+  book.isbn13,
+  book.publisher,
+  book.publication_date,
+  book.edition,
+  book.page_count,
+  book.language,
+  book.format,
+  (
+    SELECT JSON_BUILD_OBJECT('id', series.id, 'name', series.name) FROM series WHERE series.id = book.series_id
+  ) AS series,
+  book.series_position
+FROM
+  book
+LEFT JOIN
+  stock ON stock.book_id = book.id
+LEFT JOIN
+  exchange_rate ON exchange_rate.currency = $1::text
+LEFT JOIN
+  book_price ON book_price.book_id = book.id AND book_price.currency = $1::text
+LEFT JOIN
+  book_genre ON book_genre.book_id = book.id
+LEFT JOIN
+  genre ON genre.id = book_genre.genre_id
+WHERE
+  book.id = $2
+GROUP BY
+  book.id, stock.book_id, exchange_rate.currency, book_price.book_id, book_price.currency
+`
+
+type GetBookByIdParams struct {
+	Currency string
+	ID       pgtype.UUID
+}
+
+type GetBookByIdRow struct {
+	ID              pgtype.UUID
+	Title           string
+	Description     pgtype.Text
+	Author          string
+	Price           pgtype.Numeric
+	CoverImage      pgtype.Text
+	QuantityOnHand  int32
+	Reserved        int32
+	Genres          interface{}
+	CreatedAt       pgtype.Timestamptz
+	UpdatedAt       pgtype.Timestamptz
+	AverageRating   pgtype.Numeric
+	ReviewCount     int32
+	Authors         []byte
+	Isbn13          pgtype.Text
+	Publisher       pgtype.Text
+	PublicationDate pgtype.Date
+	Edition         pgtype.Text
+	PageCount       pgtype.Int4
+	Language        pgtype.Text
+	Format          pgtype.Text
+	Series          []byte
+	SeriesPosition  pgtype.Numeric
+}
+
+func (q *Queries) GetBookById(ctx context.Context, arg GetBookByIdParams) (GetBookByIdRow, error) {
+	row := q.db.QueryRow(ctx, getBookById, arg.Currency, arg.ID)
+	var i GetBookByIdRow
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.Author,
+		&i.Price,
+		&i.CoverImage,
+		&i.QuantityOnHand,
+		&i.Reserved,
+		&i.Genres,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AverageRating,
+		&i.ReviewCount,
+		&i.Authors,
+		&i.Isbn13,
+		&i.Publisher,
+		&i.PublicationDate,
+		&i.Edition,
+		&i.PageCount,
+		&i.Language,
+		&i.Format,
+		&i.Series,
+		&i.SeriesPosition,
+	)
+	return i, err
+}
+
+const getBookIdByIsbn = `-- name: GetBookIdByIsbn :one
+SELECT id FROM book WHERE isbn13 = $1
+`
+
+func (q *Queries) GetBookIdByIsbn(ctx context.Context, isbn13 pgtype.Text) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, getBookIdByIsbn, isbn13)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const getBookReviewCount = `-- name: GetBookReviewCount :one
+SELECT review_count FROM book WHERE id = $1
+`
+
+func (q *Queries) GetBookReviewCount(ctx context.Context, id pgtype.UUID) (int32, error) {
+	row := q.db.QueryRow(ctx, getBookReviewCount, id)
+	var review_count int32
+	err := row.Scan(&review_count)
+	return review_count, err
+}
+
+const getCart = `-- name: GetCart :one
+SELECT id, created_at FROM cart WHERE id = $1
+`
+
+func (q *Queries) GetCart(ctx context.Context, id pgtype.UUID) (Cart, error) {
+	row := q.db.QueryRow(ctx, getCart, id)
+	var i Cart
+	err := row.Scan(&i.ID, &i.CreatedAt)
+	return i, err
+}
+
+const getCartItems = `-- name: GetCartItems :many
+SELECT
+  book.id AS book_id,
+  book.title,
+  book.author,
+  book.price,
+  cart_item.quantity
+FROM
+  cart_item
+INNER JOIN
+  book ON book.id = cart_item.book_id
+WHERE
+  cart_item.cart_id = $1
+ORDER BY
+  book.title
+`
+
+type GetCartItemsRow struct {
+	BookID   pgtype.UUID
+	Title    string
+	Author   string
+	Price    pgtype.Numeric
+	Quantity int32
+}
+
+func (q *Queries) GetCartItems(ctx context.Context, cartID pgtype.UUID) ([]GetCartItemsRow, error) {
+	rows, err := q.db.Query(ctx, getCartItems, cartID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetCartItemsRow
+	for rows.Next() {
+		var i GetCartItemsRow
+		if err := rows.Scan(
+			&i.BookID,
+			&i.Title,
+			&i.Author,
+			&i.Price,
+			&i.Quantity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChanges = `-- name: GetChanges :many
+SELECT id, transaction_id, entity, entity_id, action, data, created_at FROM change_log
+WHERE
+  (transaction_id, id) > ($1::bigint, $2::bigint)
+AND
+  transaction_id < pg_snapshot_xmin(pg_current_snapshot())::text::bigint
+ORDER BY
+  transaction_id, id
+LIMIT
+  $3
+`
+
+type GetChangesParams struct {
+	TransactionID int64
+	ID            int64
+	MaxChanges    int32
+}
+
+// only the changes of the transactions that ended before the oldest running transaction are returned,
+// a running transaction could still add a change before the last returned one
+func (q *Queries) GetChanges(ctx context.Context, arg GetChangesParams) ([]ChangeLog, error) {
+	rows, err := q.db.Query(ctx, getChanges, arg.TransactionID, arg.ID, arg.MaxChanges)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ChangeLog
+	for rows.Next() {
+		var i ChangeLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.TransactionID,
+			&i.Entity,
+			&i.EntityID,
+			&i.Action,
+			&i.Data,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getExchangeRate = `-- name: GetExchangeRate :one
+SELECT currency, rate, rounding, updated_at FROM exchange_rate WHERE currency = $1
+`
+
+func (q *Queries) GetExchangeRate(ctx context.Context, currency string) (ExchangeRate, error) {
+	row := q.db.QueryRow(ctx, getExchangeRate, currency)
+	var i ExchangeRate
+	err := row.Scan(
+		&i.Currency,
+		&i.Rate,
+		&i.Rounding,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getExchangeRates = `-- name: GetExchangeRates :many
+SELECT currency, rate, rounding, updated_at FROM exchange_rate ORDER BY currency
+`
+
+func (q *Queries) GetExchangeRates(ctx context.Context) ([]ExchangeRate, error) {
+	rows, err := q.db.Query(ctx, getExchangeRates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExchangeRate
+	for rows.Next() {
+		var i ExchangeRate
+		if err := rows.Scan(
+			&i.Currency,
+			&i.Rate,
+			&i.Rounding,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGenreBookIds = `-- name: GetGenreBookIds :many
+SELECT book_id FROM book_genre WHERE genre_id = $1
+`
+
+func (q *Queries) GetGenreBookIds(ctx context.Context, genreID pgtype.UUID) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, getGenreBookIds, genreID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.UUID
+	for rows.Next() {
+		var book_id pgtype.UUID
+		if err := rows.Scan(&book_id); err != nil {
+			return nil, err
+		}
+		items = append(items, book_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGenreById = `-- name: GetGenreById :one
+SELECT
+  genre.id,
+  genre.name,
+  COUNT(book_genre.id) AS book_count
+FROM
+  genre
+LEFT JOIN
+  book_genre ON book_genre.genre_id = genre.id
+WHERE
+  genre.id = $1
+GROUP BY
+  genre.id
+`
+
+type GetGenreByIdRow struct {
+	ID        pgtype.UUID
+	Name      pgtype.Text
+	BookCount int64
+}
+
+func (q *Queries) GetGenreById(ctx context.Context, id pgtype.UUID) (GetGenreByIdRow, error) {
+	row := q.db.QueryRow(ctx, getGenreById, id)
+	var i GetGenreByIdRow
+	err := row.Scan(&i.ID, &i.Name, &i.BookCount)
+	return i, err
+}
+
+const getGenreByName = `-- name: GetGenreByName :one
+SELECT id, name FROM genre WHERE name = $1
+`
+
+func (q *Queries) GetGenreByName(ctx context.Context, name pgtype.Text) (Genre, error) {
+	row := q.db.QueryRow(ctx, getGenreByName, name)
+	var i Genre
+	err := row.Scan(&i.ID, &i.Name)
+	return i, err
+}
+
+const getGenres = `-- name: GetGenres :many
+SELECT
+  genre.id,
+  genre.name,
+  COUNT(book_genre.id) AS book_count
+FROM
+  genre
+LEFT JOIN
+  book_genre ON book_genre.genre_id = genre.id
+GROUP BY
+  genre.id
+ORDER BY
+  genre.name
+`
+
+type GetGenresRow struct {
+	ID        pgtype.UUID
+	Name      pgtype.Text
+	BookCount int64
+}
+
+func (q *Queries) GetGenres(ctx context.Context) ([]GetGenresRow, error) {
+	rows, err := q.db.Query(ctx, getGenres)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetGenresRow
+	for rows.Next() {
+		var i GetGenresRow
+		if err := rows.Scan(&i.ID, &i.Name, &i.BookCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrder = `-- name: GetOrder :one
+SELECT id, status, created_at, updated_at FROM orders WHERE id = $1
+`
+
+func (q *Queries) GetOrder(ctx context.Context, id pgtype.UUID) (Order, error) {
+	row := q.db.QueryRow(ctx, getOrder, id)
+	var i Order
+	err := row.Scan(
+		&i.ID,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getOrderItems = `-- name: GetOrderItems :many
+SELECT id, order_id, book_id, title, author, price, quantity FROM order_item WHERE order_id = ANY($1::uuid[]) ORDER BY title
+`
+
+func (q *Queries) GetOrderItems(ctx context.Context, orderIds []pgtype.UUID) ([]OrderItem, error) {
+	rows, err := q.db.Query(ctx, getOrderItems, orderIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OrderItem
+	for rows.Next() {
+		var i OrderItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrderID,
+			&i.BookID,
+			&i.Title,
+			&i.Author,
+			&i.Price,
+			&i.Quantity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrders = `-- name: GetOrders :many
+SELECT id, status, created_at, updated_at FROM orders ORDER BY created_at DESC LIMIT $1 OFFSET $2
+`
+
+type GetOrdersParams struct {
+	Limit  int32
+	Offset int32
+}
+
+func (q *Queries) GetOrders(ctx context.Context, arg GetOrdersParams) ([]Order, error) {
+	rows, err := q.db.Query(ctx, getOrders, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Order
+	for rows.Next() {
+		var i Order
+		if err := rows.Scan(
+			&i.ID,
+			&i.Status,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPaymentByOrderId = `-- name: GetPaymentByOrderId :one
+SELECT id, order_id, provider_payment_id, status, amount, currency, created_at, updated_at FROM payment WHERE order_id = $1 AND status = $2 ORDER BY created_at DESC LIMIT 1
+`
+
+type GetPaymentByOrderIdParams struct {
+	OrderID pgtype.UUID
+	Status  string
+}
+
+func (q *Queries) GetPaymentByOrderId(ctx context.Context, arg GetPaymentByOrderIdParams) (Payment, error) {
+	row := q.db.QueryRow(ctx, getPaymentByOrderId, arg.OrderID, arg.Status)
+	var i Payment
+	err := row.Scan(
+		&i.ID,
+		&i.OrderID,
+		&i.ProviderPaymentID,
+		&i.Status,
+		&i.Amount,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getPaymentByProviderPaymentId = `-- name: GetPaymentByProviderPaymentId :one
+SELECT id, order_id, provider_payment_id, status, amount, currency, created_at, updated_at FROM payment WHERE provider_payment_id = $1
+`
+
+func (q *Queries) GetPaymentByProviderPaymentId(ctx context.Context, providerPaymentID pgtype.Text) (Payment, error) {
+	row := q.db.QueryRow(ctx, getPaymentByProviderPaymentId, providerPaymentID)
+	var i Payment
+	err := row.Scan(
+		&i.ID,
+		&i.OrderID,
+		&i.ProviderPaymentID,
+		&i.Status,
+		&i.Amount,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getReviewForUpdate = `-- name: GetReviewForUpdate :one
+SELECT id, book_id, user_id, rating, body, created_at, updated_at FROM review WHERE id = $1 AND user_id = $2 FOR UPDATE
+`
+
+type GetReviewForUpdateParams struct {
+	ID     pgtype.UUID
+	UserID pgtype.UUID
+}
+
+func (q *Queries) GetReviewForUpdate(ctx context.Context, arg GetReviewForUpdateParams) (Review, error) {
+	row := q.db.QueryRow(ctx, getReviewForUpdate, arg.ID, arg.UserID)
+	var i Review
+	err := row.Scan(
+		&i.ID,
+		&i.BookID,
+		&i.UserID,
+		&i.Rating,
+		&i.Body,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getReviews = `-- name: GetReviews :many
+SELECT
+  review.id,
+  review.book_id,
+  review.user_id,
+  users.name AS user_name,
+  review.rating,
+  review.body,
+  review.created_at,
+  review.updated_at
+FROM
+  review
+INNER JOIN
+  users ON users.id = review.user_id
+WHERE
+  review.book_id = $1
+ORDER BY
+  review.created_at DESC, review.id
+LIMIT $2 OFFSET $3
+`
+
+type GetReviewsParams struct {
+	BookID pgtype.UUID
+	Limit  int32
+	Offset int32
+}
+
+type GetReviewsRow struct {
+	ID        pgtype.UUID
+	BookID    pgtype.UUID
+	UserID    pgtype.UUID
+	UserName  string
+	Rating    int16
+	Body      string
+	CreatedAt pgtype.Timestamptz
+	UpdatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) GetReviews(ctx context.Context, arg GetReviewsParams) ([]GetReviewsRow, error) {
+	rows, err := q.db.Query(ctx, getReviews, arg.BookID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetReviewsRow
+	for rows.Next() {
+		var i GetReviewsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.BookID,
+			&i.UserID,
+			&i.UserName,
+			&i.Rating,
+			&i.Body,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSeriesById = `-- name: GetSeriesById :one
+SELECT
+  series.id,
+  series.name,
+  series.description,
+  series.created_at,
+  COUNT(book.id) AS book_count
+FROM
+  series
+LEFT JOIN
+  book ON book.series_id = series.id
+WHERE
+  series.id = $1
+GROUP BY
+  series.id
+`
+
+type GetSeriesByIdRow struct {
+	ID          pgtype.UUID
+	Name        string
+	Description pgtype.Text
+	CreatedAt   pgtype.Timestamptz
+	BookCount   int64
+}
+
+func (q *Queries) GetSeriesById(ctx context.Context, id pgtype.UUID) (GetSeriesByIdRow, error) {
+	row := q.db.QueryRow(ctx, getSeriesById, id)
+	var i GetSeriesByIdRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.CreatedAt,
+		&i.BookCount,
+	)
+	return i, err
+}
+
+const getStock = `-- name: GetStock :one
+SELECT
+  book.id AS book_id,
+  COALESCE(stock.quantity_on_hand, 0)::integer AS quantity_on_hand,
+  COALESCE(stock.reserved, 0)::integer AS reserved
+FROM
+  book
+LEFT JOIN
+  stock ON stock.book_id = book.id
+WHERE
+  book.id = $1
+`
+
+type GetStockRow struct {
+	BookID         pgtype.UUID
+	QuantityOnHand int32
+	Reserved       int32
+}
+
+func (q *Queries) GetStock(ctx context.Context, id pgtype.UUID) (GetStockRow, error) {
+	row := q.db.QueryRow(ctx, getStock, id)
+	var i GetStockRow
+	err := row.Scan(&i.BookID, &i.QuantityOnHand, &i.Reserved)
+	return i, err
+}
+
+const getStockAdjustments = `-- name: GetStockAdjustments :many
+SELECT id, book_id, quantity, reason, note, quantity_on_hand, created_at FROM stock_adjustment WHERE book_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3
+`
+
+type GetStockAdjustmentsParams struct {
+	BookID pgtype.UUID
+	Limit  int32
+	Offset int32
+}
+
+func (q *Queries) GetStockAdjustments(ctx context.Context, arg GetStockAdjustmentsParams) ([]StockAdjustment, error) {
+	rows, err := q.db.Query(ctx, getStockAdjustments, arg.BookID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []StockAdjustment
+	for rows.Next() {
+		var i StockAdjustment
+		if err := rows.Scan(
+			&i.ID,
+			&i.BookID,
+			&i.Quantity,
+			&i.Reason,
+			&i.Note,
+			&i.QuantityOnHand,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserByEmail = `-- name: GetUserByEmail :one
+SELECT id, email, name, password_hash, created_at, role FROM users WHERE email = $1
+`
+
+func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	row := q.db.QueryRow(ctx, getUserByEmail, email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.PasswordHash,
+		&i.CreatedAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const getUserById = `-- name: GetUserById :one
+SELECT id, email, name, password_hash, created_at, role FROM users WHERE id = $1
+`
+
+func (q *Queries) GetUserById(ctx context.Context, id pgtype.UUID) (User, error) {
+	row := q.db.QueryRow(ctx, getUserById, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.PasswordHash,
+		&i.CreatedAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const moveBookGenres = `-- name: MoveBookGenres :exec
+UPDATE book_genre SET genre_id = $1
+WHERE
+  book_genre.genre_id = $2
+AND NOT EXISTS (
+  SELECT 1 FROM book_genre AS target WHERE target.book_id = book_genre.book_id AND target.genre_id = $1
+)
+`
+
+type MoveBookGenresParams struct {
+	TargetID pgtype.UUID
+	SourceID pgtype.UUID
+}
+
+// books that already have the target genre keep their row, the source row is removed with the source genre
+func (q *Queries) MoveBookGenres(ctx context.Context, arg MoveBookGenresParams) error {
+	_, err := q.db.Exec(ctx, moveBookGenres, arg.TargetID, arg.SourceID)
+	return err
+}
+
+const releaseStock = `-- name: ReleaseStock :exec
+UPDATE stock SET reserved = reserved - $1::integer WHERE book_id = $2
+`
+
+type ReleaseStockParams struct {
+	Quantity int32
+	BookID   pgtype.UUID
+}
+
+func (q *Queries) ReleaseStock(ctx context.Context, arg ReleaseStockParams) error {
+	_, err := q.db.Exec(ctx, releaseStock, arg.Quantity, arg.BookID)
+	return err
+}
+
+const reserveStock = `-- name: ReserveStock :one
+UPDATE stock SET reserved = reserved + $1::integer
+WHERE
+  book_id = $2
+RETURNING reserved
+`
+
+type ReserveStockParams struct {
+	Quantity int32
+	BookID   pgtype.UUID
+}
+
+// the check constraints on stock reject the update if there is not enough stock left to reserve
+func (q *Queries) ReserveStock(ctx context.Context, arg ReserveStockParams) (int32, error) {
+	row := q.db.QueryRow(ctx, reserveStock, arg.Quantity, arg.BookID)
+	var reserved int32
+	err := row.Scan(&reserved)
+	return reserved, err
+}
+
+const retryOutboxEvent = `-- name: RetryOutboxEvent :exec
+UPDATE outbox
+SET
+  attempts = attempts + 1,
+  last_error = $1,
+  next_attempt_at = NOW() + $2::bigint * INTERVAL '1 millisecond'
+WHERE
+  id = $3
+`
+
+type RetryOutboxEventParams struct {
+	LastError pgtype.Text
+	DelayMs   int64
+	ID        int64
+}
+
+func (q *Queries) RetryOutboxEvent(ctx context.Context, arg RetryOutboxEventParams) error {
+	_, err := q.db.Exec(ctx, retryOutboxEvent, arg.LastError, arg.DelayMs, arg.ID)
+	return err
+}
+
+const revokeApiKey = `-- name: RevokeApiKey :execrows
+UPDATE api_key SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+`
+
+type RevokeApiKeyParams struct {
+	ID     pgtype.UUID
+	UserID pgtype.UUID
+}
+
+func (q *Queries) RevokeApiKey(ctx context.Context, arg RevokeApiKeyParams) (int64, error) {
+	result, err := q.db.Exec(ctx, revokeApiKey, arg.ID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const revokeRefreshToken = `-- name: RevokeRefreshToken :one
+UPDATE refresh_token SET revoked_at = NOW() WHERE token_hash = $1 AND revoked_at IS NULL AND expires_at > NOW() RETURNING user_id
+`
+
+func (q *Queries) RevokeRefreshToken(ctx context.Context, tokenHash string) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, revokeRefreshToken, tokenHash)
+	var user_id pgtype.UUID
+	err := row.Scan(&user_id)
+	return user_id, err
+}
+
+const test = `-- name: test :many
+SELECT name FROM genre where name ilike $1::text[]
+`
+
+func (q *Queries) test(ctx context.Context, genres []string) ([]pgtype.Text, error) {
+	rows, err := q.db.Query(ctx, test, genres)
+	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
@@ -310,3 +1910,351 @@ func (q *Queries) test(ctx context.Context, genres []string) ([]pgtype.Text, err
 	}
 	return items, nil
 }
+
+const touchBooks = `-- name: TouchBooks :many
+UPDATE book SET updated_at = NOW() WHERE id = ANY($1::uuid[]) RETURNING id
+`
+
+// marks the books as updated after a change made through another table, such as their genre, stock or rating
+func (q *Queries) TouchBooks(ctx context.Context, ids []pgtype.UUID) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, touchBooks, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.UUID
+	for rows.Next() {
+		var id pgtype.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateBook = `-- name: UpdateBook :one
+UPDATE book SET
+  title = COALESCE($1, title),
+  author = COALESCE($2, author),
+  description = COALESCE($3, description),
+  price = COALESCE($4, price),
+  cover_image = COALESCE($5, cover_image),
+  isbn13 = NULLIF(COALESCE($6, isbn13), ''),
+  publisher = NULLIF(COALESCE($7, publisher), ''),
+  publication_date = CASE WHEN $8::boolean THEN $9 ELSE publication_date END,
+  edition = NULLIF(COALESCE($10, edition), ''),
+  page_count = CASE WHEN $11::boolean THEN $12 ELSE page_count END,
+  language = NULLIF(COALESCE($13, language), ''),
+  format = NULLIF(COALESCE($14, format), ''),
+  series_id = CASE WHEN $15::boolean THEN $16 ELSE series_id END,
+  series_position = CASE WHEN $15::boolean THEN $17 ELSE series_position END,
+  updated_at = NOW()
+WHERE
+  id = $18
+RETURNING id
+`
+
+type UpdateBookParams struct {
+	Title              pgtype.Text
+	Author             pgtype.Text
+	Description        pgtype.Text
+	Price              pgtype.Numeric
+	CoverImage         pgtype.Text
+	Isbn13             pgtype.Text
+	Publisher          pgtype.Text
+	SetPublicationDate bool
+	PublicationDate    pgtype.Date
+	Edition            pgtype.Text
+	SetPageCount       bool
+	PageCount          pgtype.Int4
+	Language           pgtype.Text
+	Format             pgtype.Text
+	SetSeries          bool
+	SeriesID           pgtype.UUID
+	SeriesPosition     pgtype.Numeric
+	ID                 pgtype.UUID
+}
+
+func (q *Queries) UpdateBook(ctx context.Context, arg UpdateBookParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, updateBook,
+		arg.Title,
+		arg.Author,
+		arg.Description,
+		arg.Price,
+		arg.CoverImage,
+		arg.Isbn13,
+		arg.Publisher,
+		arg.SetPublicationDate,
+		arg.PublicationDate,
+		arg.Edition,
+		arg.SetPageCount,
+		arg.PageCount,
+		arg.Language,
+		arg.Format,
+		arg.SetSeries,
+		arg.SeriesID,
+		arg.SeriesPosition,
+		arg.ID,
+	)
+	var id pgtype.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const updateBookRating = `-- name: UpdateBookRating :exec
+UPDATE book SET
+  rating_sum = rating_sum + $1::integer,
+  review_count = review_count + $2::integer
+WHERE
+  id = $3
+`
+
+type UpdateBookRatingParams struct {
+	RatingDelta int32
+	CountDelta  int32
+	ID          pgtype.UUID
+}
+
+// adds the difference a review made to the ratings of the book
+func (q *Queries) UpdateBookRating(ctx context.Context, arg UpdateBookRatingParams) error {
+	_, err := q.db.Exec(ctx, updateBookRating, arg.RatingDelta, arg.CountDelta, arg.ID)
+	return err
+}
+
+const updateCartItem = `-- name: UpdateCartItem :execrows
+UPDATE cart_item SET quantity = $1 WHERE cart_id = $2 AND book_id = $3
+`
+
+type UpdateCartItemParams struct {
+	Quantity int32
+	CartID   pgtype.UUID
+	BookID   pgtype.UUID
+}
+
+func (q *Queries) UpdateCartItem(ctx context.Context, arg UpdateCartItemParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateCartItem, arg.Quantity, arg.CartID, arg.BookID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateGenre = `-- name: UpdateGenre :execrows
+UPDATE genre SET name = $1 WHERE id = $2
+`
+
+type UpdateGenreParams struct {
+	Name pgtype.Text
+	ID   pgtype.UUID
+}
+
+func (q *Queries) UpdateGenre(ctx context.Context, arg UpdateGenreParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateGenre, arg.Name, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateOrderStatus = `-- name: UpdateOrderStatus :execrows
+UPDATE orders SET status = $1, updated_at = NOW() WHERE id = $2 AND status = $3
+`
+
+type UpdateOrderStatusParams struct {
+	Status        string
+	ID            pgtype.UUID
+	CurrentStatus string
+}
+
+func (q *Queries) UpdateOrderStatus(ctx context.Context, arg UpdateOrderStatusParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateOrderStatus, arg.Status, arg.ID, arg.CurrentStatus)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updatePaymentStatus = `-- name: UpdatePaymentStatus :one
+UPDATE payment SET status = $1, updated_at = NOW() WHERE id = $2 AND status = $3 RETURNING id, order_id, provider_payment_id, status, amount, currency, created_at, updated_at
+`
+
+type UpdatePaymentStatusParams struct {
+	Status        string
+	ID            pgtype.UUID
+	CurrentStatus string
+}
+
+func (q *Queries) UpdatePaymentStatus(ctx context.Context, arg UpdatePaymentStatusParams) (Payment, error) {
+	row := q.db.QueryRow(ctx, updatePaymentStatus, arg.Status, arg.ID, arg.CurrentStatus)
+	var i Payment
+	err := row.Scan(
+		&i.ID,
+		&i.OrderID,
+		&i.ProviderPaymentID,
+		&i.Status,
+		&i.Amount,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateReview = `-- name: UpdateReview :one
+UPDATE review SET
+  rating = COALESCE($1, rating),
+  body = COALESCE($2, body),
+  updated_at = NOW()
+WHERE
+  id = $3
+RETURNING id, book_id, user_id, rating, body, created_at, updated_at
+`
+
+type UpdateReviewParams struct {
+	Rating pgtype.Int2
+	Body   pgtype.Text
+	ID     pgtype.UUID
+}
+
+func (q *Queries) UpdateReview(ctx context.Context, arg UpdateReviewParams) (Review, error) {
+	row := q.db.QueryRow(ctx, updateReview, arg.Rating, arg.Body, arg.ID)
+	var i Review
+	err := row.Scan(
+		&i.ID,
+		&i.BookID,
+		&i.UserID,
+		&i.Rating,
+		&i.Body,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateUserRole = `-- name: UpdateUserRole :one
+UPDATE users SET role = $2 WHERE id = $1 RETURNING id, email, name, password_hash, created_at, role
+`
+
+type UpdateUserRoleParams struct {
+	ID   pgtype.UUID
+	Role string
+}
+
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserRole, arg.ID, arg.Role)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.PasswordHash,
+		&i.CreatedAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const upsertAuthor = `-- name: UpsertAuthor :one
+INSERT INTO author (
+  name
+) VALUES (
+  $1
+)
+ON CONFLICT ((LOWER(name))) DO UPDATE SET name = author.name
+RETURNING id, name
+`
+
+type UpsertAuthorRow struct {
+	ID   pgtype.UUID
+	Name string
+}
+
+// returns the author with the name, case insensitive, the author is created if there is none
+func (q *Queries) UpsertAuthor(ctx context.Context, name string) (UpsertAuthorRow, error) {
+	row := q.db.QueryRow(ctx, upsertAuthor, name)
+	var i UpsertAuthorRow
+	err := row.Scan(&i.ID, &i.Name)
+	return i, err
+}
+
+const upsertBookPrice = `-- name: UpsertBookPrice :exec
+INSERT INTO book_price (
+  book_id, currency, price
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (book_id, currency) DO UPDATE SET price = EXCLUDED.price
+`
+
+type UpsertBookPriceParams struct {
+	BookID   pgtype.UUID
+	Currency string
+	Price    pgtype.Numeric
+}
+
+func (q *Queries) UpsertBookPrice(ctx context.Context, arg UpsertBookPriceParams) error {
+	_, err := q.db.Exec(ctx, upsertBookPrice, arg.BookID, arg.Currency, arg.Price)
+	return err
+}
+
+const upsertExchangeRate = `-- name: UpsertExchangeRate :exec
+INSERT INTO exchange_rate (
+  currency, rate, rounding
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (currency) DO UPDATE SET rate = EXCLUDED.rate, rounding = EXCLUDED.rounding, updated_at = NOW()
+`
+
+type UpsertExchangeRateParams struct {
+	Currency string
+	Rate     pgtype.Numeric
+	Rounding pgtype.Numeric
+}
+
+func (q *Queries) UpsertExchangeRate(ctx context.Context, arg UpsertExchangeRateParams) error {
+	_, err := q.db.Exec(ctx, upsertExchangeRate, arg.Currency, arg.Rate, arg.Rounding)
+	return err
+}
+
+const useApiKey = `-- name: UseApiKey :one
+UPDATE api_key
+SET
+  last_used_at = NOW()
+FROM
+  users
+WHERE
+  api_key.user_id = users.id
+AND
+  api_key.key_hash = $1
+AND
+  api_key.revoked_at IS NULL
+RETURNING
+  api_key.scopes, users.id, users.email, users.name, users.role
+`
+
+type UseApiKeyRow struct {
+	Scopes []string
+	ID     pgtype.UUID
+	Email  string
+	Name   string
+	Role   string
+}
+
+// returns the scopes of the key with its user and records that the key was used
+func (q *Queries) UseApiKey(ctx context.Context, keyHash string) (UseApiKeyRow, error) {
+	row := q.db.QueryRow(ctx, useApiKey, keyHash)
+	var i UseApiKeyRow
+	err := row.Scan(
+		&i.Scopes,
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.Role,
+	)
+	return i, err
+}
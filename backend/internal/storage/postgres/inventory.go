@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cativovo/bookstore/internal/book"
+	"github.com/cativovo/bookstore/internal/inventory"
+	query "github.com/cativovo/bookstore/internal/storage/postgres/generated"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (pr *PostgresRepository) AdjustStock(ctx context.Context, a inventory.Adjustment) (inventory.Adjustment, error) {
+	var bookUuid pgtype.UUID
+	if err := bookUuid.Scan(a.BookId); err != nil {
+		return inventory.Adjustment{}, book.ErrNotFound
+	}
+
+	return withTimeout(ctx, func(ctxWithTimeout context.Context) (inventory.Adjustment, error) {
+		tx, err := pr.pool.Begin(ctxWithTimeout)
+		if err != nil {
+			return inventory.Adjustment{}, err
+		}
+		defer tx.Rollback(ctxWithTimeout)
+		qtx := pr.queries.WithTx(tx)
+
+		// the update locks the stock row so concurrent adjustments are applied one after the other
+		stock, err := qtx.AdjustStock(ctxWithTimeout, query.AdjustStockParams{
+			BookID:   bookUuid,
+			Quantity: int32(a.Quantity),
+		})
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				return inventory.Adjustment{}, book.ErrNotFound
+			}
+
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) {
+				switch pgErr.Code {
+				case pgerrcode.CheckViolation:
+					return inventory.Adjustment{}, inventory.ErrInsufficientStock
+				}
+			}
+
+			return inventory.Adjustment{}, err
+		}
+
+		row, err := qtx.CreateStockAdjustment(ctxWithTimeout, query.CreateStockAdjustmentParams{
+			BookID:         bookUuid,
+			Quantity:       int32(a.Quantity),
+			Reason:         string(a.Reason),
+			Note:           pgtype.Text{String: a.Note, Valid: a.Note != ""},
+			QuantityOnHand: stock.QuantityOnHand,
+		})
+		if err != nil {
+			return inventory.Adjustment{}, err
+		}
+
+		if err := touchBooks(ctxWithTimeout, qtx, []pgtype.UUID{bookUuid}); err != nil {
+			return inventory.Adjustment{}, err
+		}
+
+		if err := tx.Commit(ctxWithTimeout); err != nil {
+			return inventory.Adjustment{}, err
+		}
+
+		id, err := row.ID.Value()
+		if err != nil {
+			return inventory.Adjustment{}, err
+		}
+
+		a.Id = id.(string)
+		a.QuantityOnHand = int(stock.QuantityOnHand)
+		a.CreatedAt = row.CreatedAt.Time
+
+		return a, nil
+	})
+}
+
+func (pr *PostgresRepository) GetAdjustments(ctx context.Context, bookId string, limit int, offset int) ([]inventory.Adjustment, int, error) {
+	var bookUuid pgtype.UUID
+	if err := bookUuid.Scan(bookId); err != nil {
+		return nil, 0, book.ErrNotFound
+	}
+
+	type result struct {
+		rows  []query.StockAdjustment
+		count int64
+	}
+
+	r, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (result, error) {
+		// check if book exists in db
+		if _, err := pr.queries.GetStock(ctxWithTimeout, bookUuid); err != nil {
+			switch err {
+			case pgx.ErrNoRows:
+				return result{}, book.ErrNotFound
+			default:
+				return result{}, err
+			}
+		}
+
+		count, err := pr.queries.CountStockAdjustments(ctxWithTimeout, bookUuid)
+		if err != nil {
+			return result{}, err
+		}
+
+		rows, err := pr.queries.GetStockAdjustments(ctxWithTimeout, query.GetStockAdjustmentsParams{
+			BookID: bookUuid,
+			Limit:  int32(limit),
+			Offset: int32(offset),
+		})
+		if err != nil {
+			return result{}, err
+		}
+
+		return result{rows: rows, count: count}, nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	adjustments := make([]inventory.Adjustment, len(r.rows))
+
+	for i, v := range r.rows {
+		id, err := v.ID.Value()
+		if err != nil {
+			return nil, 0, err
+		}
+
+		adjustments[i] = inventory.Adjustment{
+			Id:             id.(string),
+			BookId:         bookId,
+			Quantity:       int(v.Quantity),
+			Reason:         inventory.Reason(v.Reason),
+			Note:           v.Note.String,
+			QuantityOnHand: int(v.QuantityOnHand),
+			CreatedAt:      v.CreatedAt.Time,
+		}
+	}
+
+	return adjustments, int(r.count), nil
+}
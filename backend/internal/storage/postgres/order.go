@@ -0,0 +1,385 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cativovo/bookstore/internal/book"
+	"github.com/cativovo/bookstore/internal/cart"
+	"github.com/cativovo/bookstore/internal/inventory"
+	"github.com/cativovo/bookstore/internal/order"
+	query "github.com/cativovo/bookstore/internal/storage/postgres/generated"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (pr *PostgresRepository) CreateOrder(ctx context.Context, items []order.LineItem) (order.Order, error) {
+	return withTimeout(ctx, func(ctxWithTimeout context.Context) (order.Order, error) {
+		tx, err := pr.pool.Begin(ctxWithTimeout)
+		if err != nil {
+			return order.Order{}, err
+		}
+		defer tx.Rollback(ctxWithTimeout)
+		qtx := pr.queries.WithTx(tx)
+
+		o, err := createOrder(ctxWithTimeout, qtx, items)
+		if err != nil {
+			return order.Order{}, err
+		}
+
+		if err := tx.Commit(ctxWithTimeout); err != nil {
+			return order.Order{}, err
+		}
+
+		return o, nil
+	})
+}
+
+func (pr *PostgresRepository) CreateOrderFromCart(ctx context.Context, cartId string) (order.Order, error) {
+	var cartUuid pgtype.UUID
+	if err := cartUuid.Scan(cartId); err != nil {
+		return order.Order{}, cart.ErrNotFound
+	}
+
+	return withTimeout(ctx, func(ctxWithTimeout context.Context) (order.Order, error) {
+		tx, err := pr.pool.Begin(ctxWithTimeout)
+		if err != nil {
+			return order.Order{}, err
+		}
+		defer tx.Rollback(ctxWithTimeout)
+		qtx := pr.queries.WithTx(tx)
+
+		if _, err := qtx.GetCart(ctxWithTimeout, cartUuid); err != nil {
+			switch err {
+			case pgx.ErrNoRows:
+				return order.Order{}, cart.ErrNotFound
+			default:
+				return order.Order{}, err
+			}
+		}
+
+		cartItems, err := qtx.GetCartItems(ctxWithTimeout, cartUuid)
+		if err != nil {
+			return order.Order{}, err
+		}
+
+		if len(cartItems) == 0 {
+			return order.Order{}, order.ErrEmptyOrder
+		}
+
+		items := make([]order.LineItem, len(cartItems))
+
+		for i, v := range cartItems {
+			bookId, err := v.BookID.Value()
+			if err != nil {
+				return order.Order{}, err
+			}
+
+			items[i] = order.LineItem{
+				BookId:   bookId.(string),
+				Quantity: int(v.Quantity),
+			}
+		}
+
+		o, err := createOrder(ctxWithTimeout, qtx, items)
+		if err != nil {
+			return order.Order{}, err
+		}
+
+		if err := qtx.DeleteCart(ctxWithTimeout, cartUuid); err != nil {
+			return order.Order{}, err
+		}
+
+		if err := tx.Commit(ctxWithTimeout); err != nil {
+			return order.Order{}, err
+		}
+
+		return o, nil
+	})
+}
+
+func (pr *PostgresRepository) GetOrderById(ctx context.Context, id string) (order.Order, error) {
+	var orderUuid pgtype.UUID
+	if err := orderUuid.Scan(id); err != nil {
+		return order.Order{}, order.ErrNotFound
+	}
+
+	return withTimeout(ctx, func(ctxWithTimeout context.Context) (order.Order, error) {
+		return getOrderById(ctxWithTimeout, pr.queries, orderUuid)
+	})
+}
+
+func (pr *PostgresRepository) GetOrders(ctx context.Context, limit int, offset int) ([]order.Order, int, error) {
+	type result struct {
+		rows  []query.Order
+		items []query.OrderItem
+		count int64
+	}
+
+	r, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (result, error) {
+		count, err := pr.queries.CountOrders(ctxWithTimeout)
+		if err != nil {
+			return result{}, err
+		}
+
+		rows, err := pr.queries.GetOrders(ctxWithTimeout, query.GetOrdersParams{
+			Limit:  int32(limit),
+			Offset: int32(offset),
+		})
+		if err != nil {
+			return result{}, err
+		}
+
+		orderUuids := make([]pgtype.UUID, len(rows))
+
+		for i, v := range rows {
+			orderUuids[i] = v.ID
+		}
+
+		items, err := pr.queries.GetOrderItems(ctxWithTimeout, orderUuids)
+		if err != nil {
+			return result{}, err
+		}
+
+		return result{rows: rows, items: items, count: count}, nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	orders := make([]order.Order, len(r.rows))
+
+	for i, v := range r.rows {
+		o, err := toOrder(v, r.items)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		orders[i] = o
+	}
+
+	return orders, int(r.count), nil
+}
+
+func (pr *PostgresRepository) UpdateOrderStatus(ctx context.Context, id string, current order.Status, next order.Status) error {
+	var orderUuid pgtype.UUID
+	if err := orderUuid.Scan(id); err != nil {
+		return order.ErrNotFound
+	}
+
+	_, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (struct{}, error) {
+		tx, err := pr.pool.Begin(ctxWithTimeout)
+		if err != nil {
+			return struct{}{}, err
+		}
+		defer tx.Rollback(ctxWithTimeout)
+		qtx := pr.queries.WithTx(tx)
+
+		rows, err := qtx.UpdateOrderStatus(ctxWithTimeout, query.UpdateOrderStatusParams{
+			ID:            orderUuid,
+			Status:        string(next),
+			CurrentStatus: string(current),
+		})
+		if err != nil {
+			return struct{}{}, err
+		}
+
+		// the order has been updated since it was read
+		if rows == 0 {
+			return struct{}{}, order.ErrInvalidTransition
+		}
+
+		items, err := qtx.GetOrderItems(ctxWithTimeout, []pgtype.UUID{orderUuid})
+		if err != nil {
+			return struct{}{}, err
+		}
+
+		if err := updateReservedStock(ctxWithTimeout, qtx, id, items, current, next); err != nil {
+			return struct{}{}, err
+		}
+
+		if err := tx.Commit(ctxWithTimeout); err != nil {
+			return struct{}{}, err
+		}
+
+		return struct{}{}, nil
+	})
+
+	return err
+}
+
+// createOrder snapshots the books into a new order and reserves their stock
+func createOrder(ctx context.Context, qtx *query.Queries, items []order.LineItem) (order.Order, error) {
+	o, err := qtx.CreateOrder(ctx)
+	if err != nil {
+		return order.Order{}, err
+	}
+
+	// lock the stock rows in the same order for every checkout to avoid deadlocks
+	items = slices.Clone(items)
+	slices.SortFunc(items, func(a, b order.LineItem) int {
+		return strings.Compare(a.BookId, b.BookId)
+	})
+
+	for _, v := range items {
+		var bookUuid pgtype.UUID
+		if err := bookUuid.Scan(v.BookId); err != nil {
+			return order.Order{}, book.ErrNotFound
+		}
+
+		rows, err := qtx.CreateOrderItem(ctx, query.CreateOrderItemParams{
+			OrderID:  o.ID,
+			BookID:   bookUuid,
+			Quantity: int32(v.Quantity),
+		})
+		if err != nil {
+			return order.Order{}, err
+		}
+
+		if rows == 0 {
+			return order.Order{}, book.ErrNotFound
+		}
+
+		_, err = qtx.ReserveStock(ctx, query.ReserveStockParams{
+			BookID:   bookUuid,
+			Quantity: int32(v.Quantity),
+		})
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				return order.Order{}, inventory.ErrInsufficientStock
+			}
+
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) {
+				switch pgErr.Code {
+				case pgerrcode.CheckViolation:
+					return order.Order{}, inventory.ErrInsufficientStock
+				}
+			}
+
+			return order.Order{}, err
+		}
+	}
+
+	return getOrderById(ctx, qtx, o.ID)
+}
+
+// updateReservedStock takes the reserved stock out of the quantity on hand once the order is shipped
+// and gives it back if the order is cancelled or refunded before that
+func updateReservedStock(ctx context.Context, qtx *query.Queries, orderId string, items []query.OrderItem, current order.Status, next order.Status) error {
+	switch {
+	case next == order.StatusShipped:
+		for _, v := range items {
+			// the book has been deleted
+			if !v.BookID.Valid {
+				continue
+			}
+
+			quantityOnHand, err := qtx.ConsumeStock(ctx, query.ConsumeStockParams{
+				BookID:   v.BookID,
+				Quantity: v.Quantity,
+			})
+			if err != nil {
+				return err
+			}
+
+			_, err = qtx.CreateStockAdjustment(ctx, query.CreateStockAdjustmentParams{
+				BookID:         v.BookID,
+				Quantity:       -v.Quantity,
+				Reason:         string(inventory.ReasonSale),
+				Note:           pgtype.Text{String: fmt.Sprintf("order %s", orderId), Valid: true},
+				QuantityOnHand: quantityOnHand,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	case (next == order.StatusCancelled || next == order.StatusRefunded) && (current == order.StatusPending || current == order.StatusPaid):
+		for _, v := range items {
+			if !v.BookID.Valid {
+				continue
+			}
+
+			err := qtx.ReleaseStock(ctx, query.ReleaseStockParams{
+				BookID:   v.BookID,
+				Quantity: v.Quantity,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func getOrderById(ctx context.Context, q *query.Queries, orderUuid pgtype.UUID) (order.Order, error) {
+	o, err := q.GetOrder(ctx, orderUuid)
+	if err != nil {
+		switch err {
+		case pgx.ErrNoRows:
+			return order.Order{}, order.ErrNotFound
+		default:
+			return order.Order{}, err
+		}
+	}
+
+	items, err := q.GetOrderItems(ctx, []pgtype.UUID{orderUuid})
+	if err != nil {
+		return order.Order{}, err
+	}
+
+	return toOrder(o, items)
+}
+
+// toOrder converts the row with the items that belong to it
+func toOrder(o query.Order, items []query.OrderItem) (order.Order, error) {
+	id, err := o.ID.Value()
+	if err != nil {
+		return order.Order{}, err
+	}
+
+	orderItems := make([]order.Item, 0)
+
+	for _, v := range items {
+		if v.OrderID != o.ID {
+			continue
+		}
+
+		var bookId string
+		if v.BookID.Valid {
+			value, err := v.BookID.Value()
+			if err != nil {
+				return order.Order{}, err
+			}
+
+			bookId = value.(string)
+		}
+
+		price, err := toMoney(v.Price, book.DefaultCurrency)
+		if err != nil {
+			return order.Order{}, err
+		}
+
+		orderItems = append(orderItems, order.Item{
+			BookId:   bookId,
+			Title:    v.Title,
+			Author:   v.Author,
+			Price:    price,
+			Quantity: int(v.Quantity),
+		})
+	}
+
+	return order.Order{
+		Id:        id.(string),
+		Status:    order.Status(o.Status),
+		Items:     orderItems,
+		CreatedAt: o.CreatedAt.Time,
+		UpdatedAt: o.UpdatedAt.Time,
+	}, nil
+}
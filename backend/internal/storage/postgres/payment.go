@@ -0,0 +1,141 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cativovo/bookstore/internal/order"
+	"github.com/cativovo/bookstore/internal/payment"
+	query "github.com/cativovo/bookstore/internal/storage/postgres/generated"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (pr *PostgresRepository) CreatePayment(ctx context.Context, p payment.Payment) (payment.Payment, error) {
+	var orderUuid pgtype.UUID
+	if err := orderUuid.Scan(p.OrderId); err != nil {
+		return payment.Payment{}, order.ErrNotFound
+	}
+
+	row, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (query.Payment, error) {
+		return pr.queries.CreatePayment(ctxWithTimeout, query.CreatePaymentParams{
+			OrderID:  orderUuid,
+			Status:   string(p.Status),
+			Amount:   p.Amount,
+			Currency: p.Currency,
+		})
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case pgerrcode.UniqueViolation:
+				return payment.Payment{}, payment.ErrAlreadyExists
+			}
+		}
+
+		return payment.Payment{}, err
+	}
+
+	return toPayment(row)
+}
+
+func (pr *PostgresRepository) AuthorizePayment(ctx context.Context, id string, providerPaymentId string) (payment.Payment, error) {
+	var paymentUuid pgtype.UUID
+	if err := paymentUuid.Scan(id); err != nil {
+		return payment.Payment{}, payment.ErrNotFound
+	}
+
+	return getPayment(ctx, func(ctxWithTimeout context.Context) (query.Payment, error) {
+		return pr.queries.AuthorizePayment(ctxWithTimeout, query.AuthorizePaymentParams{
+			ProviderPaymentID: pgtype.Text{String: providerPaymentId, Valid: true},
+			ID:                paymentUuid,
+		})
+	})
+}
+
+func (pr *PostgresRepository) UpdatePaymentStatus(ctx context.Context, id string, current payment.Status, next payment.Status) (payment.Payment, error) {
+	var paymentUuid pgtype.UUID
+	if err := paymentUuid.Scan(id); err != nil {
+		return payment.Payment{}, payment.ErrNotFound
+	}
+
+	row, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (query.Payment, error) {
+		return pr.queries.UpdatePaymentStatus(ctxWithTimeout, query.UpdatePaymentStatusParams{
+			Status:        string(next),
+			ID:            paymentUuid,
+			CurrentStatus: string(current),
+		})
+	})
+	if err != nil {
+		switch err {
+		// the payment has been updated since it was read
+		case pgx.ErrNoRows:
+			return payment.Payment{}, payment.ErrInvalidTransition
+		default:
+			return payment.Payment{}, err
+		}
+	}
+
+	return toPayment(row)
+}
+
+func (pr *PostgresRepository) GetPaymentByOrderId(ctx context.Context, orderId string, status payment.Status) (payment.Payment, error) {
+	var orderUuid pgtype.UUID
+	if err := orderUuid.Scan(orderId); err != nil {
+		return payment.Payment{}, payment.ErrNotFound
+	}
+
+	return getPayment(ctx, func(ctxWithTimeout context.Context) (query.Payment, error) {
+		return pr.queries.GetPaymentByOrderId(ctxWithTimeout, query.GetPaymentByOrderIdParams{
+			OrderID: orderUuid,
+			Status:  string(status),
+		})
+	})
+}
+
+func (pr *PostgresRepository) GetPaymentByProviderPaymentId(ctx context.Context, providerPaymentId string) (payment.Payment, error) {
+	return getPayment(ctx, func(ctxWithTimeout context.Context) (query.Payment, error) {
+		return pr.queries.GetPaymentByProviderPaymentId(ctxWithTimeout, pgtype.Text{String: providerPaymentId, Valid: true})
+	})
+}
+
+// getPayment runs the query that returns a single payment, no rows means the payment is not found
+func getPayment(ctx context.Context, fn func(ctxWithTimeout context.Context) (query.Payment, error)) (payment.Payment, error) {
+	row, err := withTimeout(ctx, fn)
+	if err != nil {
+		switch err {
+		case pgx.ErrNoRows:
+			return payment.Payment{}, payment.ErrNotFound
+		default:
+			return payment.Payment{}, err
+		}
+	}
+
+	return toPayment(row)
+}
+
+func toPayment(p query.Payment) (payment.Payment, error) {
+	id, err := p.ID.Value()
+	if err != nil {
+		return payment.Payment{}, err
+	}
+
+	orderId, err := p.OrderID.Value()
+	if err != nil {
+		return payment.Payment{}, err
+	}
+
+	return payment.Payment{
+		Id:                id.(string),
+		OrderId:           orderId.(string),
+		ProviderPaymentId: p.ProviderPaymentID.String,
+		Status:            payment.Status(p.Status),
+		Currency:          p.Currency,
+		Amount:            p.Amount,
+		CreatedAt:         p.CreatedAt.Time,
+		UpdatedAt:         p.UpdatedAt.Time,
+	}, nil
+}
@@ -2,14 +2,15 @@ package postgres
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"strconv"
+	"math/big"
 	"time"
 
 	"github.com/cativovo/bookstore/internal/book"
+	"github.com/cativovo/bookstore/internal/change"
 	query "github.com/cativovo/bookstore/internal/storage/postgres/generated"
+	"github.com/cativovo/bookstore/internal/webhook"
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -40,8 +41,19 @@ func (pr *PostgresRepository) CreateGenre(ctx context.Context, name string) erro
 		return err
 	}
 
-	_, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (pgtype.UUID, error) {
-		return pr.queries.CreateGenre(ctxWithTimeout, genreName)
+	_, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (struct{}, error) {
+		tx, err := pr.pool.Begin(ctxWithTimeout)
+		if err != nil {
+			return struct{}{}, err
+		}
+		defer tx.Rollback(ctxWithTimeout)
+		qtx := pr.queries.WithTx(tx)
+
+		if _, err := createGenre(ctxWithTimeout, qtx, genreName); err != nil {
+			return struct{}{}, err
+		}
+
+		return struct{}{}, tx.Commit(ctxWithTimeout)
 	})
 	if err != nil {
 		var pgErr *pgconn.PgError
@@ -64,18 +76,210 @@ func (pr *PostgresRepository) DeleteGenre(ctx context.Context, id string) error
 		return book.ErrNotFound
 	}
 
-	rows, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (int64, error) {
-		return pr.queries.DeleteGenre(ctxWithTimeout, uuid)
+	_, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (struct{}, error) {
+		tx, err := pr.pool.Begin(ctxWithTimeout)
+		if err != nil {
+			return struct{}{}, err
+		}
+		defer tx.Rollback(ctxWithTimeout)
+		qtx := pr.queries.WithTx(tx)
+
+		// the bookgenre of the genre are deleted on cascade
+		bookUuids, err := qtx.GetGenreBookIds(ctxWithTimeout, uuid)
+		if err != nil {
+			return struct{}{}, err
+		}
+
+		rows, err := qtx.DeleteGenre(ctxWithTimeout, uuid)
+		if err != nil {
+			return struct{}{}, err
+		}
+
+		if rows == 0 {
+			return struct{}{}, book.ErrNotFound
+		}
+
+		if err := recordChange(ctxWithTimeout, qtx, change.EntityGenre, change.ActionDelete, uuid, nil); err != nil {
+			return struct{}{}, err
+		}
+
+		if err := enqueueEvent(ctxWithTimeout, qtx, webhook.EventGenreDeleted, deletedEntity{Id: id}); err != nil {
+			return struct{}{}, err
+		}
+
+		if err := touchBooks(ctxWithTimeout, qtx, bookUuids); err != nil {
+			return struct{}{}, err
+		}
+
+		return struct{}{}, tx.Commit(ctxWithTimeout)
 	})
-	if err != nil {
-		return err
+
+	return err
+}
+
+func (pr *PostgresRepository) UpdateGenre(ctx context.Context, id string, name string) (book.Genre, error) {
+	var uuid pgtype.UUID
+	if err := uuid.Scan(id); err != nil {
+		return book.Genre{}, book.ErrNotFound
 	}
 
-	if rows == 0 {
-		return book.ErrNotFound
+	return withTimeout(ctx, func(ctxWithTimeout context.Context) (book.Genre, error) {
+		tx, err := pr.pool.Begin(ctxWithTimeout)
+		if err != nil {
+			return book.Genre{}, err
+		}
+		defer tx.Rollback(ctxWithTimeout)
+		qtx := pr.queries.WithTx(tx)
+
+		rows, err := qtx.UpdateGenre(ctxWithTimeout, query.UpdateGenreParams{
+			ID:   uuid,
+			Name: pgtype.Text{String: name, Valid: true},
+		})
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) {
+				switch pgErr.Code {
+				case pgerrcode.UniqueViolation:
+					return book.Genre{}, book.ErrAlreadyExists
+				}
+			}
+
+			return book.Genre{}, err
+		}
+
+		if rows == 0 {
+			return book.Genre{}, book.ErrNotFound
+		}
+
+		row, err := qtx.GetGenreById(ctxWithTimeout, uuid)
+		if err != nil {
+			return book.Genre{}, err
+		}
+
+		genre, err := toGenre(row.ID, row.Name, row.BookCount)
+		if err != nil {
+			return book.Genre{}, err
+		}
+
+		if err := recordChange(ctxWithTimeout, qtx, change.EntityGenre, change.ActionUpdate, uuid, genre); err != nil {
+			return book.Genre{}, err
+		}
+
+		if err := enqueueEvent(ctxWithTimeout, qtx, webhook.EventGenreUpdated, genre); err != nil {
+			return book.Genre{}, err
+		}
+
+		// the books show the new name of the genre
+		bookUuids, err := qtx.GetGenreBookIds(ctxWithTimeout, uuid)
+		if err != nil {
+			return book.Genre{}, err
+		}
+
+		if err := touchBooks(ctxWithTimeout, qtx, bookUuids); err != nil {
+			return book.Genre{}, err
+		}
+
+		if err := tx.Commit(ctxWithTimeout); err != nil {
+			return book.Genre{}, err
+		}
+
+		return genre, nil
+	})
+}
+
+func (pr *PostgresRepository) MergeGenres(ctx context.Context, sourceId string, targetId string) (book.Genre, error) {
+	var sourceUuid pgtype.UUID
+	if err := sourceUuid.Scan(sourceId); err != nil {
+		return book.Genre{}, book.ErrNotFound
 	}
 
-	return nil
+	var targetUuid pgtype.UUID
+	if err := targetUuid.Scan(targetId); err != nil {
+		return book.Genre{}, book.ErrInvalidGenre
+	}
+
+	// the ids are compared parsed, the same genre can be written in another case, deleting the source would delete the target
+	if sourceUuid == targetUuid {
+		return book.Genre{}, book.ErrMergeIntoItself
+	}
+
+	return withTimeout(ctx, func(ctxWithTimeout context.Context) (book.Genre, error) {
+		tx, err := pr.pool.Begin(ctxWithTimeout)
+		if err != nil {
+			return book.Genre{}, err
+		}
+		defer tx.Rollback(ctxWithTimeout)
+		qtx := pr.queries.WithTx(tx)
+
+		if _, err := qtx.GetGenreById(ctxWithTimeout, targetUuid); err != nil {
+			switch err {
+			case pgx.ErrNoRows:
+				return book.Genre{}, book.ErrInvalidGenre
+			default:
+				return book.Genre{}, err
+			}
+		}
+
+		// every book of the source genre changes, whether its row is moved or removed
+		bookUuids, err := qtx.GetGenreBookIds(ctxWithTimeout, sourceUuid)
+		if err != nil {
+			return book.Genre{}, err
+		}
+
+		err = qtx.MoveBookGenres(ctxWithTimeout, query.MoveBookGenresParams{
+			SourceID: sourceUuid,
+			TargetID: targetUuid,
+		})
+		if err != nil {
+			return book.Genre{}, err
+		}
+
+		// the remaining bookgenre of the source genre are deleted on cascade
+		rows, err := qtx.DeleteGenre(ctxWithTimeout, sourceUuid)
+		if err != nil {
+			return book.Genre{}, err
+		}
+
+		if rows == 0 {
+			return book.Genre{}, book.ErrNotFound
+		}
+
+		row, err := qtx.GetGenreById(ctxWithTimeout, targetUuid)
+		if err != nil {
+			return book.Genre{}, err
+		}
+
+		genre, err := toGenre(row.ID, row.Name, row.BookCount)
+		if err != nil {
+			return book.Genre{}, err
+		}
+
+		if err := recordChange(ctxWithTimeout, qtx, change.EntityGenre, change.ActionDelete, sourceUuid, nil); err != nil {
+			return book.Genre{}, err
+		}
+
+		if err := enqueueEvent(ctxWithTimeout, qtx, webhook.EventGenreDeleted, deletedEntity{Id: sourceId}); err != nil {
+			return book.Genre{}, err
+		}
+
+		if err := recordChange(ctxWithTimeout, qtx, change.EntityGenre, change.ActionUpdate, targetUuid, genre); err != nil {
+			return book.Genre{}, err
+		}
+
+		if err := enqueueEvent(ctxWithTimeout, qtx, webhook.EventGenreUpdated, genre); err != nil {
+			return book.Genre{}, err
+		}
+
+		if err := touchBooks(ctxWithTimeout, qtx, bookUuids); err != nil {
+			return book.Genre{}, err
+		}
+
+		if err := tx.Commit(ctxWithTimeout); err != nil {
+			return book.Genre{}, err
+		}
+
+		return genre, nil
+	})
 }
 
 func (pr *PostgresRepository) CreateBook(ctx context.Context, b book.Book) (book.Book, error) {
@@ -87,128 +291,363 @@ func (pr *PostgresRepository) CreateBook(ctx context.Context, b book.Book) (book
 		defer tx.Rollback(ctxWithTimeout)
 		qtx := pr.queries.WithTx(tx)
 
-		genreUuids := make([]pgtype.UUID, 0)
+		b, err = createBook(ctxWithTimeout, qtx, b, false)
+		if err != nil {
+			return book.Book{}, err
+		}
 
-		// check if genre exists in db
-		for _, v := range b.Genres {
-			name := pgtype.Text{String: v, Valid: true}
-			genre, err := qtx.GetGenreByName(ctxWithTimeout, name)
-			if err != nil {
-				switch err {
-				case pgx.ErrNoRows:
-					return book.Book{}, book.ErrNotFound
-				default:
+		if err := tx.Commit(ctxWithTimeout); err != nil {
+			return book.Book{}, err
+		}
+
+		return b, nil
+	})
+}
+
+func (pr *PostgresRepository) UpdateBook(ctx context.Context, id string, opts book.UpdateBookOptions) (book.Book, error) {
+	var uuid pgtype.UUID
+	if err := uuid.Scan(id); err != nil {
+		return book.Book{}, book.ErrNotFound
+	}
+
+	return withTimeout(ctx, func(ctxWithTimeout context.Context) (book.Book, error) {
+		tx, err := pr.pool.Begin(ctxWithTimeout)
+		if err != nil {
+			return book.Book{}, err
+		}
+		defer tx.Rollback(ctxWithTimeout)
+		qtx := pr.queries.WithTx(tx)
+
+		updateBookParams := query.UpdateBookParams{
+			ID:          uuid,
+			Title:       toText(opts.Title),
+			Author:      toText(opts.Author),
+			Description: toText(opts.Description),
+			CoverImage:  toText(opts.CoverImage),
+			Isbn13:      toText(opts.Isbn),
+			Publisher:   toText(opts.Publisher),
+			Edition:     toText(opts.Edition),
+			Language:    toText(opts.Language),
+		}
+
+		if opts.Price != nil {
+			updateBookParams.Price = toNumeric(*opts.Price)
+		}
+
+		// a zero date or page count is left null to clear it
+		if opts.PublicationDate != nil {
+			updateBookParams.SetPublicationDate = true
+			updateBookParams.PublicationDate = pgtype.Date{Time: opts.PublicationDate.Time, Valid: !opts.PublicationDate.IsZero()}
+		}
+
+		if opts.PageCount != nil {
+			updateBookParams.SetPageCount = true
+			updateBookParams.PageCount = pgtype.Int4{Int32: int32(*opts.PageCount), Valid: *opts.PageCount != 0}
+		}
+
+		if opts.Format != nil {
+			updateBookParams.Format = pgtype.Text{String: string(*opts.Format), Valid: true}
+		}
+
+		// the series and the position are left null to remove the book from its series
+		if opts.SeriesId != nil {
+			updateBookParams.SetSeries = true
+
+			if *opts.SeriesId != "" {
+				updateBookParams.SeriesID, _, err = getBookSeries(ctxWithTimeout, qtx, *opts.SeriesId)
+				if err != nil {
 					return book.Book{}, err
 				}
+
+				updateBookParams.SeriesPosition = toSeriesPositionNumeric(*opts.SeriesPosition)
 			}
+		}
 
-			genreUuids = append(genreUuids, genre.ID)
+		authors, err := bookAuthorsOptions(ctxWithTimeout, qtx, uuid, opts)
+		if err != nil {
+			return book.Book{}, err
 		}
 
-		// create book
-		description := pgtype.Text{String: b.Description, Valid: true}
-		coverImage := pgtype.Text{String: b.CoverImage, Valid: true}
+		// the byline follows the credits
+		if authors != nil {
+			authors, err = upsertAuthors(ctxWithTimeout, qtx, authors)
+			if err != nil {
+				return book.Book{}, err
+			}
 
-		var price pgtype.Numeric
-		if err := price.Scan(strconv.FormatFloat(b.Price, 'f', 2, 64)); err != nil {
-			return book.Book{}, err
+			updateBookParams.Author = pgtype.Text{String: book.Byline(authors), Valid: true}
 		}
 
-		createBookParams := query.CreateBookParams{
-			Title:       b.Title,
-			Author:      b.Author,
-			Description: description,
-			Price:       price,
-			CoverImage:  coverImage,
+		if _, err := qtx.UpdateBook(ctxWithTimeout, updateBookParams); err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) {
+				switch pgErr.Code {
+				case pgerrcode.UniqueViolation:
+					return book.Book{}, book.ErrAlreadyExists
+				}
+			}
+
+			switch err {
+			case pgx.ErrNoRows:
+				return book.Book{}, book.ErrNotFound
+			default:
+				return book.Book{}, err
+			}
 		}
-		bookUuid, err := qtx.CreateBook(ctxWithTimeout, createBookParams)
-		if err != nil {
-			return book.Book{}, err
+
+		if authors != nil {
+			if err := replaceBookAuthors(ctxWithTimeout, qtx, uuid, authors); err != nil {
+				return book.Book{}, err
+			}
 		}
 
-		// create bookgenre
-		for _, genreUuid := range genreUuids {
-			err := qtx.CreateBookGenre(ctxWithTimeout, query.CreateBookGenreParams{
-				BookID:  bookUuid,
-				GenreID: genreUuid,
-			})
+		// replace bookgenre
+		if opts.Genres != nil {
+			genreUuids, err := getGenreUuids(ctxWithTimeout, qtx, *opts.Genres)
 			if err != nil {
 				return book.Book{}, err
 			}
+
+			if err := qtx.DeleteBookGenres(ctxWithTimeout, uuid); err != nil {
+				return book.Book{}, err
+			}
+
+			if err := createBookGenres(ctxWithTimeout, qtx, uuid, genreUuids); err != nil {
+				return book.Book{}, err
+			}
 		}
 
-		if err := tx.Commit(ctxWithTimeout); err != nil {
+		row, err := qtx.GetBookById(ctxWithTimeout, query.GetBookByIdParams{
+			Currency: book.DefaultCurrency,
+			ID:       uuid,
+		})
+		if err != nil {
 			return book.Book{}, err
 		}
 
-		id, err := bookUuid.Value()
+		b, err := toBook(id, book.DefaultCurrency, row)
 		if err != nil {
 			return book.Book{}, err
 		}
 
-		b.Id = id.(string)
+		if err := recordChange(ctxWithTimeout, qtx, change.EntityBook, change.ActionUpdate, uuid, b); err != nil {
+			return book.Book{}, err
+		}
+
+		if err := enqueueEvent(ctxWithTimeout, qtx, webhook.EventBookUpdated, b); err != nil {
+			return book.Book{}, err
+		}
+
+		if opts.Price != nil {
+			if err := enqueueEvent(ctxWithTimeout, qtx, webhook.EventBookPriceChanged, b); err != nil {
+				return book.Book{}, err
+			}
+		}
+
+		if err := tx.Commit(ctxWithTimeout); err != nil {
+			return book.Book{}, err
+		}
 
 		return b, nil
 	})
 }
 
-func (pr *PostgresRepository) GetBooks(ctx context.Context, opts book.GetBooksOptions) ([]book.Book, int, error) {
-	genres := opts.Filter.Genres
-	if len(genres) == 0 {
-		genres = []string{"%%"}
+func (pr *PostgresRepository) DeleteBook(ctx context.Context, id string) error {
+	var uuid pgtype.UUID
+	if err := uuid.Scan(id); err != nil {
+		return book.ErrNotFound
 	}
 
-	row, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (query.GetBooksRow, error) {
-		return pr.queries.GetBooks(ctxWithTimeout, query.GetBooksParams{
-			Limit:         int32(opts.Limit),
-			Offset:        int32(opts.Offset),
-			Descending:    opts.Desc,
-			OrderBy:       opts.OrderBy,
-			KeywordAuthor: appendPatternWildcard(opts.Filter.Author),
-			KeywordTitle:  appendPatternWildcard(opts.Filter.Title),
-			Genres:        genres,
-		})
-	})
-	if err != nil {
-		return nil, 0, err
-	}
+	_, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (struct{}, error) {
+		tx, err := pr.pool.Begin(ctxWithTimeout)
+		if err != nil {
+			return struct{}{}, err
+		}
+		defer tx.Rollback(ctxWithTimeout)
+		qtx := pr.queries.WithTx(tx)
 
-	books := make([]book.Book, 0)
+		rows, err := qtx.DeleteBook(ctxWithTimeout, uuid)
+		if err != nil {
+			return struct{}{}, err
+		}
 
-	if len(row.Books) > 0 {
-		if err := json.Unmarshal(row.Books, &books); err != nil {
-			return nil, 0, err
+		if rows == 0 {
+			return struct{}{}, book.ErrNotFound
 		}
-	}
 
-	return books, int(row.Count), nil
+		if err := recordChange(ctxWithTimeout, qtx, change.EntityBook, change.ActionDelete, uuid, nil); err != nil {
+			return struct{}{}, err
+		}
+
+		if err := enqueueEvent(ctxWithTimeout, qtx, webhook.EventBookDeleted, deletedEntity{Id: id}); err != nil {
+			return struct{}{}, err
+		}
+
+		return struct{}{}, tx.Commit(ctxWithTimeout)
+	})
+
+	return err
 }
 
-func (pr *PostgresRepository) GetGenres(ctx context.Context) ([]string, error) {
-	genreRows, err := withTimeout(ctx, func(ctxWithTimeout context.Context) ([]pgtype.Text, error) {
+func (pr *PostgresRepository) GetGenres(ctx context.Context) ([]book.Genre, error) {
+	genreRows, err := withTimeout(ctx, func(ctxWithTimeout context.Context) ([]query.GetGenresRow, error) {
 		return pr.queries.GetGenres(ctxWithTimeout)
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	genres := make([]string, len(genreRows))
+	genres := make([]book.Genre, len(genreRows))
 
 	for i, v := range genreRows {
-		genres[i] = v.String
+		genre, err := toGenre(v.ID, v.Name, v.BookCount)
+		if err != nil {
+			return nil, err
+		}
+
+		genres[i] = genre
 	}
 
 	return genres, nil
 }
 
-func (pr *PostgresRepository) GetBookById(ctx context.Context, id string) (book.Book, error) {
+func (pr *PostgresRepository) SetBookPrice(ctx context.Context, id string, price book.Money) error {
+	var uuid pgtype.UUID
+	if err := uuid.Scan(id); err != nil {
+		return book.ErrNotFound
+	}
+
+	_, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (struct{}, error) {
+		tx, err := pr.pool.Begin(ctxWithTimeout)
+		if err != nil {
+			return struct{}{}, err
+		}
+		defer tx.Rollback(ctxWithTimeout)
+		qtx := pr.queries.WithTx(tx)
+
+		err = qtx.UpsertBookPrice(ctxWithTimeout, query.UpsertBookPriceParams{
+			BookID:   uuid,
+			Currency: price.Currency,
+			Price:    toNumeric(price),
+		})
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) {
+				switch pgErr.Code {
+				case pgerrcode.ForeignKeyViolation:
+					return struct{}{}, book.ErrNotFound
+				}
+			}
+
+			return struct{}{}, err
+		}
+
+		// the price is never in the default currency so there is no book.price_changed event
+		if err := touchBooks(ctxWithTimeout, qtx, []pgtype.UUID{uuid}); err != nil {
+			return struct{}{}, err
+		}
+
+		return struct{}{}, tx.Commit(ctxWithTimeout)
+	})
+
+	return err
+}
+
+func (pr *PostgresRepository) DeleteBookPrice(ctx context.Context, id string, currency string) error {
+	var uuid pgtype.UUID
+	if err := uuid.Scan(id); err != nil {
+		return book.ErrPriceNotFound
+	}
+
+	_, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (struct{}, error) {
+		tx, err := pr.pool.Begin(ctxWithTimeout)
+		if err != nil {
+			return struct{}{}, err
+		}
+		defer tx.Rollback(ctxWithTimeout)
+		qtx := pr.queries.WithTx(tx)
+
+		rows, err := qtx.DeleteBookPrice(ctxWithTimeout, query.DeleteBookPriceParams{
+			BookID:   uuid,
+			Currency: currency,
+		})
+		if err != nil {
+			return struct{}{}, err
+		}
+
+		if rows == 0 {
+			return struct{}{}, book.ErrPriceNotFound
+		}
+
+		if err := touchBooks(ctxWithTimeout, qtx, []pgtype.UUID{uuid}); err != nil {
+			return struct{}{}, err
+		}
+
+		return struct{}{}, tx.Commit(ctxWithTimeout)
+	})
+
+	return err
+}
+
+// touchBooks bumps the updated_at of books changed through another table, such as their genre, stock or rating,
+// then records the change of every book and enqueues its book.updated event so the change feed and the webhooks see the new book.
+// It has to use the transaction of the change.
+func touchBooks(ctx context.Context, qtx *query.Queries, bookUuids []pgtype.UUID) error {
+	if len(bookUuids) == 0 {
+		return nil
+	}
+
+	touched, err := qtx.TouchBooks(ctx, bookUuids)
+	if err != nil {
+		return err
+	}
+
+	for _, uuid := range touched {
+		row, err := qtx.GetBookById(ctx, query.GetBookByIdParams{
+			Currency: book.DefaultCurrency,
+			ID:       uuid,
+		})
+		if err != nil {
+			return err
+		}
+
+		id, err := uuid.Value()
+		if err != nil {
+			return err
+		}
+
+		b, err := toBook(id.(string), book.DefaultCurrency, row)
+		if err != nil {
+			return err
+		}
+
+		if err := recordChange(ctx, qtx, change.EntityBook, change.ActionUpdate, uuid, b); err != nil {
+			return err
+		}
+
+		if err := enqueueEvent(ctx, qtx, webhook.EventBookUpdated, b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (pr *PostgresRepository) GetBookById(ctx context.Context, id string, currency string) (book.Book, error) {
 	var uuid pgtype.UUID
 	if err := uuid.Scan(id); err != nil {
 		return book.Book{}, book.ErrNotFound
 	}
 
 	b, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (query.GetBookByIdRow, error) {
-		return pr.queries.GetBookById(ctxWithTimeout, uuid)
+		if err := checkCurrency(ctxWithTimeout, pr.queries, currency); err != nil {
+			return query.GetBookByIdRow{}, err
+		}
+
+		return pr.queries.GetBookById(ctxWithTimeout, query.GetBookByIdParams{
+			Currency: currency,
+			ID:       uuid,
+		})
 	})
 	if err != nil {
 		switch err {
@@ -219,11 +658,58 @@ func (pr *PostgresRepository) GetBookById(ctx context.Context, id string) (book.
 		}
 	}
 
-	priceFloatValue, err := b.Price.Float64Value()
+	return toBook(id, currency, b)
+}
+
+func (pr *PostgresRepository) GetBookByIsbn(ctx context.Context, isbn13 string, currency string) (book.Book, error) {
+	uuid, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (pgtype.UUID, error) {
+		return pr.queries.GetBookIdByIsbn(ctxWithTimeout, pgtype.Text{String: isbn13, Valid: true})
+	})
+	if err != nil {
+		switch err {
+		case pgx.ErrNoRows:
+			return book.Book{}, book.ErrNotFound
+		default:
+			return book.Book{}, err
+		}
+	}
+
+	id, err := uuid.Value()
+	if err != nil {
+		return book.Book{}, err
+	}
+
+	return pr.GetBookById(ctx, id.(string), currency)
+}
+
+func toBook(id string, currency string, b query.GetBookByIdRow) (book.Book, error) {
+	price, err := toMoney(b.Price, currency)
+	if err != nil {
+		return book.Book{}, err
+	}
+
+	rating, err := toRating(b.AverageRating)
+	if err != nil {
+		return book.Book{}, err
+	}
+
+	authors, err := toBookAuthors(b.Authors)
 	if err != nil {
 		return book.Book{}, err
 	}
 
+	series, err := toBookSeries(b.Series)
+	if err != nil {
+		return book.Book{}, err
+	}
+
+	seriesPosition, err := toSeriesPosition(b.SeriesPosition)
+	if err != nil {
+		return book.Book{}, err
+	}
+
+	isbn10, _ := book.ISBN10(b.Isbn13.String)
+
 	genresInterface := b.Genres.([]interface{})
 	genres := make([]string, len(genresInterface))
 
@@ -232,16 +718,326 @@ func (pr *PostgresRepository) GetBookById(ctx context.Context, id string) (book.
 	}
 
 	return book.Book{
-		Id:          id,
-		Author:      b.Author,
-		Title:       b.Title,
-		Price:       priceFloatValue.Float64,
-		CoverImage:  b.CoverImage.String,
-		Description: b.Description.String,
-		Genres:      genres,
+		Id:              id,
+		Author:          b.Author,
+		Authors:         authors,
+		Title:           b.Title,
+		Price:           price,
+		CoverImage:      book.CoverImage(b.CoverImage.String),
+		Description:     b.Description.String,
+		Genres:          genres,
+		QuantityOnHand:  int(b.QuantityOnHand),
+		Reserved:        int(b.Reserved),
+		CreatedAt:       b.CreatedAt.Time,
+		UpdatedAt:       b.UpdatedAt.Time,
+		AverageRating:   rating,
+		ReviewCount:     int(b.ReviewCount),
+		Isbn13:          b.Isbn13.String,
+		Isbn10:          isbn10,
+		Publisher:       b.Publisher.String,
+		PublicationDate: toDate(b.PublicationDate),
+		Edition:         b.Edition.String,
+		PageCount:       toPageCount(b.PageCount),
+		Language:        b.Language.String,
+		Format:          book.Format(b.Format.String),
+		Series:          series,
+		SeriesPosition:  seriesPosition,
 	}, nil
 }
 
+// setBookMetadata sets the bibliographic fields of the book that are not required, the empty ones are left null
+func setBookMetadata(params *query.CreateBookParams, b book.Book) {
+	params.Isbn13 = pgtype.Text{String: b.Isbn13, Valid: b.Isbn13 != ""}
+	params.Publisher = pgtype.Text{String: b.Publisher, Valid: b.Publisher != ""}
+	params.Edition = pgtype.Text{String: b.Edition, Valid: b.Edition != ""}
+	params.Language = pgtype.Text{String: b.Language, Valid: b.Language != ""}
+	params.Format = pgtype.Text{String: string(b.Format), Valid: b.Format != ""}
+
+	if b.PublicationDate != nil {
+		params.PublicationDate = pgtype.Date{Time: b.PublicationDate.Time, Valid: !b.PublicationDate.IsZero()}
+	}
+
+	if b.PageCount != nil {
+		params.PageCount = pgtype.Int4{Int32: int32(*b.PageCount), Valid: *b.PageCount != 0}
+	}
+}
+
+func toDate(d pgtype.Date) *book.Date {
+	if !d.Valid {
+		return nil
+	}
+
+	return &book.Date{Time: d.Time}
+}
+
+func toPageCount(n pgtype.Int4) *int {
+	if !n.Valid {
+		return nil
+	}
+
+	pageCount := int(n.Int32)
+	return &pageCount
+}
+
+func toGenre(uuid pgtype.UUID, name pgtype.Text, bookCount int64) (book.Genre, error) {
+	id, err := uuid.Value()
+	if err != nil {
+		return book.Genre{}, err
+	}
+
+	return book.Genre{
+		Id:        id.(string),
+		Name:      name.String,
+		BookCount: int(bookCount),
+	}, nil
+}
+
+// createBook creates the book in the transaction of qtx, the genres the catalog doesn't have are created if createGenres is true
+func createBook(ctx context.Context, qtx *query.Queries, b book.Book, createGenres bool) (book.Book, error) {
+	var genreUuids []pgtype.UUID
+	var err error
+	if createGenres {
+		genreUuids, err = getOrCreateGenreUuids(ctx, qtx, b.Genres)
+	} else {
+		genreUuids, err = getGenreUuids(ctx, qtx, b.Genres)
+	}
+	if err != nil {
+		return book.Book{}, err
+	}
+
+	authors := b.Authors
+	if len(authors) == 0 {
+		authors = []book.BookAuthor{{Name: b.Author, Role: book.AuthorRoleAuthor}}
+	}
+
+	b.Authors, err = upsertAuthors(ctx, qtx, authors)
+	if err != nil {
+		return book.Book{}, err
+	}
+	b.Author = book.Byline(b.Authors)
+
+	// create book
+	description := pgtype.Text{String: b.Description, Valid: true}
+	coverImage := pgtype.Text{String: string(b.CoverImage), Valid: true}
+
+	createBookParams := query.CreateBookParams{
+		Title:       b.Title,
+		Author:      b.Author,
+		Description: description,
+		Price:       toNumeric(b.Price),
+		CoverImage:  coverImage,
+	}
+	setBookMetadata(&createBookParams, b)
+
+	if b.Series != nil {
+		seriesUuid, series, err := getBookSeries(ctx, qtx, b.Series.Id)
+		if err != nil {
+			return book.Book{}, err
+		}
+
+		b.Series = &series
+		createBookParams.SeriesID = seriesUuid
+		createBookParams.SeriesPosition = toSeriesPositionNumeric(*b.SeriesPosition)
+	}
+
+	row, err := qtx.CreateBook(ctx, createBookParams)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case pgerrcode.UniqueViolation:
+				return book.Book{}, book.ErrAlreadyExists
+			}
+		}
+
+		return book.Book{}, err
+	}
+	bookUuid := row.ID
+
+	if err := qtx.CreateStock(ctx, bookUuid); err != nil {
+		return book.Book{}, err
+	}
+
+	// create bookgenre
+	if err := createBookGenres(ctx, qtx, bookUuid, genreUuids); err != nil {
+		return book.Book{}, err
+	}
+
+	if err := replaceBookAuthors(ctx, qtx, bookUuid, b.Authors); err != nil {
+		return book.Book{}, err
+	}
+
+	id, err := bookUuid.Value()
+	if err != nil {
+		return book.Book{}, err
+	}
+
+	b.Id = id.(string)
+	b.Isbn10, _ = book.ISBN10(b.Isbn13)
+	b.CreatedAt = row.CreatedAt.Time
+	b.UpdatedAt = row.UpdatedAt.Time
+
+	if err := recordChange(ctx, qtx, change.EntityBook, change.ActionCreate, bookUuid, b); err != nil {
+		return book.Book{}, err
+	}
+
+	if err := enqueueEvent(ctx, qtx, webhook.EventBookCreated, b); err != nil {
+		return book.Book{}, err
+	}
+
+	return b, nil
+}
+
+// getGenreUuids checks if every genre exists in db and returns their ids
+func getGenreUuids(ctx context.Context, qtx *query.Queries, names []string) ([]pgtype.UUID, error) {
+	genreUuids := make([]pgtype.UUID, 0, len(names))
+
+	for _, v := range names {
+		name := pgtype.Text{String: v, Valid: true}
+		genre, err := qtx.GetGenreByName(ctx, name)
+		if err != nil {
+			switch err {
+			case pgx.ErrNoRows:
+				return nil, book.ErrInvalidGenre
+			default:
+				return nil, err
+			}
+		}
+
+		genreUuids = append(genreUuids, genre.ID)
+	}
+
+	return genreUuids, nil
+}
+
+// getOrCreateGenreUuids is getGenreUuids creating the genres the catalog doesn't have
+func getOrCreateGenreUuids(ctx context.Context, qtx *query.Queries, names []string) ([]pgtype.UUID, error) {
+	genreUuids := make([]pgtype.UUID, 0, len(names))
+
+	for _, v := range names {
+		name := pgtype.Text{String: v, Valid: true}
+		genre, err := qtx.GetGenreByName(ctx, name)
+		if err == nil {
+			genreUuids = append(genreUuids, genre.ID)
+			continue
+		}
+		if err != pgx.ErrNoRows {
+			return nil, err
+		}
+
+		genreUuid, err := createGenre(ctx, qtx, name)
+		if err != nil {
+			return nil, err
+		}
+
+		genreUuids = append(genreUuids, genreUuid)
+	}
+
+	return genreUuids, nil
+}
+
+func createGenre(ctx context.Context, qtx *query.Queries, name pgtype.Text) (pgtype.UUID, error) {
+	genreUuid, err := qtx.CreateGenre(ctx, name)
+	if err != nil {
+		return pgtype.UUID{}, err
+	}
+
+	genre, err := toGenre(genreUuid, name, 0)
+	if err != nil {
+		return pgtype.UUID{}, err
+	}
+
+	if err := recordChange(ctx, qtx, change.EntityGenre, change.ActionCreate, genreUuid, genre); err != nil {
+		return pgtype.UUID{}, err
+	}
+
+	if err := enqueueEvent(ctx, qtx, webhook.EventGenreCreated, genre); err != nil {
+		return pgtype.UUID{}, err
+	}
+
+	return genreUuid, nil
+}
+
+func createBookGenres(ctx context.Context, qtx *query.Queries, bookUuid pgtype.UUID, genreUuids []pgtype.UUID) error {
+	for _, genreUuid := range genreUuids {
+		err := qtx.CreateBookGenre(ctx, query.CreateBookGenreParams{
+			BookID:  bookUuid,
+			GenreID: genreUuid,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func toText(s *string) pgtype.Text {
+	if s == nil {
+		return pgtype.Text{}
+	}
+
+	return pgtype.Text{String: *s, Valid: true}
+}
+
+func toBool(b *bool) pgtype.Bool {
+	if b == nil {
+		return pgtype.Bool{}
+	}
+
+	return pgtype.Bool{Bool: *b, Valid: true}
+}
+
+// toNumeric converts the money to a NUMERIC with the scale of its currency so no digit is lost
+func toNumeric(m book.Money) pgtype.Numeric {
+	return pgtype.Numeric{
+		Int:   big.NewInt(m.Amount),
+		Exp:   -int32(book.MinorUnits(m.Currency)),
+		Valid: true,
+	}
+}
+
+// toMoney converts a NUMERIC to money in the currency, it fails rather than rounding if the NUMERIC has more digits than the currency
+func toMoney(n pgtype.Numeric, currency string) (book.Money, error) {
+	if !n.Valid || n.NaN || n.InfinityModifier != pgtype.Finite {
+		return book.Money{}, fmt.Errorf("%w: %v", book.ErrInvalidMoney, n)
+	}
+
+	exp := -int32(book.MinorUnits(currency))
+	amount := new(big.Int).Set(n.Int)
+
+	if n.Exp > exp {
+		amount.Mul(amount, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n.Exp-exp)), nil))
+	} else if n.Exp < exp {
+		var remainder big.Int
+		amount.QuoRem(amount, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp-n.Exp)), nil), &remainder)
+		// the price has more digits than the currency allows, rounding it would change the price
+		if remainder.Sign() != 0 {
+			return book.Money{}, fmt.Errorf("%w: %v has more digits than %s allows", book.ErrInvalidMoney, n.Int, currency)
+		}
+	}
+
+	if !amount.IsInt64() {
+		return book.Money{}, fmt.Errorf("%w: %v is out of range", book.ErrInvalidMoney, amount)
+	}
+
+	return book.NewMoney(amount.Int64(), currency), nil
+}
+
+// toRating returns nil if the book has no review
+func toRating(n pgtype.Numeric) (*float64, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+
+	f, err := n.Float64Value()
+	if err != nil {
+		return nil, err
+	}
+
+	return &f.Float64, nil
+}
+
 func appendPatternWildcard(s string) string {
 	return fmt.Sprintf("%%%s%%", s)
 }
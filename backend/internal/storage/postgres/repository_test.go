@@ -0,0 +1,152 @@
+package postgres
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/cativovo/bookstore/internal/book"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToMoney(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          pgtype.Numeric
+		currency       string
+		expectedOutput book.Money
+		expectedErr    error
+	}{
+		{
+			name:           "Same digits",
+			input:          pgtype.Numeric{Int: big.NewInt(1010), Exp: -2, Valid: true},
+			currency:       "USD",
+			expectedOutput: book.NewMoney(1010, "USD"),
+		},
+		{
+			name:           "Fewer digits",
+			input:          pgtype.Numeric{Int: big.NewInt(101), Exp: -1, Valid: true},
+			currency:       "USD",
+			expectedOutput: book.NewMoney(1010, "USD"),
+		},
+		{
+			name:           "Positive exponent",
+			input:          pgtype.Numeric{Int: big.NewInt(15), Exp: 2, Valid: true},
+			currency:       "JPY",
+			expectedOutput: book.NewMoney(1500, "JPY"),
+		},
+		{
+			name:           "More digits that are zeros",
+			input:          pgtype.Numeric{Int: big.NewInt(101000), Exp: -4, Valid: true},
+			currency:       "USD",
+			expectedOutput: book.NewMoney(1010, "USD"),
+		},
+		{
+			name:        "More digits than the currency",
+			input:       pgtype.Numeric{Int: big.NewInt(10005), Exp: -3, Valid: true},
+			currency:    "USD",
+			expectedErr: book.ErrInvalidMoney,
+		},
+		{
+			name:        "Negative with more digits than the currency",
+			input:       pgtype.Numeric{Int: big.NewInt(-10005), Exp: -3, Valid: true},
+			currency:    "USD",
+			expectedErr: book.ErrInvalidMoney,
+		},
+		{
+			name:           "Three digit currency",
+			input:          pgtype.Numeric{Int: big.NewInt(10005), Exp: -3, Valid: true},
+			currency:       "BHD",
+			expectedOutput: book.NewMoney(10005, "BHD"),
+		},
+		{
+			name:        "Out of range",
+			input:       pgtype.Numeric{Int: big.NewInt(math.MaxInt64), Exp: 0, Valid: true},
+			currency:    "USD",
+			expectedErr: book.ErrInvalidMoney,
+		},
+		{
+			name:        "NaN",
+			input:       pgtype.Numeric{NaN: true, Valid: true},
+			currency:    "USD",
+			expectedErr: book.ErrInvalidMoney,
+		},
+		{
+			name:        "Null",
+			input:       pgtype.Numeric{},
+			currency:    "USD",
+			expectedErr: book.ErrInvalidMoney,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m, err := toMoney(test.input, test.currency)
+			if test.expectedErr != nil {
+				assert.ErrorIs(t, err, test.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedOutput, m)
+
+			// converting it back gives the same amount
+			back, err := toMoney(toNumeric(m), test.currency)
+			assert.NoError(t, err)
+			assert.Equal(t, m, back)
+		})
+	}
+}
+
+// the cases fail before the database is queried
+func TestMergeGenresInvalidIds(t *testing.T) {
+	tests := []struct {
+		name        string
+		sourceId    string
+		targetId    string
+		expectedErr error
+	}{
+		{
+			name:        "Same genre",
+			sourceId:    "55976dc9-74d3-4982-a2ec-966b9f803d97",
+			targetId:    "55976dc9-74d3-4982-a2ec-966b9f803d97",
+			expectedErr: book.ErrMergeIntoItself,
+		},
+		{
+			name:        "Same genre in another case",
+			sourceId:    "55976DC9-74D3-4982-A2EC-966B9F803D97",
+			targetId:    "55976dc9-74d3-4982-a2ec-966b9f803d97",
+			expectedErr: book.ErrMergeIntoItself,
+		},
+		{
+			name:        "Same genre without hyphens",
+			sourceId:    "55976dc9-74d3-4982-a2ec-966b9f803d97",
+			targetId:    "55976dc974d34982a2ec966b9f803d97",
+			expectedErr: book.ErrMergeIntoItself,
+		},
+		{
+			name:        "Invalid source",
+			sourceId:    "1234",
+			targetId:    "55976dc9-74d3-4982-a2ec-966b9f803d97",
+			expectedErr: book.ErrNotFound,
+		},
+		{
+			name:        "Invalid target",
+			sourceId:    "55976dc9-74d3-4982-a2ec-966b9f803d97",
+			targetId:    "1234",
+			expectedErr: book.ErrInvalidGenre,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pr := &PostgresRepository{}
+
+			_, err := pr.MergeGenres(context.Background(), test.sourceId, test.targetId)
+
+			assert.ErrorIs(t, err, test.expectedErr)
+		})
+	}
+}
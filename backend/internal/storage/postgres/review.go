@@ -0,0 +1,282 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cativovo/bookstore/internal/book"
+	"github.com/cativovo/bookstore/internal/review"
+	query "github.com/cativovo/bookstore/internal/storage/postgres/generated"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (pr *PostgresRepository) CreateReview(ctx context.Context, r review.Review) (review.Review, error) {
+	var bookUuid, userUuid pgtype.UUID
+	if err := bookUuid.Scan(r.BookId); err != nil {
+		return review.Review{}, book.ErrNotFound
+	}
+	if err := userUuid.Scan(r.UserId); err != nil {
+		return review.Review{}, err
+	}
+
+	return withTimeout(ctx, func(ctxWithTimeout context.Context) (review.Review, error) {
+		tx, err := pr.pool.Begin(ctxWithTimeout)
+		if err != nil {
+			return review.Review{}, err
+		}
+		defer tx.Rollback(ctxWithTimeout)
+		qtx := pr.queries.WithTx(tx)
+
+		row, err := qtx.CreateReview(ctxWithTimeout, query.CreateReviewParams{
+			BookID: bookUuid,
+			UserID: userUuid,
+			Rating: int16(r.Rating),
+			Body:   r.Body,
+		})
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) {
+				switch pgErr.Code {
+				case pgerrcode.UniqueViolation:
+					return review.Review{}, review.ErrAlreadyExists
+				case pgerrcode.ForeignKeyViolation:
+					return review.Review{}, book.ErrNotFound
+				}
+			}
+
+			return review.Review{}, err
+		}
+
+		err = qtx.UpdateBookRating(ctxWithTimeout, query.UpdateBookRatingParams{
+			RatingDelta: int32(r.Rating),
+			CountDelta:  1,
+			ID:          bookUuid,
+		})
+		if err != nil {
+			return review.Review{}, err
+		}
+
+		if err := touchBooks(ctxWithTimeout, qtx, []pgtype.UUID{bookUuid}); err != nil {
+			return review.Review{}, err
+		}
+
+		if err := tx.Commit(ctxWithTimeout); err != nil {
+			return review.Review{}, err
+		}
+
+		id, err := row.ID.Value()
+		if err != nil {
+			return review.Review{}, err
+		}
+
+		r.Id = id.(string)
+		r.CreatedAt = row.CreatedAt.Time
+		r.UpdatedAt = row.UpdatedAt.Time
+
+		return r, nil
+	})
+}
+
+func (pr *PostgresRepository) GetReviews(ctx context.Context, bookId string, limit int, offset int) ([]review.Review, int, error) {
+	var bookUuid pgtype.UUID
+	if err := bookUuid.Scan(bookId); err != nil {
+		return nil, 0, book.ErrNotFound
+	}
+
+	type result struct {
+		rows  []query.GetReviewsRow
+		count int32
+	}
+
+	r, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (result, error) {
+		// the count is kept on the book, it doesn't need to count the reviews
+		count, err := pr.queries.GetBookReviewCount(ctxWithTimeout, bookUuid)
+		if err != nil {
+			return result{}, err
+		}
+
+		rows, err := pr.queries.GetReviews(ctxWithTimeout, query.GetReviewsParams{
+			BookID: bookUuid,
+			Limit:  int32(limit),
+			Offset: int32(offset),
+		})
+		if err != nil {
+			return result{}, err
+		}
+
+		return result{rows: rows, count: count}, nil
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, 0, book.ErrNotFound
+		}
+
+		return nil, 0, err
+	}
+
+	reviews := make([]review.Review, len(r.rows))
+
+	for i, v := range r.rows {
+		rv, err := toReview(query.Review{
+			ID:        v.ID,
+			BookID:    v.BookID,
+			UserID:    v.UserID,
+			Rating:    v.Rating,
+			Body:      v.Body,
+			CreatedAt: v.CreatedAt,
+			UpdatedAt: v.UpdatedAt,
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+
+		rv.UserName = v.UserName
+		reviews[i] = rv
+	}
+
+	return reviews, int(r.count), nil
+}
+
+func (pr *PostgresRepository) UpdateReview(ctx context.Context, userId string, id string, opts review.UpdateReviewOptions) (review.Review, error) {
+	var uuid, userUuid pgtype.UUID
+	if err := uuid.Scan(id); err != nil {
+		return review.Review{}, review.ErrNotFound
+	}
+	if err := userUuid.Scan(userId); err != nil {
+		return review.Review{}, review.ErrNotFound
+	}
+
+	return withTimeout(ctx, func(ctxWithTimeout context.Context) (review.Review, error) {
+		tx, err := pr.pool.Begin(ctxWithTimeout)
+		if err != nil {
+			return review.Review{}, err
+		}
+		defer tx.Rollback(ctxWithTimeout)
+		qtx := pr.queries.WithTx(tx)
+
+		// the old rating is locked until the rating of the book is updated
+		old, err := qtx.GetReviewForUpdate(ctxWithTimeout, query.GetReviewForUpdateParams{
+			ID:     uuid,
+			UserID: userUuid,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return review.Review{}, review.ErrNotFound
+			}
+
+			return review.Review{}, err
+		}
+
+		params := query.UpdateReviewParams{
+			Body: toText(opts.Body),
+			ID:   uuid,
+		}
+
+		if opts.Rating != nil {
+			params.Rating = pgtype.Int2{Int16: int16(*opts.Rating), Valid: true}
+		}
+
+		row, err := qtx.UpdateReview(ctxWithTimeout, params)
+		if err != nil {
+			return review.Review{}, err
+		}
+
+		if row.Rating != old.Rating {
+			err := qtx.UpdateBookRating(ctxWithTimeout, query.UpdateBookRatingParams{
+				RatingDelta: int32(row.Rating - old.Rating),
+				ID:          row.BookID,
+			})
+			if err != nil {
+				return review.Review{}, err
+			}
+
+			if err := touchBooks(ctxWithTimeout, qtx, []pgtype.UUID{row.BookID}); err != nil {
+				return review.Review{}, err
+			}
+		}
+
+		if err := tx.Commit(ctxWithTimeout); err != nil {
+			return review.Review{}, err
+		}
+
+		return toReview(row)
+	})
+}
+
+func (pr *PostgresRepository) DeleteReview(ctx context.Context, userId string, id string) error {
+	var uuid, userUuid pgtype.UUID
+	if err := uuid.Scan(id); err != nil {
+		return review.ErrNotFound
+	}
+	if err := userUuid.Scan(userId); err != nil {
+		return review.ErrNotFound
+	}
+
+	_, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (struct{}, error) {
+		tx, err := pr.pool.Begin(ctxWithTimeout)
+		if err != nil {
+			return struct{}{}, err
+		}
+		defer tx.Rollback(ctxWithTimeout)
+		qtx := pr.queries.WithTx(tx)
+
+		row, err := qtx.DeleteReview(ctxWithTimeout, query.DeleteReviewParams{
+			ID:     uuid,
+			UserID: userUuid,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return struct{}{}, review.ErrNotFound
+			}
+
+			return struct{}{}, err
+		}
+
+		err = qtx.UpdateBookRating(ctxWithTimeout, query.UpdateBookRatingParams{
+			RatingDelta: -int32(row.Rating),
+			CountDelta:  -1,
+			ID:          row.BookID,
+		})
+		if err != nil {
+			return struct{}{}, err
+		}
+
+		if err := touchBooks(ctxWithTimeout, qtx, []pgtype.UUID{row.BookID}); err != nil {
+			return struct{}{}, err
+		}
+
+		return struct{}{}, tx.Commit(ctxWithTimeout)
+	})
+
+	return err
+}
+
+func toReview(r query.Review) (review.Review, error) {
+	id, err := r.ID.Value()
+	if err != nil {
+		return review.Review{}, err
+	}
+
+	bookId, err := r.BookID.Value()
+	if err != nil {
+		return review.Review{}, err
+	}
+
+	userId, err := r.UserID.Value()
+	if err != nil {
+		return review.Review{}, err
+	}
+
+	return review.Review{
+		CreatedAt: r.CreatedAt.Time,
+		UpdatedAt: r.UpdatedAt.Time,
+		Id:        id.(string),
+		BookId:    bookId.(string),
+		UserId:    userId.(string),
+		Rating:    int(r.Rating),
+		Body:      r.Body,
+	}, nil
+}
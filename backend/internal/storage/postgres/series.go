@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+
+	"github.com/cativovo/bookstore/internal/book"
+	query "github.com/cativovo/bookstore/internal/storage/postgres/generated"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (pr *PostgresRepository) CreateSeries(ctx context.Context, s book.Series) (book.Series, error) {
+	row, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (query.CreateSeriesRow, error) {
+		return pr.queries.CreateSeries(ctxWithTimeout, query.CreateSeriesParams{
+			Name:        s.Name,
+			Description: pgtype.Text{String: s.Description, Valid: s.Description != ""},
+		})
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case pgerrcode.UniqueViolation:
+				return book.Series{}, book.ErrAlreadyExists
+			}
+		}
+
+		return book.Series{}, err
+	}
+
+	id, err := row.ID.Value()
+	if err != nil {
+		return book.Series{}, err
+	}
+
+	s.Id = id.(string)
+	s.CreatedAt = row.CreatedAt.Time
+
+	return s, nil
+}
+
+func (pr *PostgresRepository) GetSeriesById(ctx context.Context, id string) (book.Series, error) {
+	var uuid pgtype.UUID
+	if err := uuid.Scan(id); err != nil {
+		return book.Series{}, book.ErrSeriesNotFound
+	}
+
+	row, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (query.GetSeriesByIdRow, error) {
+		return pr.queries.GetSeriesById(ctxWithTimeout, uuid)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return book.Series{}, book.ErrSeriesNotFound
+		}
+
+		return book.Series{}, err
+	}
+
+	return book.Series{
+		CreatedAt:   row.CreatedAt.Time,
+		Id:          id,
+		Name:        row.Name,
+		Description: row.Description.String,
+		BookCount:   int(row.BookCount),
+	}, nil
+}
+
+// getBookSeries returns the series a book is put in, it returns book.ErrSeriesNotFound if the series doesn't exist
+func getBookSeries(ctx context.Context, qtx *query.Queries, id string) (pgtype.UUID, book.BookSeries, error) {
+	var uuid pgtype.UUID
+	if err := uuid.Scan(id); err != nil {
+		return pgtype.UUID{}, book.BookSeries{}, book.ErrSeriesNotFound
+	}
+
+	row, err := qtx.GetSeriesById(ctx, uuid)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return pgtype.UUID{}, book.BookSeries{}, book.ErrSeriesNotFound
+		}
+
+		return pgtype.UUID{}, book.BookSeries{}, err
+	}
+
+	return uuid, book.BookSeries{Id: id, Name: row.Name}, nil
+}
+
+// toSeriesPositionNumeric converts a position book.ValidSeriesPosition accepts, the rounding only drops the error of the float
+func toSeriesPositionNumeric(position float64) pgtype.Numeric {
+	return pgtype.Numeric{
+		Int:   big.NewInt(int64(math.Round(position * 100))),
+		Exp:   -2,
+		Valid: true,
+	}
+}
+
+// toSeriesPosition returns nil if the book is not part of a series
+func toSeriesPosition(n pgtype.Numeric) (*float64, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+
+	f, err := n.Float64Value()
+	if err != nil {
+		return nil, err
+	}
+
+	return &f.Float64, nil
+}
+
+// toBookSeries decodes the series built by the queries of the books, it returns nil if the book is not part of a series
+func toBookSeries(b []byte) (*book.BookSeries, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+
+	var s *book.BookSeries
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
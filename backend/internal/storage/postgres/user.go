@@ -0,0 +1,329 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	query "github.com/cativovo/bookstore/internal/storage/postgres/generated"
+	"github.com/cativovo/bookstore/internal/user"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (pr *PostgresRepository) CreateUser(ctx context.Context, u user.User) (user.User, error) {
+	row, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (query.CreateUserRow, error) {
+		return pr.queries.CreateUser(ctxWithTimeout, query.CreateUserParams{
+			Email:        u.Email,
+			Name:         u.Name,
+			PasswordHash: u.PasswordHash,
+			Role:         string(u.Role),
+		})
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return user.User{}, user.ErrAlreadyExists
+		}
+
+		return user.User{}, err
+	}
+
+	id, err := row.ID.Value()
+	if err != nil {
+		return user.User{}, err
+	}
+
+	u.Id = id.(string)
+	u.CreatedAt = row.CreatedAt.Time
+	u.PasswordHash = ""
+
+	return u, nil
+}
+
+func (pr *PostgresRepository) GetUserByEmail(ctx context.Context, email string) (user.User, error) {
+	row, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (query.User, error) {
+		return pr.queries.GetUserByEmail(ctxWithTimeout, email)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return user.User{}, user.ErrNotFound
+		}
+
+		return user.User{}, err
+	}
+
+	u, err := toUser(row)
+	if err != nil {
+		return user.User{}, err
+	}
+
+	u.PasswordHash = row.PasswordHash
+
+	return u, nil
+}
+
+func (pr *PostgresRepository) GetUserById(ctx context.Context, id string) (user.User, error) {
+	var uuid pgtype.UUID
+	if err := uuid.Scan(id); err != nil {
+		return user.User{}, user.ErrNotFound
+	}
+
+	row, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (query.User, error) {
+		return pr.queries.GetUserById(ctxWithTimeout, uuid)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return user.User{}, user.ErrNotFound
+		}
+
+		return user.User{}, err
+	}
+
+	return toUser(row)
+}
+
+func (pr *PostgresRepository) UpdateUserRole(ctx context.Context, id string, role user.Role) (user.User, error) {
+	var uuid pgtype.UUID
+	if err := uuid.Scan(id); err != nil {
+		return user.User{}, user.ErrNotFound
+	}
+
+	row, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (query.User, error) {
+		return pr.queries.UpdateUserRole(ctxWithTimeout, query.UpdateUserRoleParams{
+			ID:   uuid,
+			Role: string(role),
+		})
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return user.User{}, user.ErrNotFound
+		}
+
+		return user.User{}, err
+	}
+
+	return toUser(row)
+}
+
+func (pr *PostgresRepository) CreateRefreshToken(ctx context.Context, userId string, tokenHash string, expiresAt time.Time) error {
+	var uuid pgtype.UUID
+	if err := uuid.Scan(userId); err != nil {
+		return user.ErrNotFound
+	}
+
+	_, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (struct{}, error) {
+		return struct{}{}, pr.queries.CreateRefreshToken(ctxWithTimeout, query.CreateRefreshTokenParams{
+			TokenHash: tokenHash,
+			UserID:    uuid,
+			ExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
+		})
+	})
+
+	return err
+}
+
+func (pr *PostgresRepository) RotateRefreshToken(ctx context.Context, tokenHash string, newTokenHash string, expiresAt time.Time) (string, error) {
+	return withTimeout(ctx, func(ctxWithTimeout context.Context) (string, error) {
+		tx, err := pr.pool.Begin(ctxWithTimeout)
+		if err != nil {
+			return "", err
+		}
+		defer tx.Rollback(ctxWithTimeout)
+		qtx := pr.queries.WithTx(tx)
+
+		userUuid, err := qtx.RevokeRefreshToken(ctxWithTimeout, tokenHash)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return "", user.ErrInvalidToken
+			}
+
+			return "", err
+		}
+
+		err = qtx.CreateRefreshToken(ctxWithTimeout, query.CreateRefreshTokenParams{
+			TokenHash: newTokenHash,
+			UserID:    userUuid,
+			ExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
+		})
+		if err != nil {
+			return "", err
+		}
+
+		if err := tx.Commit(ctxWithTimeout); err != nil {
+			return "", err
+		}
+
+		userId, err := userUuid.Value()
+		if err != nil {
+			return "", err
+		}
+
+		return userId.(string), nil
+	})
+}
+
+func (pr *PostgresRepository) RevokeRefreshToken(ctx context.Context, tokenHash string) error {
+	_, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (pgtype.UUID, error) {
+		return pr.queries.RevokeRefreshToken(ctxWithTimeout, tokenHash)
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return user.ErrInvalidToken
+	}
+
+	return err
+}
+
+func toUser(row query.User) (user.User, error) {
+	id, err := row.ID.Value()
+	if err != nil {
+		return user.User{}, err
+	}
+
+	return user.User{
+		CreatedAt: row.CreatedAt.Time,
+		Id:        id.(string),
+		Email:     row.Email,
+		Name:      row.Name,
+		Role:      user.Role(row.Role),
+	}, nil
+}
+
+func (pr *PostgresRepository) CreateApiKey(ctx context.Context, userId string, k user.ApiKey, keyHash string) (user.ApiKey, error) {
+	var userUuid pgtype.UUID
+	if err := userUuid.Scan(userId); err != nil {
+		return user.ApiKey{}, user.ErrNotFound
+	}
+
+	row, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (query.CreateApiKeyRow, error) {
+		return pr.queries.CreateApiKey(ctxWithTimeout, query.CreateApiKeyParams{
+			UserID:  userUuid,
+			Name:    k.Name,
+			Prefix:  k.Prefix,
+			KeyHash: keyHash,
+			Scopes:  fromPermissions(k.Scopes),
+		})
+	})
+	if err != nil {
+		return user.ApiKey{}, err
+	}
+
+	id, err := row.ID.Value()
+	if err != nil {
+		return user.ApiKey{}, err
+	}
+
+	k.Id = id.(string)
+	k.CreatedAt = row.CreatedAt.Time
+
+	return k, nil
+}
+
+func (pr *PostgresRepository) GetApiKeys(ctx context.Context, userId string) ([]user.ApiKey, error) {
+	var userUuid pgtype.UUID
+	if err := userUuid.Scan(userId); err != nil {
+		return []user.ApiKey{}, nil
+	}
+
+	rows, err := withTimeout(ctx, func(ctxWithTimeout context.Context) ([]query.ApiKey, error) {
+		return pr.queries.GetApiKeys(ctxWithTimeout, userUuid)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]user.ApiKey, len(rows))
+
+	for i, v := range rows {
+		id, err := v.ID.Value()
+		if err != nil {
+			return nil, err
+		}
+
+		keys[i] = user.ApiKey{
+			CreatedAt: v.CreatedAt.Time,
+			Id:        id.(string),
+			Name:      v.Name,
+			Prefix:    v.Prefix,
+			Scopes:    toPermissions(v.Scopes),
+		}
+
+		if v.LastUsedAt.Valid {
+			keys[i].LastUsedAt = &v.LastUsedAt.Time
+		}
+	}
+
+	return keys, nil
+}
+
+func (pr *PostgresRepository) RevokeApiKey(ctx context.Context, userId string, id string) error {
+	var userUuid, uuid pgtype.UUID
+	if err := userUuid.Scan(userId); err != nil {
+		return user.ErrNotFound
+	}
+	if err := uuid.Scan(id); err != nil {
+		return user.ErrNotFound
+	}
+
+	rows, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (int64, error) {
+		return pr.queries.RevokeApiKey(ctxWithTimeout, query.RevokeApiKeyParams{
+			ID:     uuid,
+			UserID: userUuid,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return user.ErrNotFound
+	}
+
+	return nil
+}
+
+func (pr *PostgresRepository) UseApiKey(ctx context.Context, keyHash string) (user.User, error) {
+	row, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (query.UseApiKeyRow, error) {
+		return pr.queries.UseApiKey(ctxWithTimeout, keyHash)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return user.User{}, user.ErrInvalidToken
+		}
+
+		return user.User{}, err
+	}
+
+	id, err := row.ID.Value()
+	if err != nil {
+		return user.User{}, err
+	}
+
+	return user.User{
+		Id:     id.(string),
+		Email:  row.Email,
+		Name:   row.Name,
+		Role:   user.Role(row.Role),
+		Scopes: toPermissions(row.Scopes),
+	}, nil
+}
+
+func fromPermissions(permissions []user.Permission) []string {
+	s := make([]string, len(permissions))
+	for i, v := range permissions {
+		s[i] = string(v)
+	}
+
+	return s
+}
+
+func toPermissions(s []string) []user.Permission {
+	permissions := make([]user.Permission, len(s))
+	for i, v := range s {
+		permissions[i] = user.Permission(v)
+	}
+
+	return permissions
+}
@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	query "github.com/cativovo/bookstore/internal/storage/postgres/generated"
+	"github.com/cativovo/bookstore/internal/webhook"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (pr *PostgresRepository) CreateWebhook(ctx context.Context, url string, eventTypes []webhook.EventType, secret string) (webhook.Webhook, error) {
+	types := make([]string, len(eventTypes))
+	for i, v := range eventTypes {
+		types[i] = string(v)
+	}
+
+	row, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (query.CreateWebhookRow, error) {
+		return pr.queries.CreateWebhook(ctxWithTimeout, query.CreateWebhookParams{
+			Url:        url,
+			EventTypes: types,
+			Secret:     secret,
+		})
+	})
+	if err != nil {
+		return webhook.Webhook{}, err
+	}
+
+	id, err := row.ID.Value()
+	if err != nil {
+		return webhook.Webhook{}, err
+	}
+
+	return webhook.Webhook{
+		CreatedAt:  row.CreatedAt.Time,
+		Id:         id.(string),
+		Url:        url,
+		EventTypes: eventTypes,
+	}, nil
+}
+
+func (pr *PostgresRepository) DeleteWebhook(ctx context.Context, id string) error {
+	var uuid pgtype.UUID
+	if err := uuid.Scan(id); err != nil {
+		return webhook.ErrNotFound
+	}
+
+	rows, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (int64, error) {
+		return pr.queries.DeleteWebhook(ctxWithTimeout, uuid)
+	})
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return webhook.ErrNotFound
+	}
+
+	return nil
+}
+
+func (pr *PostgresRepository) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]webhook.Delivery, error) {
+	rows, err := withTimeout(ctx, func(ctxWithTimeout context.Context) ([]query.ClaimOutboxEventsRow, error) {
+		return pr.queries.ClaimOutboxEvents(ctxWithTimeout, query.ClaimOutboxEventsParams{
+			LeaseSeconds: int32(lease / time.Second),
+			MaxEvents:    int32(limit),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]webhook.Delivery, len(rows))
+
+	for i, v := range rows {
+		eventId, err := v.EventID.Value()
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries[i] = webhook.Delivery{
+			Id: v.ID,
+			Event: webhook.Event{
+				CreatedAt: v.CreatedAt.Time,
+				Id:        eventId.(string),
+				Type:      webhook.EventType(v.EventType),
+				Data:      v.Payload,
+			},
+			Url:      v.Url,
+			Secret:   v.Secret,
+			Attempts: int(v.Attempts),
+		}
+	}
+
+	return deliveries, nil
+}
+
+func (pr *PostgresRepository) CompleteDelivery(ctx context.Context, id int64) error {
+	_, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (struct{}, error) {
+		return struct{}{}, pr.queries.DeliverOutboxEvent(ctxWithTimeout, id)
+	})
+
+	return err
+}
+
+func (pr *PostgresRepository) RetryDelivery(ctx context.Context, id int64, delay time.Duration, reason string) error {
+	_, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (struct{}, error) {
+		return struct{}{}, pr.queries.RetryOutboxEvent(ctxWithTimeout, query.RetryOutboxEventParams{
+			LastError: pgtype.Text{String: reason, Valid: true},
+			DelayMs:   delay.Milliseconds(),
+			ID:        id,
+		})
+	})
+
+	return err
+}
+
+func (pr *PostgresRepository) DeadLetterDelivery(ctx context.Context, id int64, reason string) error {
+	_, err := withTimeout(ctx, func(ctxWithTimeout context.Context) (struct{}, error) {
+		return struct{}{}, pr.queries.DeadLetterOutboxEvent(ctxWithTimeout, query.DeadLetterOutboxEventParams{
+			LastError: pgtype.Text{String: reason, Valid: true},
+			ID:        id,
+		})
+	})
+
+	return err
+}
+
+// enqueueEvent adds the event to the outbox of the subscribed webhooks, it has to use the transaction of the change.
+// data is encoded to json, a deleted entity is sent as deletedEntity.
+func enqueueEvent(ctx context.Context, qtx *query.Queries, eventType webhook.EventType, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return qtx.CreateOutboxEvent(ctx, query.CreateOutboxEventParams{
+		EventType: string(eventType),
+		Payload:   payload,
+	})
+}
+
+// deletedEntity is the data of the events of a delete
+type deletedEntity struct {
+	Id string `json:"id"`
+}
@@ -0,0 +1,83 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// ErrScopeNotGranted is returned when an api key is created with a scope the role of its user doesn't grant
+var ErrScopeNotGranted = errors.New("scope not granted")
+
+// apiKeyPrefix starts every api key so a leaked key can be recognized, e.g. by a secret scanner
+const apiKeyPrefix = "bk_"
+
+// ApiKey authenticates a machine client as its user, limited to the scopes of the key.
+// The key is sent as "Authorization: ApiKey <key>".
+type ApiKey struct {
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	Id         string     `json:"id"`
+	Name       string     `json:"name"`
+	// Prefix is the start of the key to tell the keys apart, the whole key can't be read again after it is created
+	Prefix string       `json:"prefix"`
+	Scopes []Permission `json:"scopes"`
+	// Key is only set when the key is created
+	Key string `json:"key,omitempty"`
+}
+
+// CreateApiKey issues a key for the user, the scopes have to be granted by the role of the user.
+func (us *UserService) CreateApiKey(ctx context.Context, u User, name string, scopes []Permission) (ApiKey, error) {
+	for _, scope := range scopes {
+		if !u.HasPermission(scope) {
+			return ApiKey{}, ErrScopeNotGranted
+		}
+	}
+
+	key, prefix, err := newApiKey()
+	if err != nil {
+		return ApiKey{}, err
+	}
+
+	k, err := us.repository.CreateApiKey(ctx, u.Id, ApiKey{
+		Name:   name,
+		Prefix: prefix,
+		Scopes: scopes,
+	}, hashToken(key))
+	if err != nil {
+		return ApiKey{}, err
+	}
+
+	k.Key = key
+
+	return k, nil
+}
+
+func (us *UserService) GetApiKeys(ctx context.Context, userId string) ([]ApiKey, error) {
+	return us.repository.GetApiKeys(ctx, userId)
+}
+
+// RevokeApiKey revokes a key of the user, it returns ErrNotFound if the user has no such key.
+func (us *UserService) RevokeApiKey(ctx context.Context, userId string, id string) error {
+	return us.repository.RevokeApiKey(ctx, userId, id)
+}
+
+// AuthenticateApiKey returns the user of the key with the scopes of the key, it returns ErrInvalidToken if the key is unknown or revoked.
+func (us *UserService) AuthenticateApiKey(ctx context.Context, key string) (User, error) {
+	return us.repository.UseApiKey(ctx, hashToken(key))
+}
+
+// newApiKey returns a random key and its prefix, e.g. bk_1a2b3c4d_<random>
+func newApiKey() (string, string, error) {
+	b := make([]byte, 36)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	prefix := apiKeyPrefix + hex.EncodeToString(b[:4])
+
+	return prefix + "_" + base64.RawURLEncoding.EncodeToString(b[4:]), prefix, nil
+}
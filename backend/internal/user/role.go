@@ -0,0 +1,44 @@
+package user
+
+import "slices"
+
+type Role string
+
+const (
+	RoleCustomer Role = "customer"
+	RoleStaff    Role = "staff"
+	RoleAdmin    Role = "admin"
+)
+
+type Permission string
+
+const (
+	// PermissionCatalogWrite creates and updates the books and genres
+	PermissionCatalogWrite Permission = "catalog:write"
+	// PermissionCatalogAdmin deletes genres and manages the settings of the catalog like the exchange rates and webhooks
+	PermissionCatalogAdmin Permission = "catalog:admin"
+	// PermissionOrdersManage lists the orders of every customer, changes their status and refunds them
+	PermissionOrdersManage Permission = "orders:manage"
+	// PermissionUsersAdmin changes the roles of the users
+	PermissionUsersAdmin Permission = "users:admin"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleCustomer: {},
+	RoleStaff:    {PermissionCatalogWrite, PermissionOrdersManage},
+	RoleAdmin:    {PermissionCatalogWrite, PermissionCatalogAdmin, PermissionOrdersManage, PermissionUsersAdmin},
+}
+
+// Permissions returns the permissions of the role, an unknown role has none.
+func (r Role) Permissions() []Permission {
+	return rolePermissions[r]
+}
+
+// HasPermission reports whether the role of the user grants the permission, with an api key the permission also has to be a scope of the key.
+func (u User) HasPermission(p Permission) bool {
+	if u.Scopes != nil && !slices.Contains(u.Scopes, p) {
+		return false
+	}
+
+	return slices.Contains(u.Role.Permissions(), p)
+}
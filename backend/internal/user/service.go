@@ -0,0 +1,226 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrNotFound           = errors.New("not found")
+	ErrAlreadyExists      = errors.New("already exists")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrInvalidToken       = errors.New("invalid token")
+	// ErrPasswordTooLong is returned when the password is longer than the 72 bytes bcrypt hashes
+	ErrPasswordTooLong = errors.New("password too long")
+)
+
+const (
+	AccessTokenTTL  = time.Minute * 15
+	RefreshTokenTTL = time.Hour * 24 * 30
+	issuer          = "bookstore"
+)
+
+type UserRepository interface {
+	// CreateUser returns ErrAlreadyExists if the email is used by another user.
+	CreateUser(ctx context.Context, u User) (User, error)
+	// GetUserByEmail returns the user with its PasswordHash.
+	GetUserByEmail(ctx context.Context, email string) (User, error)
+	GetUserById(ctx context.Context, id string) (User, error)
+	UpdateUserRole(ctx context.Context, id string, role Role) (User, error)
+	// the refresh tokens are stored by their hash so a leak of the table can't be used to log in
+	CreateRefreshToken(ctx context.Context, userId string, tokenHash string, expiresAt time.Time) error
+	// RotateRefreshToken revokes the token and creates the new one for the same user, it returns the id of the user.
+	// It returns ErrInvalidToken if the token is unknown, expired or revoked.
+	RotateRefreshToken(ctx context.Context, tokenHash string, newTokenHash string, expiresAt time.Time) (string, error)
+	// RevokeRefreshToken returns ErrInvalidToken if the token is unknown, expired or already revoked.
+	RevokeRefreshToken(ctx context.Context, tokenHash string) error
+	// the api keys are stored by their hash like the refresh tokens
+	CreateApiKey(ctx context.Context, userId string, k ApiKey, keyHash string) (ApiKey, error)
+	// GetApiKeys returns the keys of the user that are not revoked, the most recent first.
+	GetApiKeys(ctx context.Context, userId string) ([]ApiKey, error)
+	RevokeApiKey(ctx context.Context, userId string, id string) error
+	// UseApiKey returns the user of the key with the scopes of the key and records that the key was used.
+	// It returns ErrInvalidToken if the key is unknown or revoked.
+	UseApiKey(ctx context.Context, keyHash string) (User, error)
+}
+
+type UserService struct {
+	repository UserRepository
+	signingKey []byte
+	// dummyHash is compared to the password of an unknown email so a login takes the same time whether the email exists or not
+	dummyHash []byte
+}
+
+// accessClaims are the claims of the access tokens, the subject is the id of the user
+type accessClaims struct {
+	Email string `json:"email"`
+	Name  string `json:"name"`
+	Role  Role   `json:"role"`
+	jwt.RegisteredClaims
+}
+
+func NewUserService(r UserRepository, signingKey string) *UserService {
+	dummyHash, err := bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+	if err != nil {
+		panic(err)
+	}
+
+	return &UserService{
+		repository: r,
+		signingKey: []byte(signingKey),
+		dummyHash:  dummyHash,
+	}
+}
+
+// Register creates a customer, the other roles are given by an admin with SetRole.
+func (us *UserService) Register(ctx context.Context, email string, password string, name string) (User, error) {
+	return us.CreateUser(ctx, email, password, name, RoleCustomer)
+}
+
+func (us *UserService) CreateUser(ctx context.Context, email string, password string, name string, role Role) (User, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+			return User{}, ErrPasswordTooLong
+		}
+
+		return User{}, err
+	}
+
+	return us.repository.CreateUser(ctx, User{
+		Email:        strings.ToLower(email),
+		Name:         name,
+		Role:         role,
+		PasswordHash: string(hash),
+	})
+}
+
+// Login returns ErrInvalidCredentials if the email is unknown or the password is wrong, the caller can't tell which one.
+func (us *UserService) Login(ctx context.Context, email string, password string) (Tokens, error) {
+	u, err := us.repository.GetUserByEmail(ctx, strings.ToLower(email))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			bcrypt.CompareHashAndPassword(us.dummyHash, []byte(password))
+			return Tokens{}, ErrInvalidCredentials
+		}
+
+		return Tokens{}, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)); err != nil {
+		return Tokens{}, ErrInvalidCredentials
+	}
+
+	refreshToken, refreshTokenHash, err := newRefreshToken()
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	if err := us.repository.CreateRefreshToken(ctx, u.Id, refreshTokenHash, time.Now().Add(RefreshTokenTTL)); err != nil {
+		return Tokens{}, err
+	}
+
+	return us.newTokens(u, refreshToken)
+}
+
+// Refresh exchanges the refresh token for new tokens, the refresh token can't be used again.
+func (us *UserService) Refresh(ctx context.Context, refreshToken string) (Tokens, error) {
+	newToken, newTokenHash, err := newRefreshToken()
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	userId, err := us.repository.RotateRefreshToken(ctx, hashToken(refreshToken), newTokenHash, time.Now().Add(RefreshTokenTTL))
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	u, err := us.repository.GetUserById(ctx, userId)
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	return us.newTokens(u, newToken)
+}
+
+// SetRole changes the role of the user, the access tokens already issued keep the previous role until they expire.
+func (us *UserService) SetRole(ctx context.Context, id string, role Role) (User, error) {
+	return us.repository.UpdateUserRole(ctx, id, role)
+}
+
+// Logout revokes the refresh token, the access tokens stay valid until they expire.
+func (us *UserService) Logout(ctx context.Context, refreshToken string) error {
+	return us.repository.RevokeRefreshToken(ctx, hashToken(refreshToken))
+}
+
+// Authenticate returns the user of the access token, it returns ErrInvalidToken if the token is malformed, expired or not signed by this service.
+func (us *UserService) Authenticate(accessToken string) (User, error) {
+	var claims accessClaims
+
+	_, err := jwt.ParseWithClaims(accessToken, &claims, func(t *jwt.Token) (any, error) {
+		return us.signingKey, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithIssuer(issuer), jwt.WithExpirationRequired())
+	if err != nil {
+		return User{}, ErrInvalidToken
+	}
+
+	return User{
+		Id:    claims.Subject,
+		Email: claims.Email,
+		Name:  claims.Name,
+		Role:  claims.Role,
+	}, nil
+}
+
+func (us *UserService) newTokens(u User, refreshToken string) (Tokens, error) {
+	now := time.Now()
+
+	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims{
+		Email: u.Email,
+		Name:  u.Name,
+		Role:  u.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Subject:   u.Id,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL)),
+		},
+	}).SignedString(us.signingKey)
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	return Tokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(AccessTokenTTL / time.Second),
+	}, nil
+}
+
+// newRefreshToken returns a random refresh token and its hash
+func newRefreshToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	return token, hashToken(token), nil
+}
+
+// hashToken returns the hash the refresh tokens and api keys are stored by
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
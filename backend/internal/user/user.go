@@ -0,0 +1,41 @@
+package user
+
+import (
+	"context"
+	"time"
+)
+
+type User struct {
+	CreatedAt time.Time `json:"created_at"`
+	Id        string    `json:"id"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	Role      Role      `json:"role"`
+	// Scopes limits the permissions of the role when the user is authenticated with an api key, nil is every permission of the role
+	Scopes []Permission `json:"scopes,omitempty"`
+	// PasswordHash is only set by the repository for the login
+	PasswordHash string `json:"-"`
+}
+
+// Tokens are returned by a login or a refresh.
+// The access token is a JWT sent as "Authorization: Bearer <token>", the refresh token gets new tokens once it expires.
+type Tokens struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	// ExpiresIn is the number of seconds the access token is valid for
+	ExpiresIn int `json:"expires_in"`
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of the context with the user of the request.
+func NewContext(ctx context.Context, u User) context.Context {
+	return context.WithValue(ctx, contextKey{}, u)
+}
+
+// FromContext returns the user of the request, false if the request is anonymous.
+func FromContext(ctx context.Context) (User, bool) {
+	u, ok := ctx.Value(contextKey{}).(User)
+	return u, ok
+}
@@ -0,0 +1,199 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// headers of a delivery, the receivers verify the signature with Sign
+const (
+	EventIdHeader   = "X-Webhook-Id"
+	TimestampHeader = "X-Webhook-Timestamp"
+	SignatureHeader = "X-Webhook-Signature"
+)
+
+const (
+	// DefaultMaxAttempts is the number of failed attempts before a delivery is dead-lettered when the dispatcher isn't configured with another one
+	DefaultMaxAttempts = 8
+	// DefaultBackoff is the delay after the first failed attempt, it doubles after each attempt
+	DefaultBackoff = time.Second * 30
+)
+
+const (
+	batchSize = 50
+	// the deliveries of a batch are sent by at most workers requests at the same time
+	workers       = 10
+	clientTimeout = time.Second * 10
+	pollInterval  = time.Second * 5
+	// the lease outlives a batch whose every delivery times out, with a minute for the outbox,
+	// so a delivery isn't sent by two dispatchers at the same time
+	lease      = (batchSize+workers-1)/workers*clientTimeout + time.Minute
+	maxBackoff = time.Hour * 6
+)
+
+type OutboxRepository interface {
+	// ClaimDeliveries returns at most limit due deliveries, the other dispatchers don't get them until the lease ends.
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]Delivery, error)
+	CompleteDelivery(ctx context.Context, id int64) error
+	// RetryDelivery records the failed attempt, the delivery is due again after the delay.
+	RetryDelivery(ctx context.Context, id int64, delay time.Duration, reason string) error
+	// DeadLetterDelivery records the failed attempt, the delivery is never attempted again.
+	DeadLetterDelivery(ctx context.Context, id int64, reason string) error
+}
+
+// Dispatcher delivers the events of the outbox to the webhooks.
+// A failed delivery is retried with an exponential backoff until it failed maxAttempts times, it is then dead-lettered.
+type Dispatcher struct {
+	repository  OutboxRepository
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+}
+
+func NewDispatcher(r OutboxRepository, maxAttempts int, backoff time.Duration) *Dispatcher {
+	return &Dispatcher{
+		repository:  r,
+		client:      &http.Client{Timeout: clientTimeout},
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+	}
+}
+
+// Run dispatches the due deliveries until the context is done, the outbox is polled again as long as full batches are claimed.
+func (d *Dispatcher) Run(ctx context.Context) {
+	for {
+		n, err := d.Dispatch(ctx)
+		if err != nil {
+			log.Println(err)
+		}
+
+		if err == nil && n == batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// Dispatch attempts a batch of due deliveries concurrently and returns the number of deliveries claimed.
+// The error is only about the outbox, a failed delivery is retried or dead-lettered.
+func (d *Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	deliveries, err := d.repository.ClaimDeliveries(ctx, batchSize, lease)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var errs []error
+	sem := make(chan struct{}, workers)
+
+	for _, v := range deliveries {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(dl Delivery) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if err := d.deliver(ctx, dl); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}(v)
+	}
+
+	wg.Wait()
+
+	return len(deliveries), errors.Join(errs...)
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, dl Delivery) error {
+	err := d.send(ctx, dl)
+	if err == nil {
+		return d.repository.CompleteDelivery(ctx, dl.Id)
+	}
+
+	// the delivery is claimed again when the lease ends
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	attempts := dl.Attempts + 1
+	if attempts >= d.maxAttempts {
+		return d.repository.DeadLetterDelivery(ctx, dl.Id, err.Error())
+	}
+
+	return d.repository.RetryDelivery(ctx, dl.Id, d.backoffDelay(attempts), err.Error())
+}
+
+// backoffDelay returns the delay after the failed attempts, the delay doubles after each attempt up to maxBackoff
+func (d *Dispatcher) backoffDelay(attempts int) time.Duration {
+	delay := d.backoff
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxBackoff)
+}
+
+func (d *Dispatcher) send(ctx context.Context, dl Delivery) error {
+	body, err := json.Marshal(dl.Event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dl.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventIdHeader, dl.Event.Id)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(dl.Secret, timestamp, body))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	// drain the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(res.Body, 1<<16))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+// Sign returns the signature of a delivery, it is the hex HMAC-SHA256 of "<timestamp>.<body>" with the secret of the webhook.
+// The receivers compare it to the X-Webhook-Signature header and check the timestamp to reject forged or replayed deliveries.
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
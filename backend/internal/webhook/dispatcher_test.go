@@ -0,0 +1,195 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockOutboxRepository) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]Delivery, error) {
+	args := m.Called(ctx, limit, lease)
+	return args.Get(0).([]Delivery), args.Error(1)
+}
+
+func (m *MockOutboxRepository) CompleteDelivery(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) RetryDelivery(ctx context.Context, id int64, delay time.Duration, reason string) error {
+	args := m.Called(ctx, id, delay, reason)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) DeadLetterDelivery(ctx context.Context, id int64, reason string) error {
+	args := m.Called(ctx, id, reason)
+	return args.Error(0)
+}
+
+const testSecret = "whsec_0123456789abcdef"
+
+func TestDispatch(t *testing.T) {
+	event := Event{
+		CreatedAt: time.Date(2024, time.April, 30, 10, 22, 15, 0, time.UTC),
+		Id:        "b0a3c1f4-2d5e-4f6a-8b7c-9d0e1f2a3b4c",
+		Type:      EventBookCreated,
+		Data:      json.RawMessage(`{"id":"7e1f9a2b-3c4d-4e5f-a6b7-c8d9e0f1a2b3","title":"The Stranger"}`),
+	}
+
+	tests := []struct {
+		name           string
+		attempts       int
+		statusCode     int
+		unreachable    bool
+		expectedMethod string
+		expectedArgs   []any
+	}{
+		{
+			name:           "Success",
+			statusCode:     http.StatusNoContent,
+			expectedMethod: "CompleteDelivery",
+			expectedArgs:   []any{int64(1)},
+		},
+		{
+			name:           "Retry",
+			statusCode:     http.StatusInternalServerError,
+			expectedMethod: "RetryDelivery",
+			expectedArgs:   []any{int64(1), time.Second, "unexpected status code 500"},
+		},
+		{
+			name:           "Exponential backoff",
+			attempts:       3,
+			statusCode:     http.StatusBadGateway,
+			expectedMethod: "RetryDelivery",
+			expectedArgs:   []any{int64(1), time.Second * 8, "unexpected status code 502"},
+		},
+		{
+			name:           "Unreachable",
+			unreachable:    true,
+			expectedMethod: "RetryDelivery",
+			expectedArgs:   []any{int64(1), time.Second, mock.AnythingOfType("string")},
+		},
+		{
+			name:           "Dead letter",
+			attempts:       4,
+			statusCode:     http.StatusGone,
+			expectedMethod: "DeadLetterDelivery",
+			expectedArgs:   []any{int64(1), "unexpected status code 410"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var received Event
+
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Error(err)
+				}
+
+				assert.Equal(t, Sign(testSecret, r.Header.Get(TimestampHeader), body), r.Header.Get(SignatureHeader))
+				assert.Equal(t, event.Id, r.Header.Get(EventIdHeader))
+				if err := json.Unmarshal(body, &received); err != nil {
+					t.Error(err)
+				}
+
+				w.WriteHeader(test.statusCode)
+			}))
+			defer receiver.Close()
+
+			url := receiver.URL
+			if test.unreachable {
+				receiver.Close()
+			}
+
+			ctx := context.Background()
+			mockRepository := new(MockOutboxRepository)
+			mockRepository.On("ClaimDeliveries", ctx, batchSize, lease).Return([]Delivery{
+				{Id: 1, Event: event, Url: url, Secret: testSecret, Attempts: test.attempts},
+			}, nil)
+			mockRepository.On(test.expectedMethod, append([]any{ctx}, test.expectedArgs...)...).Return(nil)
+			d := NewDispatcher(mockRepository, 5, time.Second)
+
+			n, err := d.Dispatch(ctx)
+
+			assert.NoError(t, err)
+			assert.Equal(t, 1, n)
+			if !test.unreachable {
+				assert.Equal(t, event, received)
+			}
+			mockRepository.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDispatchConcurrently(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	// every request waits for the next one so the deliveries overlap if they are sent concurrently
+	release := make(chan struct{})
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		full := inFlight == workers
+		mu.Unlock()
+
+		if full {
+			close(release)
+		}
+
+		select {
+		case <-release:
+		case <-time.After(time.Second):
+		}
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	deliveries := make([]Delivery, workers*2)
+	for i := range deliveries {
+		deliveries[i] = Delivery{Id: int64(i + 1), Event: Event{Id: strconv.Itoa(i + 1), Type: EventBookCreated}, Url: receiver.URL, Secret: testSecret}
+	}
+
+	ctx := context.Background()
+	mockRepository := new(MockOutboxRepository)
+	mockRepository.On("ClaimDeliveries", ctx, batchSize, lease).Return(deliveries, nil)
+	mockRepository.On("CompleteDelivery", ctx, mock.AnythingOfType("int64")).Return(nil)
+	d := NewDispatcher(mockRepository, 5, time.Second)
+
+	n, err := d.Dispatch(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, len(deliveries), n)
+	assert.Equal(t, workers, maxInFlight)
+	mockRepository.AssertNumberOfCalls(t, "CompleteDelivery", len(deliveries))
+}
+
+func TestBackoffDelay(t *testing.T) {
+	d := NewDispatcher(nil, 20, time.Minute)
+
+	assert.Equal(t, time.Minute, d.backoffDelay(1))
+	assert.Equal(t, time.Minute*2, d.backoffDelay(2))
+	assert.Equal(t, time.Minute*32, d.backoffDelay(6))
+	assert.Equal(t, maxBackoff, d.backoffDelay(19))
+}
@@ -0,0 +1,32 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrNotFound = errors.New("not found")
+
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, url string, eventTypes []EventType, secret string) (Webhook, error)
+	DeleteWebhook(ctx context.Context, id string) error
+}
+
+type WebhookService struct {
+	repository WebhookRepository
+}
+
+func NewWebhookService(r WebhookRepository) *WebhookService {
+	return &WebhookService{
+		repository: r,
+	}
+}
+
+// CreateWebhook subscribes the url to the event types, the deliveries are signed with the secret.
+func (ws *WebhookService) CreateWebhook(ctx context.Context, url string, eventTypes []EventType, secret string) (Webhook, error) {
+	return ws.repository.CreateWebhook(ctx, url, eventTypes, secret)
+}
+
+func (ws *WebhookService) DeleteWebhook(ctx context.Context, id string) error {
+	return ws.repository.DeleteWebhook(ctx, id)
+}
@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventBookCreated EventType = "book.created"
+	EventBookUpdated EventType = "book.updated"
+	// EventBookPriceChanged is sent with EventBookUpdated when the price of the book in the default currency is updated
+	EventBookPriceChanged EventType = "book.price_changed"
+	EventBookDeleted      EventType = "book.deleted"
+	EventGenreCreated     EventType = "genre.created"
+	EventGenreUpdated     EventType = "genre.updated"
+	EventGenreDeleted     EventType = "genre.deleted"
+)
+
+// EventTypes is every event type a webhook can subscribe to
+var EventTypes = []EventType{
+	EventBookCreated,
+	EventBookUpdated,
+	EventBookPriceChanged,
+	EventBookDeleted,
+	EventGenreCreated,
+	EventGenreUpdated,
+	EventGenreDeleted,
+}
+
+// Webhook is a subscription of an external service to the events of the catalog, the secret is never returned.
+type Webhook struct {
+	CreatedAt  time.Time   `json:"created_at"`
+	Id         string      `json:"id"`
+	Url        string      `json:"url"`
+	EventTypes []EventType `json:"event_types"`
+}
+
+// Event is the body of a delivery.
+// Data is the entity after the change, e.g. a book.Book, a deleted entity only has its id.
+type Event struct {
+	CreatedAt time.Time       `json:"created_at"`
+	Id        string          `json:"id"`
+	Type      EventType       `json:"type"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Delivery is an event of the outbox waiting to be delivered to a webhook.
+type Delivery struct {
+	Id     int64
+	Event  Event
+	Url    string
+	Secret string
+	// Attempts is the number of failed attempts before this one
+	Attempts int
+}
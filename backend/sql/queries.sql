@@ -12,13 +12,42 @@ DELETE FROM genre WHERE id = $1;
 -- name: GetGenreByName :one
 SELECT * FROM genre WHERE name = $1;
 
+-- name: GetGenreById :one
+SELECT
+  genre.id,
+  genre.name,
+  COUNT(book_genre.id) AS book_count
+FROM
+  genre
+LEFT JOIN
+  book_genre ON book_genre.genre_id = genre.id
+WHERE
+  genre.id = $1
+GROUP BY
+  genre.id;
+
+-- name: GetGenreBookIds :many
+SELECT book_id FROM book_genre WHERE genre_id = $1;
+
+-- name: UpdateGenre :execrows
+UPDATE genre SET name = $1 WHERE id = $2;
+
+-- name: MoveBookGenres :exec
+-- books that already have the target genre keep their row, the source row is removed with the source genre
+UPDATE book_genre SET genre_id = @target_id
+WHERE
+  book_genre.genre_id = @source_id
+AND NOT EXISTS (
+  SELECT 1 FROM book_genre AS target WHERE target.book_id = book_genre.book_id AND target.genre_id = @target_id
+);
+
 -- name: CreateBook :one
 INSERT INTO book (
-  title, author, description, price, cover_image
+  title, author, description, price, cover_image, isbn13, publisher, publication_date, edition, page_count, language, format, series_id, series_position
 ) VALUES (
-  $1, $2, $3, $4, $5
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
 )
-RETURNING id;
+RETURNING id, created_at, updated_at;
 
 -- name: CreateBookGenre :exec
 INSERT INTO book_genre (
@@ -27,93 +56,35 @@ INSERT INTO book_genre (
   $1, $2
 );
 
--- name: GetBooks :one
-WITH
-filtered_books as (
-    SELECT
-      book.id AS id,
-      book.title AS title,
-      book.description AS description,
-      book.author AS author,
-      book.price AS price,
-      book.cover_image AS cover_image,
-      COALESCE(ARRAY_AGG(genre.name) FILTER (WHERE genre.name IS NOT NULL), '{}') AS genres
-    FROM
-      book
-    LEFT JOIN
-      book_genre ON book_genre.book_id = book.id
-    LEFT JOIN
-      genre ON genre.id = book_genre.genre_id
-    WHERE 
-      book.author ILIKE @keyword_author
-    AND
-      book.title ILIKE @keyword_title
-    AND
-      book.id
-    IN
-      (
-        SELECT
-          book_genre.book_id
-        FROM
-          genre
-        INNER JOIN
-          book_genre
-        ON
-          book_genre.genre_id = genre.id 
-        AND
-          genre.name ILIKE ANY(@genres::text[])
-        GROUP BY 1
-      )
-    GROUP BY
-      book.id
-)
-SELECT (
-  SELECT
-    COUNT(filtered_books.id)
-  FROM
-  filtered_books
-) AS count,
-(
-  SELECT 
-    JSON_AGG(rows.*)
-  FROM
-    (
-      select 
-        id,
-        title,
-        description,
-        author,
-        price,
-        cover_image,
-        genres
-      from 
-        filtered_books
-      ORDER BY 
-        -- will produce title ASC/DESC, author ASC/DESC OR author ASC/DESC, title ASC/DESC
-        CASE
-          WHEN @descending::boolean AND @order_by::text = 'title' THEN title
-          WHEN @descending::boolean AND @order_by::text = 'author' THEN author
-          WHEN @descending::boolean THEN title
-        END DESC,
-        CASE
-          WHEN @descending::boolean AND @order_by::text = 'author' THEN title
-          WHEN @descending::boolean THEN author
-        END DESC,
-        CASE
-          WHEN NOT @descending::boolean AND @order_by::text = 'title' THEN title
-          WHEN NOT @descending::boolean AND @order_by::text = 'author' THEN author
-          WHEN NOT @descending::boolean THEN title
-        END ASC,
-        CASE
-          WHEN NOT @descending::boolean AND @order_by::text = 'author' THEN title
-          WHEN NOT @descending::boolean THEN author
-        END ASC
-      LIMIT 
-        $1
-      OFFSET 
-        $2
-    ) AS rows
-) AS books;
+-- name: UpdateBook :one
+UPDATE book SET
+  title = COALESCE(sqlc.narg('title'), title),
+  author = COALESCE(sqlc.narg('author'), author),
+  description = COALESCE(sqlc.narg('description'), description),
+  price = COALESCE(sqlc.narg('price'), price),
+  cover_image = COALESCE(sqlc.narg('cover_image'), cover_image),
+  isbn13 = NULLIF(COALESCE(sqlc.narg('isbn13'), isbn13), ''),
+  publisher = NULLIF(COALESCE(sqlc.narg('publisher'), publisher), ''),
+  publication_date = CASE WHEN @set_publication_date::boolean THEN sqlc.narg('publication_date') ELSE publication_date END,
+  edition = NULLIF(COALESCE(sqlc.narg('edition'), edition), ''),
+  page_count = CASE WHEN @set_page_count::boolean THEN sqlc.narg('page_count') ELSE page_count END,
+  language = NULLIF(COALESCE(sqlc.narg('language'), language), ''),
+  format = NULLIF(COALESCE(sqlc.narg('format'), format), ''),
+  series_id = CASE WHEN @set_series::boolean THEN sqlc.narg('series_id') ELSE series_id END,
+  series_position = CASE WHEN @set_series::boolean THEN sqlc.narg('series_position') ELSE series_position END,
+  updated_at = NOW()
+WHERE
+  id = @id
+RETURNING id;
+
+-- name: GetBookIdByIsbn :one
+SELECT id FROM book WHERE isbn13 = $1;
+
+-- name: DeleteBookGenres :exec
+DELETE FROM book_genre WHERE book_id = $1;
+
+-- name: DeleteBook :execrows
+DELETE FROM book WHERE id = $1;
 
 -- name: GetBookById :one
 SELECT
@@ -121,22 +92,544 @@ SELECT
   book.title,
   book.description,
   book.author,
-  book.price,
+  COALESCE(book_price.price, convert_price(book.price, exchange_rate.rate, exchange_rate.rounding), book.price)::numeric AS price,
   book.cover_image,
-  COALESCE(ARRAY_AGG(genre.name) FILTER (WHERE genre.name IS NOT NULL), '{}') AS genres
+  COALESCE(stock.quantity_on_hand, 0)::integer AS quantity_on_hand,
+  COALESCE(stock.reserved, 0)::integer AS reserved,
+  COALESCE(ARRAY_AGG(genre.name) FILTER (WHERE genre.name IS NOT NULL), '{}') AS genres,
+  book.created_at,
+  book.updated_at,
+  book.average_rating,
+  book.review_count,
+  (
+    SELECT
+      COALESCE(JSON_AGG(JSON_BUILD_OBJECT('id', author.id, 'name', author.name, 'role', book_author.role) ORDER BY book_author.position), '[]')
+    FROM
+      book_author
+    INNER JOIN
+      author ON author.id = book_author.author_id
+    WHERE
+      book_author.book_id = book.id
+  ) AS authors,
+  book.isbn13,
+  book.publisher,
+  book.publication_date,
+  book.edition,
+  book.page_count,
+  book.language,
+  book.format,
+  (
+    SELECT JSON_BUILD_OBJECT('id', series.id, 'name', series.name) FROM series WHERE series.id = book.series_id
+  ) AS series,
+  book.series_position
 FROM
   book
+LEFT JOIN
+  stock ON stock.book_id = book.id
+LEFT JOIN
+  exchange_rate ON exchange_rate.currency = @currency::text
+LEFT JOIN
+  book_price ON book_price.book_id = book.id AND book_price.currency = @currency::text
 LEFT JOIN
   book_genre ON book_genre.book_id = book.id
 LEFT JOIN
   genre ON genre.id = book_genre.genre_id
 WHERE
-  book.id = $1
+  book.id = @id
 GROUP BY
-  book.id;
+  book.id, stock.book_id, exchange_rate.currency, book_price.book_id, book_price.currency;
 
 -- name: GetGenres :many
-SELECT name FROM genre;
+SELECT
+  genre.id,
+  genre.name,
+  COUNT(book_genre.id) AS book_count
+FROM
+  genre
+LEFT JOIN
+  book_genre ON book_genre.genre_id = genre.id
+GROUP BY
+  genre.id
+ORDER BY
+  genre.name;
 
 -- name: test :many
 SELECT name FROM genre where name ilike @genres::text[];
+
+-- name: GetStock :one
+SELECT
+  book.id AS book_id,
+  COALESCE(stock.quantity_on_hand, 0)::integer AS quantity_on_hand,
+  COALESCE(stock.reserved, 0)::integer AS reserved
+FROM
+  book
+LEFT JOIN
+  stock ON stock.book_id = book.id
+WHERE
+  book.id = $1;
+
+-- name: CreateStock :exec
+INSERT INTO stock (
+  book_id
+) VALUES (
+  $1
+);
+
+-- name: AdjustStock :one
+-- the check constraints on stock reject the update if it drives the quantity on hand below zero or below the reserved quantity
+UPDATE stock SET quantity_on_hand = quantity_on_hand + @quantity::integer
+WHERE
+  book_id = @book_id
+RETURNING quantity_on_hand, reserved;
+
+-- name: CreateStockAdjustment :one
+INSERT INTO stock_adjustment (
+  book_id, quantity, reason, note, quantity_on_hand
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING id, created_at;
+
+-- name: GetStockAdjustments :many
+SELECT * FROM stock_adjustment WHERE book_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3;
+
+-- name: CountStockAdjustments :one
+SELECT COUNT(*) FROM stock_adjustment WHERE book_id = $1;
+
+-- name: CreateCart :one
+INSERT INTO cart DEFAULT VALUES RETURNING id;
+
+-- name: GetCart :one
+SELECT * FROM cart WHERE id = $1;
+
+-- name: GetCartItems :many
+SELECT
+  book.id AS book_id,
+  book.title,
+  book.author,
+  book.price,
+  cart_item.quantity
+FROM
+  cart_item
+INNER JOIN
+  book ON book.id = cart_item.book_id
+WHERE
+  cart_item.cart_id = $1
+ORDER BY
+  book.title;
+
+-- name: AddCartItem :exec
+INSERT INTO cart_item (
+  cart_id, book_id, quantity
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (cart_id, book_id) DO UPDATE SET quantity = cart_item.quantity + EXCLUDED.quantity;
+
+-- name: UpdateCartItem :execrows
+UPDATE cart_item SET quantity = $1 WHERE cart_id = $2 AND book_id = $3;
+
+-- name: DeleteCartItem :execrows
+DELETE FROM cart_item WHERE cart_id = $1 AND book_id = $2;
+
+-- name: DeleteCart :exec
+DELETE FROM cart WHERE id = $1;
+
+-- name: ReserveStock :one
+-- the check constraints on stock reject the update if there is not enough stock left to reserve
+UPDATE stock SET reserved = reserved + @quantity::integer
+WHERE
+  book_id = @book_id
+RETURNING reserved;
+
+-- name: ReleaseStock :exec
+UPDATE stock SET reserved = reserved - @quantity::integer WHERE book_id = @book_id;
+
+-- name: ConsumeStock :one
+UPDATE stock SET
+  quantity_on_hand = quantity_on_hand - @quantity::integer,
+  reserved = reserved - @quantity::integer
+WHERE
+  book_id = @book_id
+RETURNING quantity_on_hand;
+
+-- name: CreateOrder :one
+INSERT INTO orders DEFAULT VALUES RETURNING *;
+
+-- name: CreateOrderItem :execrows
+INSERT INTO order_item (
+  order_id, book_id, title, author, price, quantity
+)
+SELECT
+  @order_id, book.id, book.title, book.author, book.price, @quantity::integer
+FROM
+  book
+WHERE
+  book.id = @book_id;
+
+-- name: GetOrder :one
+SELECT * FROM orders WHERE id = $1;
+
+-- name: GetOrders :many
+SELECT * FROM orders ORDER BY created_at DESC LIMIT $1 OFFSET $2;
+
+-- name: CountOrders :one
+SELECT COUNT(*) FROM orders;
+
+-- name: GetOrderItems :many
+SELECT * FROM order_item WHERE order_id = ANY(@order_ids::uuid[]) ORDER BY title;
+
+-- name: UpdateOrderStatus :execrows
+UPDATE orders SET status = @status, updated_at = NOW() WHERE id = @id AND status = @current_status;
+
+-- name: CreatePayment :one
+INSERT INTO payment (
+  order_id, status, amount, currency
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: AuthorizePayment :one
+UPDATE payment SET provider_payment_id = @provider_payment_id, status = 'authorized', updated_at = NOW()
+WHERE id = @id AND status = 'pending'
+RETURNING *;
+
+-- name: UpdatePaymentStatus :one
+UPDATE payment SET status = @status, updated_at = NOW() WHERE id = @id AND status = @current_status RETURNING *;
+
+-- name: GetPaymentByOrderId :one
+SELECT * FROM payment WHERE order_id = $1 AND status = $2 ORDER BY created_at DESC LIMIT 1;
+
+-- name: GetPaymentByProviderPaymentId :one
+SELECT * FROM payment WHERE provider_payment_id = $1;
+
+-- name: UpsertExchangeRate :exec
+INSERT INTO exchange_rate (
+  currency, rate, rounding
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (currency) DO UPDATE SET rate = EXCLUDED.rate, rounding = EXCLUDED.rounding, updated_at = NOW();
+
+-- name: GetExchangeRates :many
+SELECT * FROM exchange_rate ORDER BY currency;
+
+-- name: GetExchangeRate :one
+SELECT * FROM exchange_rate WHERE currency = $1;
+
+-- name: ConvertPrices :many
+-- converts the prices in USD to the currency in the same order, with the same rounding as the prices of the books
+SELECT
+  COALESCE(convert_price(prices.price, exchange_rate.rate, exchange_rate.rounding), prices.price)::numeric AS price
+FROM
+  UNNEST(@prices::numeric[]) WITH ORDINALITY AS prices(price, position)
+LEFT JOIN
+  exchange_rate ON exchange_rate.currency = @currency::text
+ORDER BY
+  prices.position;
+
+-- name: UpsertBookPrice :exec
+INSERT INTO book_price (
+  book_id, currency, price
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (book_id, currency) DO UPDATE SET price = EXCLUDED.price;
+
+-- name: DeleteBookPrice :execrows
+DELETE FROM book_price WHERE book_id = $1 AND currency = $2;
+
+-- name: CreateChange :exec
+INSERT INTO change_log (
+  entity, entity_id, action, data
+) VALUES (
+  $1, $2, $3, $4
+);
+
+-- name: GetChanges :many
+-- only the changes of the transactions that ended before the oldest running transaction are returned,
+-- a running transaction could still add a change before the last returned one
+SELECT * FROM change_log
+WHERE
+  (transaction_id, id) > (@transaction_id::bigint, @id::bigint)
+AND
+  transaction_id < pg_snapshot_xmin(pg_current_snapshot())::text::bigint
+ORDER BY
+  transaction_id, id
+LIMIT
+  @max_changes;
+
+-- name: CreateWebhook :one
+INSERT INTO webhook (
+  url, event_types, secret
+) VALUES (
+  $1, $2, $3
+)
+RETURNING id, created_at;
+
+-- name: DeleteWebhook :execrows
+DELETE FROM webhook WHERE id = $1;
+
+-- name: CreateOutboxEvent :exec
+-- adds the event to the outbox of every webhook subscribed to its type
+WITH event AS (
+  SELECT uuid_generate_v4() AS id
+)
+INSERT INTO outbox (
+  webhook_id, event_id, event_type, payload
+)
+SELECT
+  webhook.id, event.id, @event_type::text, @payload::jsonb
+FROM
+  webhook, event
+WHERE
+  @event_type::text = ANY(webhook.event_types);
+
+-- name: ClaimOutboxEvents :many
+-- claims the due events by moving their next attempt after the lease, the other dispatchers skip them until the lease ends
+UPDATE outbox
+SET
+  next_attempt_at = NOW() + @lease_seconds::int * INTERVAL '1 second'
+FROM
+  webhook
+WHERE
+  outbox.webhook_id = webhook.id
+AND
+  outbox.id IN (
+    SELECT id FROM outbox
+    WHERE status = 'pending' AND next_attempt_at <= NOW()
+    ORDER BY next_attempt_at, id
+    LIMIT @max_events
+    FOR UPDATE SKIP LOCKED
+  )
+RETURNING
+  outbox.id, outbox.event_id, outbox.event_type, outbox.payload, outbox.attempts, outbox.created_at, webhook.url, webhook.secret;
+
+-- name: DeliverOutboxEvent :exec
+UPDATE outbox SET status = 'delivered', attempts = attempts + 1, last_error = NULL, delivered_at = NOW() WHERE id = $1;
+
+-- name: RetryOutboxEvent :exec
+UPDATE outbox
+SET
+  attempts = attempts + 1,
+  last_error = @last_error,
+  next_attempt_at = NOW() + @delay_ms::bigint * INTERVAL '1 millisecond'
+WHERE
+  id = @id;
+
+-- name: DeadLetterOutboxEvent :exec
+UPDATE outbox SET status = 'dead', attempts = attempts + 1, last_error = @last_error WHERE id = @id;
+
+-- name: CreateUser :one
+INSERT INTO users (
+  email, name, password_hash, role
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING id, created_at;
+
+-- name: GetUserByEmail :one
+SELECT * FROM users WHERE email = $1;
+
+-- name: GetUserById :one
+SELECT * FROM users WHERE id = $1;
+
+-- name: UpdateUserRole :one
+UPDATE users SET role = $2 WHERE id = $1 RETURNING *;
+
+-- name: CreateRefreshToken :exec
+INSERT INTO refresh_token (
+  token_hash, user_id, expires_at
+) VALUES (
+  $1, $2, $3
+);
+
+-- name: RevokeRefreshToken :one
+UPDATE refresh_token SET revoked_at = NOW() WHERE token_hash = $1 AND revoked_at IS NULL AND expires_at > NOW() RETURNING user_id;
+
+-- name: CreateApiKey :one
+INSERT INTO api_key (
+  user_id, name, prefix, key_hash, scopes
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING id, created_at;
+
+-- name: GetApiKeys :many
+SELECT * FROM api_key WHERE user_id = $1 AND revoked_at IS NULL ORDER BY created_at DESC;
+
+-- name: RevokeApiKey :execrows
+UPDATE api_key SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL;
+
+-- name: UseApiKey :one
+-- returns the scopes of the key with its user and records that the key was used
+UPDATE api_key
+SET
+  last_used_at = NOW()
+FROM
+  users
+WHERE
+  api_key.user_id = users.id
+AND
+  api_key.key_hash = $1
+AND
+  api_key.revoked_at IS NULL
+RETURNING
+  api_key.scopes, users.id, users.email, users.name, users.role;
+
+-- name: CreateReview :one
+INSERT INTO review (
+  book_id, user_id, rating, body
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING id, created_at, updated_at;
+
+-- name: GetReviews :many
+SELECT
+  review.id,
+  review.book_id,
+  review.user_id,
+  users.name AS user_name,
+  review.rating,
+  review.body,
+  review.created_at,
+  review.updated_at
+FROM
+  review
+INNER JOIN
+  users ON users.id = review.user_id
+WHERE
+  review.book_id = $1
+ORDER BY
+  review.created_at DESC, review.id
+LIMIT $2 OFFSET $3;
+
+-- name: GetReviewForUpdate :one
+SELECT * FROM review WHERE id = $1 AND user_id = $2 FOR UPDATE;
+
+-- name: UpdateReview :one
+UPDATE review SET
+  rating = COALESCE(sqlc.narg('rating'), rating),
+  body = COALESCE(sqlc.narg('body'), body),
+  updated_at = NOW()
+WHERE
+  id = @id
+RETURNING *;
+
+-- name: DeleteReview :one
+DELETE FROM review WHERE id = $1 AND user_id = $2 RETURNING book_id, rating;
+
+-- name: GetBookReviewCount :one
+SELECT review_count FROM book WHERE id = $1;
+
+-- name: TouchBooks :many
+-- marks the books as updated after a change made through another table, such as their genre, stock or rating
+UPDATE book SET updated_at = NOW() WHERE id = ANY(@ids::uuid[]) RETURNING id;
+
+-- name: UpdateBookRating :exec
+-- adds the difference a review made to the ratings of the book
+UPDATE book SET
+  rating_sum = rating_sum + @rating_delta::integer,
+  review_count = review_count + @count_delta::integer
+WHERE
+  id = @id;
+
+-- name: UpsertAuthor :one
+-- returns the author with the name, case insensitive, the author is created if there is none
+INSERT INTO author (
+  name
+) VALUES (
+  $1
+)
+ON CONFLICT ((LOWER(name))) DO UPDATE SET name = author.name
+RETURNING id, name;
+
+-- name: GetAuthors :many
+SELECT
+  author.id,
+  author.name,
+  author.bio,
+  author.photo_url,
+  author.created_at,
+  COUNT(DISTINCT book_author.book_id) AS book_count
+FROM
+  author
+LEFT JOIN
+  book_author ON book_author.author_id = author.id
+WHERE
+  author.name ILIKE @name::text
+GROUP BY
+  author.id
+ORDER BY
+  author.name, author.id
+LIMIT @limit_count OFFSET @offset_count;
+
+-- name: CountAuthors :one
+SELECT COUNT(*) FROM author WHERE name ILIKE $1;
+
+-- name: GetAuthorById :one
+SELECT
+  author.id,
+  author.name,
+  author.bio,
+  author.photo_url,
+  author.created_at,
+  COUNT(DISTINCT book_author.book_id) AS book_count
+FROM
+  author
+LEFT JOIN
+  book_author ON book_author.author_id = author.id
+WHERE
+  author.id = $1
+GROUP BY
+  author.id;
+
+-- name: GetBookAuthors :many
+SELECT
+  author.id,
+  author.name,
+  book_author.role
+FROM
+  book_author
+INNER JOIN
+  author ON author.id = book_author.author_id
+WHERE
+  book_author.book_id = $1
+ORDER BY
+  book_author.position;
+
+-- name: CreateBookAuthor :exec
+INSERT INTO book_author (
+  book_id, author_id, role, position
+) VALUES (
+  $1, $2, $3, $4
+);
+
+-- name: DeleteBookAuthors :exec
+DELETE FROM book_author WHERE book_id = $1;
+
+-- name: CreateSeries :one
+INSERT INTO series (
+  name, description
+) VALUES (
+  $1, $2
+)
+RETURNING id, created_at;
+
+-- name: GetSeriesById :one
+SELECT
+  series.id,
+  series.name,
+  series.description,
+  series.created_at,
+  COUNT(book.id) AS book_count
+FROM
+  series
+LEFT JOIN
+  book ON book.series_id = series.id
+WHERE
+  series.id = $1
+GROUP BY
+  series.id;
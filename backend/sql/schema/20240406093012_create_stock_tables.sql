@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE stock (
+  book_id UUID,
+  quantity_on_hand INTEGER NOT NULL DEFAULT 0,
+  reserved INTEGER NOT NULL DEFAULT 0,
+  FOREIGN KEY (book_id) REFERENCES book(id) ON DELETE CASCADE,
+  PRIMARY KEY(book_id),
+  -- guards concurrent adjustments from selling stock we do not have
+  CONSTRAINT stock_quantity_on_hand_check CHECK (quantity_on_hand >= 0),
+  CONSTRAINT stock_reserved_check CHECK (reserved >= 0 AND reserved <= quantity_on_hand)
+);
+
+CREATE TABLE stock_adjustment (
+  id UUID DEFAULT uuid_generate_v4(),
+  book_id UUID NOT NULL,
+  quantity INTEGER NOT NULL,
+  reason VARCHAR(32) NOT NULL,
+  note TEXT,
+  quantity_on_hand INTEGER NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  FOREIGN KEY (book_id) REFERENCES book(id) ON DELETE CASCADE,
+  PRIMARY KEY(id)
+);
+
+CREATE INDEX stock_adjustment_book_id_idx ON stock_adjustment (book_id, created_at);
+
+INSERT INTO stock (book_id) SELECT id FROM book;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE stock_adjustment;
+DROP TABLE stock;
+-- +goose StatementEnd
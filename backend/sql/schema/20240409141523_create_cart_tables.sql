@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE cart (
+  id UUID DEFAULT uuid_generate_v4(),
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY(id)
+);
+
+CREATE TABLE cart_item (
+  cart_id UUID,
+  book_id UUID,
+  quantity INTEGER NOT NULL,
+  FOREIGN KEY (cart_id) REFERENCES cart(id) ON DELETE CASCADE,
+  FOREIGN KEY (book_id) REFERENCES book(id) ON DELETE CASCADE,
+  PRIMARY KEY(cart_id, book_id),
+  CONSTRAINT cart_item_quantity_check CHECK (quantity > 0)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE cart_item;
+DROP TABLE cart;
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE orders (
+  id UUID DEFAULT uuid_generate_v4(),
+  status VARCHAR(32) NOT NULL DEFAULT 'pending',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY(id),
+  CONSTRAINT orders_status_check CHECK (status IN ('pending', 'paid', 'shipped', 'delivered', 'cancelled', 'refunded'))
+);
+
+CREATE INDEX orders_created_at_idx ON orders (created_at);
+
+-- title, author and price are copied from the book so the order does not change when the book does
+CREATE TABLE order_item (
+  id UUID DEFAULT uuid_generate_v4(),
+  order_id UUID NOT NULL,
+  book_id UUID,
+  title VARCHAR(255) NOT NULL,
+  author VARCHAR(255) NOT NULL,
+  price DECIMAL NOT NULL,
+  quantity INTEGER NOT NULL,
+  FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+  FOREIGN KEY (book_id) REFERENCES book(id) ON DELETE SET NULL,
+  PRIMARY KEY(id),
+  CONSTRAINT order_item_quantity_check CHECK (quantity > 0)
+);
+
+CREATE INDEX order_item_order_id_idx ON order_item (order_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE order_item;
+DROP TABLE orders;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE payment (
+  id UUID DEFAULT uuid_generate_v4(),
+  order_id UUID NOT NULL,
+  -- null until the charge is authorized
+  provider_payment_id VARCHAR(255),
+  status VARCHAR(32) NOT NULL,
+  amount BIGINT NOT NULL,
+  currency CHAR(3) NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+  PRIMARY KEY(id),
+  CONSTRAINT unique_provider_payment_id UNIQUE (provider_payment_id),
+  CONSTRAINT payment_status_check CHECK (status IN ('pending', 'authorized', 'captured', 'refunding', 'refunded', 'failed'))
+);
+
+CREATE INDEX payment_order_id_idx ON payment (order_id);
+-- an order has a single payment that isn't failed or refunded, a concurrent payment of the order fails on insert
+CREATE UNIQUE INDEX payment_order_id_active_idx ON payment (order_id) WHERE status NOT IN ('failed', 'refunded');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE payment;
+-- +goose StatementEnd
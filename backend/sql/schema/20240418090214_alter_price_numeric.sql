@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- prices are stored in USD with the 2 digits of its minor unit so they map to book.Money exactly
+ALTER TABLE book ALTER COLUMN price TYPE NUMERIC(12, 2);
+ALTER TABLE book ADD CONSTRAINT book_price_check CHECK (price > 0);
+ALTER TABLE order_item ALTER COLUMN price TYPE NUMERIC(12, 2);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE order_item ALTER COLUMN price TYPE DECIMAL;
+ALTER TABLE book DROP CONSTRAINT book_price_check;
+ALTER TABLE book ALTER COLUMN price TYPE DECIMAL;
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE exchange_rate (
+  currency CHAR(3) NOT NULL,
+  -- amount of the currency for 1 USD
+  rate NUMERIC(20, 10) NOT NULL,
+  -- converted prices are rounded to a multiple of it, e.g. 0.05 for CHF or 1 for JPY
+  rounding NUMERIC(12, 3) NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY(currency),
+  CONSTRAINT exchange_rate_rate_check CHECK (rate > 0),
+  CONSTRAINT exchange_rate_rounding_check CHECK (rounding > 0)
+);
+
+-- price of a book in a currency that is used instead of the converted price
+CREATE TABLE book_price (
+  book_id UUID NOT NULL,
+  currency CHAR(3) NOT NULL,
+  price NUMERIC(15, 3) NOT NULL,
+  FOREIGN KEY (book_id) REFERENCES book(id) ON DELETE CASCADE,
+  PRIMARY KEY(book_id, currency),
+  CONSTRAINT book_price_price_check CHECK (price > 0)
+);
+
+CREATE FUNCTION convert_price(price NUMERIC, rate NUMERIC, rounding NUMERIC) RETURNS NUMERIC AS $$
+  SELECT ROUND(price * rate / rounding) * rounding
+$$ LANGUAGE SQL IMMUTABLE STRICT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP FUNCTION convert_price;
+DROP TABLE book_price;
+DROP TABLE exchange_rate;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- title matches rank higher than author matches which rank higher than description matches
+ALTER TABLE book ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+  setweight(to_tsvector('english', COALESCE(title, '')), 'A') ||
+  setweight(to_tsvector('english', COALESCE(author, '')), 'B') ||
+  setweight(to_tsvector('english', COALESCE(description, '')), 'C')
+) STORED;
+
+CREATE INDEX book_search_vector_idx ON book USING GIN (search_vector);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX book_search_vector_idx;
+ALTER TABLE book DROP COLUMN search_vector;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the books that existed before are considered created now
+ALTER TABLE book ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+CREATE INDEX book_created_at_idx ON book (created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX book_created_at_idx;
+ALTER TABLE book DROP COLUMN created_at;
+-- +goose StatementEnd
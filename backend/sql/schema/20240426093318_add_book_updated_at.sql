@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- updated_at is set by the queries that update a book, it is used to sync the changes of the catalog
+ALTER TABLE book ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+UPDATE book SET updated_at = created_at;
+
+CREATE INDEX book_updated_at_idx ON book (updated_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX book_updated_at_idx;
+ALTER TABLE book DROP COLUMN updated_at;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the changes of the catalog in the order they were committed, it is written in the same transaction as the change
+CREATE TABLE change_log (
+  id BIGSERIAL,
+  -- the changes are read in the order of the transactions that wrote them since the ids are not committed in order
+  transaction_id BIGINT NOT NULL DEFAULT pg_current_xact_id()::text::bigint,
+  entity VARCHAR(16) NOT NULL,
+  entity_id UUID NOT NULL,
+  action VARCHAR(16) NOT NULL,
+  -- the entity after the change, NULL for a delete
+  data JSONB,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY(id),
+  CONSTRAINT change_log_entity_check CHECK (entity IN ('book', 'genre')),
+  CONSTRAINT change_log_action_check CHECK (action IN ('create', 'update', 'delete'))
+);
+
+CREATE INDEX change_log_transaction_id_idx ON change_log (transaction_id, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE change_log;
+-- +goose StatementEnd
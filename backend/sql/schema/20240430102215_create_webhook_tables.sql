@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE webhook (
+  id UUID DEFAULT uuid_generate_v4(),
+  url TEXT NOT NULL,
+  event_types VARCHAR(32)[] NOT NULL,
+  -- the deliveries are signed with the secret so it is kept as is
+  secret TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY(id)
+);
+
+-- the events waiting to be delivered to the webhooks, it is written in the same transaction as the change of the event
+CREATE TABLE outbox (
+  id BIGSERIAL,
+  webhook_id UUID NOT NULL,
+  -- an event has the same id in every webhook it is delivered to
+  event_id UUID NOT NULL,
+  event_type VARCHAR(32) NOT NULL,
+  payload JSONB NOT NULL,
+  status VARCHAR(16) NOT NULL DEFAULT 'pending',
+  attempts INT NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  last_error TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  delivered_at TIMESTAMPTZ,
+  FOREIGN KEY (webhook_id) REFERENCES webhook(id) ON DELETE CASCADE,
+  PRIMARY KEY(id),
+  CONSTRAINT outbox_status_check CHECK (status IN ('pending', 'delivered', 'dead'))
+);
+
+CREATE INDEX outbox_pending_idx ON outbox (next_attempt_at) WHERE status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE outbox;
+DROP TABLE webhook;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE users (
+  id UUID DEFAULT uuid_generate_v4(),
+  email VARCHAR(255) NOT NULL,
+  name VARCHAR(255) NOT NULL,
+  password_hash TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY(id),
+  CONSTRAINT unique_user_email UNIQUE (email)
+);
+
+-- the refresh tokens are stored by the sha256 of the token
+CREATE TABLE refresh_token (
+  token_hash CHAR(64),
+  user_id UUID NOT NULL,
+  expires_at TIMESTAMPTZ NOT NULL,
+  revoked_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+  PRIMARY KEY(token_hash)
+);
+
+CREATE INDEX refresh_token_user_id_idx ON refresh_token (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE refresh_token;
+DROP TABLE users;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the keys are stored by the sha256 of the key, the prefix is the start of the key shown to tell the keys apart
+CREATE TABLE api_key (
+  id UUID DEFAULT uuid_generate_v4(),
+  user_id UUID NOT NULL,
+  name VARCHAR(255) NOT NULL,
+  prefix VARCHAR(16) NOT NULL,
+  key_hash CHAR(64) NOT NULL,
+  scopes VARCHAR(32)[] NOT NULL,
+  last_used_at TIMESTAMPTZ,
+  revoked_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+  PRIMARY KEY(id),
+  CONSTRAINT unique_api_key_hash UNIQUE (key_hash)
+);
+
+CREATE INDEX api_key_user_id_idx ON api_key (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE api_key;
+-- +goose StatementEnd
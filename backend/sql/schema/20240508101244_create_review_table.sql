@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+-- a user has one review per book, the review is edited instead of posting another one
+CREATE TABLE review (
+  id UUID DEFAULT uuid_generate_v4(),
+  book_id UUID NOT NULL,
+  user_id UUID NOT NULL,
+  rating SMALLINT NOT NULL,
+  body TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  FOREIGN KEY (book_id) REFERENCES book(id) ON DELETE CASCADE,
+  FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+  PRIMARY KEY(id),
+  CONSTRAINT unique_review_book_user UNIQUE (book_id, user_id),
+  CONSTRAINT review_rating_check CHECK (rating BETWEEN 1 AND 5)
+);
+
+CREATE INDEX review_book_id_created_at_idx ON review (book_id, created_at DESC);
+
+-- the ratings of a book are summed up when a review is posted, edited or deleted so they are not aggregated on every read,
+-- average_rating is null until the book has a review
+ALTER TABLE book ADD COLUMN rating_sum INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE book ADD COLUMN review_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE book ADD COLUMN average_rating NUMERIC(3, 2) GENERATED ALWAYS AS (
+  CASE WHEN review_count > 0 THEN ROUND(rating_sum::numeric / review_count, 2) END
+) STORED;
+
+CREATE INDEX book_average_rating_idx ON book (average_rating);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX book_average_rating_idx;
+ALTER TABLE book DROP COLUMN average_rating;
+ALTER TABLE book DROP COLUMN review_count;
+ALTER TABLE book DROP COLUMN rating_sum;
+DROP TABLE review;
+-- +goose StatementEnd
@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+-- an author is matched by name case insensitive so the same spelling is one author
+CREATE TABLE author (
+  id UUID DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  bio TEXT,
+  photo_url TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY(id)
+);
+
+CREATE UNIQUE INDEX author_name_idx ON author (LOWER(name));
+
+-- position orders the credits of a book, book.author keeps the byline of the authors for the search and the sort
+CREATE TABLE book_author (
+  book_id UUID NOT NULL,
+  author_id UUID NOT NULL,
+  role VARCHAR(16) NOT NULL DEFAULT 'author',
+  position SMALLINT NOT NULL DEFAULT 0,
+  FOREIGN KEY (book_id) REFERENCES book(id) ON DELETE CASCADE,
+  FOREIGN KEY (author_id) REFERENCES author(id),
+  PRIMARY KEY(book_id, author_id, role),
+  CONSTRAINT book_author_role_check CHECK (role IN ('author', 'translator', 'illustrator', 'editor'))
+);
+
+CREATE INDEX book_author_author_id_idx ON book_author (author_id);
+
+-- the first spelling in alphabetical order is kept when the same author is spelled with another case
+INSERT INTO author (name)
+SELECT DISTINCT ON (LOWER(TRIM(author))) TRIM(author) FROM book WHERE TRIM(author) <> '' ORDER BY LOWER(TRIM(author)), TRIM(author);
+
+INSERT INTO book_author (book_id, author_id, role)
+SELECT book.id, author.id, 'author' FROM book INNER JOIN author ON LOWER(author.name) = LOWER(TRIM(book.author));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE book_author;
+DROP TABLE author;
+-- +goose StatementEnd
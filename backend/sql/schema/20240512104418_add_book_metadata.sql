@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the ISBN is stored as an ISBN-13, the ISBN-10 is derived from it
+ALTER TABLE book ADD COLUMN isbn13 CHAR(13);
+ALTER TABLE book ADD COLUMN publisher VARCHAR(255);
+ALTER TABLE book ADD COLUMN publication_date DATE;
+ALTER TABLE book ADD COLUMN edition VARCHAR(64);
+ALTER TABLE book ADD COLUMN page_count INTEGER;
+ALTER TABLE book ADD COLUMN language VARCHAR(35);
+ALTER TABLE book ADD COLUMN format VARCHAR(16);
+
+ALTER TABLE book ADD CONSTRAINT unique_book_isbn13 UNIQUE (isbn13);
+ALTER TABLE book ADD CONSTRAINT book_page_count_check CHECK (page_count > 0);
+ALTER TABLE book ADD CONSTRAINT book_format_check CHECK (format IN ('hardcover', 'paperback', 'ebook', 'audiobook'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE book DROP CONSTRAINT book_format_check;
+ALTER TABLE book DROP CONSTRAINT book_page_count_check;
+ALTER TABLE book DROP CONSTRAINT unique_book_isbn13;
+ALTER TABLE book DROP COLUMN format;
+ALTER TABLE book DROP COLUMN language;
+ALTER TABLE book DROP COLUMN page_count;
+ALTER TABLE book DROP COLUMN edition;
+ALTER TABLE book DROP COLUMN publication_date;
+ALTER TABLE book DROP COLUMN publisher;
+ALTER TABLE book DROP COLUMN isbn13;
+-- +goose StatementEnd
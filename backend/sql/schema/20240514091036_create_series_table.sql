@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE series (
+  id UUID DEFAULT uuid_generate_v4(),
+  name VARCHAR(255) NOT NULL,
+  description TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY(id)
+);
+
+CREATE UNIQUE INDEX series_name_idx ON series (LOWER(name));
+
+-- a book is part of at most one series, the position is the reading order and can be fractional, e.g. 2.5 for a novella
+-- between the second and the third book, the editions of a book share the position.
+-- a series with books can't be deleted, setting series_id to null would leave the position and fail book_series_check
+ALTER TABLE book ADD COLUMN series_id UUID REFERENCES series(id) ON DELETE RESTRICT;
+ALTER TABLE book ADD COLUMN series_position NUMERIC(6, 2);
+ALTER TABLE book ADD CONSTRAINT book_series_position_check CHECK (series_position > 0);
+ALTER TABLE book ADD CONSTRAINT book_series_check CHECK ((series_id IS NULL) = (series_position IS NULL));
+
+CREATE INDEX book_series_id_series_position_idx ON book (series_id, series_position);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX book_series_id_series_position_idx;
+ALTER TABLE book DROP CONSTRAINT book_series_check;
+ALTER TABLE book DROP CONSTRAINT book_series_position_check;
+ALTER TABLE book DROP COLUMN series_position;
+ALTER TABLE book DROP COLUMN series_id;
+DROP TABLE series;
+-- +goose StatementEnd